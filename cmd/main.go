@@ -4,11 +4,12 @@ import "person/configs"
 
 // @title Person API
 // @version 1.0
-// @description This is a crud of people.
+// @description This is a crud of people. Error messages are written in the language of the Accept-Language header: en, pt-BR or es, falling back to en.
 // @BasePath /v1
 func main() {
 	configs.Variables()
 	configs.Logrus()
 	configs.Di()
+	configs.Jobs()
 	configs.Routes()
 }
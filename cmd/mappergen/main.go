@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"person/internal/mappergen"
+	"strings"
+)
+
+// mappergen writes the conversions between the structs of two packages, it is run by go generate in the package that
+// uses them.
+func main() {
+
+	config := mappergen.Config{Dir: "."}
+	types := ""
+
+	flag.StringVar(&config.From, "from", "", "import path of the package converted from, such as the documents")
+	flag.StringVar(&config.To, "to", "", "import path of the package converted to, such as the dtos")
+	flag.StringVar(&types, "types", "", "comma separated structs found in both packages")
+	flag.StringVar(&config.Output, "output", "mapper_gen.go", "file written in the current directory")
+	flag.Parse()
+
+	if config.From == "" || config.To == "" || types == "" {
+		flag.Usage()
+		log.Fatal("mappergen: from, to and types are required")
+	}
+
+	config.Types = strings.Split(types, ",")
+	source, err := mappergen.Generate(config)
+
+	if err != nil {
+		log.Fatalf("mappergen: %v", err)
+	}
+
+	if err = ioutil.WriteFile(config.Output, source, 0644); err != nil {
+		log.Fatalf("mappergen: %v", err)
+	}
+}
@@ -1,19 +1,72 @@
 package configs
 
 import (
+	log "github.com/sirupsen/logrus"
 	"person/internal/handler"
+	"person/internal/handlerv2"
 	"person/internal/mapper"
+	"person/internal/normalize"
 	"person/internal/repository"
+	"person/internal/useful"
 )
 
 var personHandler *handler.PersonHandler
+var personHandlerV2 *handlerv2.PersonHandler
+var personRepository repository.Repository
 
 func Di() {
 	person()
 }
 
 func person() {
-	personRepository := repository.PersonRepository{Collection: mongo()}
+	personRepository = storage()
 	personMapper := mapper.PersonMapper{}
+	normalizer := normalizer()
+	validator := validator()
 	personHandler = handler.NewPersonHandler(&personMapper, personRepository)
+	personHandler.MaxBodySize = properties.Request.MaxBodySize
+	personHandler.Normalizer = normalizer
+	personHandler.Validator = validator
+	personHandlerV2 = handlerv2.NewPersonHandler(personRepository)
+	personHandlerV2.MaxBodySize = properties.Request.MaxBodySize
+	personHandlerV2.Normalizer = normalizer
+	personHandlerV2.Validator = validator
+}
+
+func normalizer() normalize.Normalizer {
+
+	normalizer, err := normalize.New(properties.Normalize)
+
+	if err != nil {
+		log.Fatalln(useful.UnknownNormalization, err)
+	}
+
+	return normalizer
+}
+
+// validator is shared by the handlers of every version, so both validate with the same rules.
+func validator() *handler.Validator {
+
+	validator, err := handler.NewValidator(properties.ValidationRules())
+
+	if err != nil {
+		log.Fatalln(useful.InvalidValidationRules, err)
+	}
+
+	return validator
+}
+
+func storage() repository.Repository {
+
+	switch properties.Storage.Driver {
+	case "memory":
+		log.Warnln(useful.MemoryStorage)
+		return repository.NewMemoryRepository()
+	case "", "mongo":
+		collection, history := mongo()
+		return repository.PersonRepository{Collection: collection, Revisions: history, Timeout: timeout()}
+	}
+
+	log.Fatalln(useful.UnknownStorage, properties.Storage.Driver)
+	return nil
 }
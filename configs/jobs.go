@@ -0,0 +1,16 @@
+package configs
+
+import (
+	"context"
+	"person/internal/job"
+	"time"
+)
+
+func Jobs() {
+	purge := job.Purge{
+		Repository: personRepository,
+		Retention:  time.Duration(properties.SoftDelete.RetentionDays) * 24 * time.Hour,
+		Interval:   time.Duration(properties.SoftDelete.PurgeIntervalMinutes) * time.Minute,
+	}
+	purge.Start(context.Background())
+}
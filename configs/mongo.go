@@ -6,11 +6,12 @@ import (
 	driver "go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"person/internal/repository"
 	"person/internal/useful"
 	"time"
 )
 
-func mongo() *driver.Collection {
+func mongo() (*driver.Collection, *driver.Collection) {
 
 	client, _ := driver.NewClient(options.Client().ApplyURI(properties.Mongo.Uri))
 	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
@@ -22,5 +23,35 @@ func mongo() *driver.Collection {
 		log.Fatal(useful.ConnectDbError, err)
 	}
 
-	return client.Database(properties.Mongo.Database).Collection(properties.Mongo.Collection)
+	database := client.Database(properties.Mongo.Database)
+	collection := database.Collection(properties.Mongo.Collection)
+	history := database.Collection(properties.Mongo.History)
+
+	if err = repository.EnsureIndexes(ctx, collection); err != nil {
+		log.Fatal(useful.CreateIndexError, err)
+	}
+
+	// people saved before fuzzy searches used grams can take longer than the connection to update
+	if err = repository.EnsureSearchGrams(context.Background(), collection); err != nil {
+		log.Fatal(useful.CreateIndexError, err)
+	}
+
+	if err = repository.EnsureRevisionIndexes(ctx, history); err != nil {
+		log.Fatal(useful.CreateIndexError, err)
+	}
+
+	return collection, history
+}
+
+func timeout() repository.Timeout {
+	return repository.Timeout{
+		Find:     time.Duration(properties.Mongo.Timeout.Find) * time.Millisecond,
+		FindById: time.Duration(properties.Mongo.Timeout.FindById) * time.Millisecond,
+		Create:   time.Duration(properties.Mongo.Timeout.Create) * time.Millisecond,
+		Update:   time.Duration(properties.Mongo.Timeout.Update) * time.Millisecond,
+		Delete:   time.Duration(properties.Mongo.Timeout.Delete) * time.Millisecond,
+		Bulk:     time.Duration(properties.Mongo.Timeout.Bulk) * time.Millisecond,
+		Stream:   time.Duration(properties.Mongo.Timeout.Stream) * time.Millisecond,
+		Search:   time.Duration(properties.Mongo.Timeout.Search) * time.Millisecond,
+	}
 }
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"person/internal/dto"
 )
 
 type Properties struct {
@@ -14,6 +15,35 @@ type Properties struct {
 		Uri        string
 		Database   string
 		Collection string
+		History    string
+		Timeout    struct {
+			Find     int
+			FindById int
+			Create   int
+			Update   int
+			Delete   int
+			Bulk     int
+			Stream   int
+			Search   int
+		}
+	}
+	Storage struct {
+		Driver string
+	}
+	SoftDelete struct {
+		RetentionDays        int
+		PurgeIntervalMinutes int
+	}
+	Request struct {
+		MaxBodySize int64
+	}
+	Normalize  map[string][]string
+	Validation struct {
+		MinAge        int
+		MaxAge        int
+		MinNameLength int
+		MaxNameLength int
+		EmailDomains  []string
 	}
 	Port int
 	Log  struct {
@@ -24,9 +54,34 @@ type Properties struct {
 
 var properties Properties
 
-func Variables() {
+// ValidationRules are the rules of the validation section, with the defaults of the ones it leaves out.
+func (p Properties) ValidationRules() dto.ValidationRules {
+	return dto.ValidationRules(p.Validation).WithDefaults()
+}
+
+// Load reads the properties of base, with the sections of env taking the place of the same ones of base.
+func Load(base []byte, env []byte) (Properties, error) {
+
+	var loaded Properties
 	variables := make(map[string]interface{})
 
+	baseErr := yaml.Unmarshal(base, &variables)
+	envErr := yaml.Unmarshal(env, &variables)
+	err := mapstructure.Decode(variables, &loaded)
+
+	if baseErr != nil {
+		return loaded, baseErr
+	}
+
+	if envErr != nil {
+		return loaded, envErr
+	}
+
+	return loaded, err
+}
+
+func Variables() {
+
 	var basepath string
 
 	if basepath, _ = os.Getwd(); basepath == "/" {
@@ -37,9 +92,7 @@ func Variables() {
 	env, envErr := ioutil.ReadFile(fmt.Sprintf("%s/properties/%s.yaml", basepath, os.Getenv("env")))
 
 	if baseErr == nil && envErr == nil {
-		baseErr = yaml.Unmarshal(base, &variables)
-		envErr = yaml.Unmarshal(env, &variables)
-		_ = mapstructure.Decode(variables, &properties)
+		properties, _ = Load(base, env)
 		return
 	}
 
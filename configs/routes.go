@@ -7,20 +7,61 @@ import (
 	"github.com/swaggo/http-swagger"
 	"net/http"
 	_ "person/docs"
+	docsv2 "person/docs/v2"
+	"person/internal/codec"
+	"person/internal/handler"
+	"person/internal/handlerv2"
 )
 
 func Routes() {
 	port := fmt.Sprintf(":%d", properties.Port)
 	log.Infoln("Applications starting in port", port)
 
-	r := mux.NewRouter()
-	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
-	r.HandleFunc("/v1/person", personHandler.Find).Methods(http.MethodGet)
-	r.HandleFunc("/v1/person/{id}", personHandler.FindById).Methods(http.MethodGet)
-	r.HandleFunc("/v1/person", personHandler.Create).Methods(http.MethodPost)
-	r.HandleFunc("/v1/person/{id}", personHandler.Update).Methods(http.MethodPut)
-	r.HandleFunc("/v1/person/{id}", personHandler.Delete).Methods(http.MethodDelete)
-	if err := http.ListenAndServe(port, r); err != nil {
+	if err := http.ListenAndServe(port, Router(personHandler, personHandlerV2)); err != nil {
 		log.Fatalln(err)
 	}
 }
+
+func Router(personHandler *handler.PersonHandler, personHandlerV2 *handlerv2.PersonHandler) *mux.Router {
+	r := mux.NewRouter()
+	r.HandleFunc("/swagger/v2/doc.json", swaggerV2)
+	r.PathPrefix("/swagger/v2/").Handler(httpSwagger.Handler(httpSwagger.URL("/swagger/v2/doc.json")))
+	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
+	r.Handle("/v1/person", negotiated(personHandler.Find, handler.TextCsv)).Methods(http.MethodGet)
+	r.HandleFunc("/v1/person/export", personHandler.Export).Methods(http.MethodGet)
+	r.Handle("/v1/person/search", negotiated(personHandler.Search)).Methods(http.MethodGet)
+	r.Handle("/v1/person/rules", negotiated(personHandler.Rules)).Methods(http.MethodGet)
+	r.Handle("/v1/person/{id}", negotiated(personHandler.FindById)).Methods(http.MethodGet)
+	r.Handle("/v1/person", negotiated(personHandler.Create)).Methods(http.MethodPost)
+	r.Handle("/v1/person/_bulk", negotiated(personHandler.Bulk)).Methods(http.MethodPost)
+	r.Handle("/v1/person/import", negotiated(personHandler.Import)).Methods(http.MethodPost)
+	r.Handle("/v1/person/{id}", negotiated(personHandler.Update)).Methods(http.MethodPut)
+	r.Handle("/v1/person/{id}", negotiated(personHandler.Patch)).Methods(http.MethodPatch)
+	r.Handle("/v1/person/{id}", negotiated(personHandler.Delete)).Methods(http.MethodDelete)
+	r.Handle("/v1/person/{id}/restore", negotiated(personHandler.Restore)).Methods(http.MethodPost)
+	r.Handle("/v1/person/{id}/history", negotiated(personHandler.History)).Methods(http.MethodGet)
+	r.Handle("/v1/person/{id}/revisions/{rev}/revert", negotiated(personHandler.Revert)).Methods(http.MethodPost)
+	r.Handle("/v2/person", negotiated(personHandlerV2.Find)).Methods(http.MethodGet)
+	r.HandleFunc("/v2/person/export", personHandlerV2.Export).Methods(http.MethodGet)
+	r.Handle("/v2/person/rules", negotiated(personHandlerV2.Rules)).Methods(http.MethodGet)
+	r.Handle("/v2/person/{id}", negotiated(personHandlerV2.FindById)).Methods(http.MethodGet)
+	r.Handle("/v2/person", negotiated(personHandlerV2.Create)).Methods(http.MethodPost)
+	r.Handle("/v2/person/{id}", negotiated(personHandlerV2.Update)).Methods(http.MethodPut)
+	r.Handle("/v2/person/{id}", negotiated(personHandlerV2.Patch)).Methods(http.MethodPatch)
+	r.Handle("/v2/person/{id}", negotiated(personHandlerV2.Delete)).Methods(http.MethodDelete)
+	r.Handle("/v2/person/{id}/restore", negotiated(personHandlerV2.Restore)).Methods(http.MethodPost)
+	r.Handle("/v2/person/{id}/history", negotiated(personHandlerV2.History)).Methods(http.MethodGet)
+	r.Handle("/v2/person/{id}/revisions/{rev}/revert", negotiated(personHandlerV2.Revert)).Methods(http.MethodPost)
+	return r
+}
+
+// swaggerV2 serves the v2 document, the swagger ui only reads the v1 one by itself.
+func swaggerV2(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = w.Write([]byte(docsv2.Doc.ReadDoc()))
+}
+
+// negotiated answers with the codec the client accepts, export is left out since it only writes ndjson.
+func negotiated(h http.HandlerFunc, others ...string) http.Handler {
+	return handler.Negotiate(codec.Default, others...)(h)
+}
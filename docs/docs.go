@@ -27,28 +27,118 @@ var doc = `{
     "paths": {
         "/person": {
             "get": {
-                "description": "Find people",
+                "description": "Find people paginated, filtered and sorted, or every matching person as CSV when text/csv is accepted",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack",
+                    "text/csv"
                 ],
                 "tags": [
                     "person"
                 ],
                 "summary": "Find people",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Part of the name, case insensitive",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email, case insensitive",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum age, from the birth date when the person has one",
+                        "name": "minAge",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum age, from the birth date when the person has one",
+                        "name": "maxAge",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only people changed at or after this RFC 3339 date time",
+                        "name": "updatedSince",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields among id, name, email and age, prefixed with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also list deleted people, for administration",
+                        "name": "includeDeleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque token from a previous page cursor, send it empty to start. Supports only sort by id or name and cannot be used with page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.Person"
+                            "$ref": "#/definitions/dto.Page"
+                        },
+                        "headers": {
+                            "Content-Disposition": {
+                                "type": "string",
+                                "description": "Attachment file name, when answering CSV"
                             }
                         }
                     },
+                    "400": {
+                        "description": "When the client sends an invalid query parameter.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "When none of the accepted media types can be produced.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
                     "500": {
                         "description": "When a internal error occur.",
                         "schema": {
-                            "$ref": "#/definitions/dto.Error"
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "When the database takes too long to answer.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     }
                 }
@@ -56,10 +146,16 @@ var doc = `{
             "post": {
                 "description": "Create person",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "person"
@@ -74,6 +170,12 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/dto.Person"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who performs the change, when not authenticated",
+                        "name": "X-Actor",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -81,46 +183,106 @@ var doc = `{
                         "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/dto.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the person"
+                            }
                         }
                     },
                     "400": {
-                        "description": "When the client sends the body with an invalid field.",
+                        "description": "When the client sends an invalid or unknown field, another id or more than one value. Other read only fields are ignored.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "When none of the accepted media types can be produced.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "When another person already has the email, its id is returned.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "When the body is larger than the configured limit.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "When the body is sent in an unsupported media type.",
                         "schema": {
-                            "$ref": "#/definitions/dto.Error"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     },
                     "422": {
                         "description": "When the client sends a broken body.",
                         "schema": {
-                            "$ref": "#/definitions/dto.Error"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     },
                     "500": {
                         "description": "When a internal error occur.",
                         "schema": {
-                            "$ref": "#/definitions/dto.Error"
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "When the database takes too long to answer.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     }
                 }
             }
         },
-        "/person/{id}": {
-            "get": {
-                "description": "Find person",
-                "produces": [
+        "/person/_bulk": {
+            "post": {
+                "description": "Run many operations in a single request, each one answered with the status its own endpoint would give",
+                "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
                 "tags": [
                     "person"
                 ],
-                "summary": "Find person",
+                "summary": "Bulk create, update and delete people",
                 "parameters": [
+                    {
+                        "description": "Operations, update and delete check the version when it is sent",
+                        "name": "operations",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.BulkOperation"
+                            }
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Stop at the first failure, the following operations are answered with 424",
+                        "name": "ordered",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Person id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Who performs the changes, when not authenticated",
+                        "name": "X-Actor",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -129,135 +291,1217 @@ var doc = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/dto.Person"
+                                "$ref": "#/definitions/dto.BulkResult"
                             }
                         }
                     },
-                    "404": {
-                        "description": "When not find a person.",
+                    "400": {
+                        "description": "When the client sends an invalid query parameter or an unknown field.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "When none of the accepted media types can be produced.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "When more than 1000 operations are sent or the body is larger than the configured limit.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "When the client sends a broken body.",
                         "schema": {
-                            "$ref": "#/definitions/dto.Error"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     },
                     "500": {
                         "description": "When a internal error occur.",
                         "schema": {
-                            "$ref": "#/definitions/dto.Error"
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "When the database takes too long to answer.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update person",
+            }
+        },
+        "/person/export": {
+            "get": {
+                "description": "Stream every person matching the listing filters as one json object per line",
                 "produces": [
-                    "application/json"
+                    "application/x-ndjson"
                 ],
                 "tags": [
                     "person"
                 ],
-                "summary": "Update person",
+                "summary": "Export people",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Person id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Part of the name, case insensitive",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email, case insensitive",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum age, from the birth date when the person has one",
+                        "name": "minAge",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum age, from the birth date when the person has one",
+                        "name": "maxAge",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also export deleted people",
+                        "name": "includeDeleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only people changed at or after this RFC 3339 date time",
+                        "name": "updatedSince",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields among id, name, email and age, prefixed with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "One per line",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Person"
+                        }
+                    },
+                    "400": {
+                        "description": "When the client sends an invalid query parameter.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "When the client does not accept application/x-ndjson.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "When a internal error occur.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "When the database takes too long to answer.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/person/import": {
+            "post": {
+                "description": "Create a person for each line of a CSV whose header row names the name, email and age or birthDate columns, other columns are ignored",
+                "consumes": [
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Import people",
+                "parameters": [
+                    {
+                        "description": "CSV with a header row",
+                        "name": "people",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who performs the changes, when not authenticated",
+                        "name": "X-Actor",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.Person"
-                            }
+                            "$ref": "#/definitions/dto.ImportReport"
                         }
                     },
                     "400": {
-                        "description": "When the client sends the body with an invalid field.",
+                        "description": "When the header row is missing a column.",
                         "schema": {
-                            "$ref": "#/definitions/dto.Error"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     },
-                    "404": {
-                        "description": "When not find a person.",
+                    "406": {
+                        "description": "When none of the accepted media types can be produced.",
                         "schema": {
-                            "$ref": "#/definitions/dto.Error"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     },
-                    "422": {
-                        "description": "When the client sends a broken body.",
+                    "413": {
+                        "description": "When the body is larger than the configured limit.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "When the body is not sent as text/csv.",
                         "schema": {
-                            "$ref": "#/definitions/dto.Error"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     },
                     "500": {
                         "description": "When a internal error occur.",
                         "schema": {
-                            "$ref": "#/definitions/dto.Error"
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "When the database takes too long to answer.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Update person",
+            }
+        },
+        "/person/rules": {
+            "get": {
+                "description": "Rules people are validated with, as configured, for clients to check what they send beforehand",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "person"
                 ],
-                "summary": "Update person",
+                "summary": "Validation rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ValidationRules"
+                        }
+                    },
+                    "406": {
+                        "description": "When none of the accepted media types can be produced.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/person/search": {
+            "get": {
+                "description": "Search people by name and email, most relevant first. Terms are matched ignoring case and accents, so Joao finds João. Without a mode, people are compared by similarity when no term is found, to find partial and misspelled names",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Search people",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Person id",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Words to look for in the name and email",
+                        "name": "q",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "text",
+                            "fuzzy"
+                        ],
+                        "type": "string",
+                        "description": "Search only by terms or only by similarity",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {},
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SearchPage"
+                        }
+                    },
                     "400": {
-                        "description": "When the client sends a invalid id",
+                        "description": "When the client sends an invalid query parameter.",
                         "schema": {
-                            "$ref": "#/definitions/dto.Error"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     },
-                    "404": {
-                        "description": "When not find a person.",
+                    "406": {
+                        "description": "When none of the accepted media types can be produced.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "When a internal error occur.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "When the database takes too long to answer.",
                         "schema": {
-                            "$ref": "#/definitions/dto.Error"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.Error": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
-                }
-            }
         },
-        "dto.Person": {
-            "type": "object",
-            "required": [
-                "age",
-                "email",
-                "name"
-            ],
-            "properties": {
-                "age": {
-                    "type": "integer"
-                },
-                "email": {
+        "/person/{id}": {
+            "get": {
+                "description": "Find person",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Find person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reconstruct the person as it was at this RFC 3339 date time",
+                        "name": "asOf",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached person",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the person"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "When the person still matches If-None-Match."
+                    },
+                    "400": {
+                        "description": "When the client sends a invalid id or asOf",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "When not find a person.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "When none of the accepted media types can be produced.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "When a internal error occur.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "When the database takes too long to answer.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update person, the birth date, phones, addresses and documents are kept when not sent",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Update person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the person must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who performs the change, when not authenticated",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the person"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "When the client sends an invalid or unknown field, another id or more than one value. Other read only fields are ignored.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "When not find a person.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "When none of the accepted media types can be produced.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "When another person already has the email, its id is returned.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "When the person does not match If-Match.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "When the body is larger than the configured limit.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "When the body is sent in an unsupported media type.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "When the client sends a broken body.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "When a internal error occur.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "When the database takes too long to answer.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete person, it can be restored until the retention period ends",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Delete person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the person must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who performs the change, when not authenticated",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {},
+                    "400": {
+                        "description": "When the client sends a invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "When not find a person.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "When none of the accepted media types can be produced.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "When the person does not match If-Match.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "When the database takes too long to answer.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Change only some fields of a person with a JSON Merge Patch (RFC 7386) or a JSON Patch (RFC 6902)",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Patch person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the person must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who performs the change, when not authenticated",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Merge patch like {\\",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the person"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "When the patched person has an invalid or unknown field or another id.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "When not find a person.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "When none of the accepted media types can be produced.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "When another person already has the email, its id is returned.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "When the person does not match If-Match or changes while patched.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "When the body is larger than the configured limit.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "When the patch is not a merge patch nor a json patch.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "When the client sends a broken id or patch.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "When a internal error occur.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "When the database takes too long to answer.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/person/{id}/history": {
+            "get": {
+                "description": "Every revision of a person, newest first, with the values before and after each change",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Person history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RevisionPage"
+                        }
+                    },
+                    "400": {
+                        "description": "When the client sends a invalid id or query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "When the person has no history.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "When none of the accepted media types can be produced.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "When a internal error occur.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "When the database takes too long to answer.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/person/{id}/restore": {
+            "post": {
+                "description": "Restore a deleted person before it is purged",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Restore person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who performs the change, when not authenticated",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the person"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "When the client sends a invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "When not find a deleted person.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "When none of the accepted media types can be produced.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "When another person already has the email, its id is returned.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "When a internal error occur.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "When the database takes too long to answer.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/person/{id}/revisions/{rev}/revert": {
+            "post": {
+                "description": "Update the person with the data it had at a revision of its history",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Revert person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version of the revision to go back to",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the person must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who performs the change, when not authenticated",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the person"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "When the client sends a invalid revision or the revision does not pass validation.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "When not find the person or the revision.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "When none of the accepted media types can be produced.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "When another person already has the email, its id is returned.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "When the person does not match If-Match.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "When the client sends a invalid id.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "When a internal error occur.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "When the database takes too long to answer.",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.Address": {
+            "type": "object",
+            "required": [
+                "city",
+                "country",
+                "street"
+            ],
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "complement": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "BR"
+                },
+                "district": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "postalCode": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "street": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "home",
+                        "work",
+                        "billing",
+                        "shipping",
+                        "other"
+                    ]
+                }
+            }
+        },
+        "dto.BulkOperation": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ]
+                },
+                "person": {
+                    "type": "object",
+                    "$ref": "#/definitions/dto.Person"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.BulkResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldError"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "email"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "email must be a valid email address"
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "email"
+                }
+            }
+        },
+        "dto.Identifier": {
+            "type": "object",
+            "required": [
+                "number",
+                "type"
+            ],
+            "properties": {
+                "number": {
+                    "type": "string",
+                    "example": "529.982.247-25"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "cpf",
+                        "rg",
+                        "passport",
+                        "other"
+                    ]
+                }
+            }
+        },
+        "dto.ImportError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldError"
+                    }
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ImportReport": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ImportError"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "imported": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.Page": {
+            "type": "object",
+            "properties": {
+                "cursor": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.Person"
+                    }
+                },
+                "next": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.Person": {
+            "type": "object",
+            "required": [
+                "email",
+                "name"
+            ],
+            "properties": {
+                "addresses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.Address"
+                    }
+                },
+                "age": {
+                    "type": "integer",
+                    "example": 34
+                },
+                "birthDate": {
+                    "type": "string",
+                    "format": "date",
+                    "example": "1990-05-17"
+                },
+                "createdAt": {
+                    "type": "string",
+                    "readOnly": true
+                },
+                "createdBy": {
+                    "type": "string",
+                    "readOnly": true
+                },
+                "deletedAt": {
+                    "type": "string",
+                    "readOnly": true
+                },
+                "documents": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.Identifier"
+                    }
+                },
+                "email": {
                     "type": "string"
                 },
                 "id": {
@@ -265,6 +1509,203 @@ var doc = `{
                 },
                 "name": {
                     "type": "string"
+                },
+                "phones": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.Phone"
+                    }
+                },
+                "updatedAt": {
+                    "type": "string",
+                    "readOnly": true
+                },
+                "updatedBy": {
+                    "type": "string",
+                    "readOnly": true
+                }
+            }
+        },
+        "dto.Phone": {
+            "type": "object",
+            "required": [
+                "number",
+                "type"
+            ],
+            "properties": {
+                "number": {
+                    "type": "string",
+                    "example": "+5511987654321"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "mobile",
+                        "home",
+                        "work",
+                        "other"
+                    ]
+                }
+            }
+        },
+        "dto.Problem": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldError"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/v1/person"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Bad Request"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "dto.Revision": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "object",
+                    "$ref": "#/definitions/dto.Person"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "before": {
+                    "type": "object",
+                    "$ref": "#/definitions/dto.Person"
+                },
+                "operation": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete",
+                        "restore",
+                        "revert"
+                    ]
+                },
+                "personId": {
+                    "type": "string"
+                },
+                "reverts": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.RevisionPage": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.Revision"
+                    }
+                },
+                "next": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SearchPage": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.Person"
+                    }
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "text",
+                        "fuzzy"
+                    ],
+                    "example": "text"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ValidationRules": {
+            "type": "object",
+            "properties": {
+                "emailDomains": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "gmail.com"
+                    ]
+                },
+                "maxAge": {
+                    "type": "integer",
+                    "example": 150
+                },
+                "maxNameLength": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "minAge": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "minNameLength": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         }
@@ -287,7 +1728,7 @@ var SwaggerInfo = swaggerInfo{
 	BasePath:    "/v1",
 	Schemes:     []string{},
 	Title:       "Person API",
-	Description: "This is a crud of people.",
+	Description: "This is a crud of people. Error messages are written in the language of the Accept-Language header: en, pt-BR or es, falling back to en.",
 }
 
 type s struct{}
@@ -0,0 +1,6 @@
+package v2
+
+import "github.com/swaggo/swag"
+
+// Doc reads the v2 swagger document, swag registers a single document and v1 has it.
+var Doc swag.Swagger
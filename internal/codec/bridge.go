@@ -0,0 +1,122 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+)
+
+// The other codecs go through the json form of a payload, so every media type carries the same field names, omits
+// the same empty fields and writes ids and dates the same way.
+
+// toGeneric turns a payload into maps, slices and scalars as its json form has them. Objects become yaml.MapSlice
+// when ordered, to keep the fields in the order they are declared.
+func toGeneric(v interface{}, ordered bool) (interface{}, error) {
+
+	raw, err := json.Marshal(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	return readGeneric(decoder, ordered)
+}
+
+func readGeneric(decoder *json.Decoder, ordered bool) (interface{}, error) {
+
+	token, err := decoder.Token()
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		if value == '[' {
+			list := []interface{}{}
+			for decoder.More() {
+				item, err := readGeneric(decoder, ordered)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, item)
+			}
+			_, err = decoder.Token()
+			return list, err
+		}
+
+		object := yaml.MapSlice{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			item, err := readGeneric(decoder, ordered)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, yaml.MapItem{Key: key, Value: item})
+		}
+		if _, err = decoder.Token(); err != nil {
+			return nil, err
+		}
+
+		if ordered {
+			return object, nil
+		}
+
+		unordered := make(map[string]interface{}, len(object))
+		for _, item := range object {
+			unordered[item.Key.(string)] = item.Value
+		}
+		return unordered, nil
+	case json.Number:
+		if integer, err := value.Int64(); err == nil {
+			return integer, nil
+		}
+		return value.Float64()
+	}
+
+	return token, nil
+}
+
+// fromGeneric fills a payload from decoded maps, slices and scalars by way of json, as strictly as the json codec.
+func fromGeneric(generic interface{}, v interface{}) error {
+
+	raw, err := json.Marshal(stringKeys(generic))
+
+	if err != nil {
+		return err
+	}
+
+	return JSON.Unmarshal(raw, v)
+}
+
+// stringKeys converts the map[interface{}]interface{} some decoders produce, json only marshals string keys.
+func stringKeys(generic interface{}) interface{} {
+
+	switch value := generic.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			converted[fmt.Sprint(k)] = stringKeys(v)
+		}
+		return converted
+	case map[string]interface{}:
+		for k, v := range value {
+			value[k] = stringKeys(v)
+		}
+		return value
+	case []interface{}:
+		for i, v := range value {
+			value[i] = stringKeys(v)
+		}
+		return value
+	}
+
+	return generic
+}
@@ -0,0 +1,164 @@
+package codec
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Codec turns payloads into the bytes of one media type and back.
+type Codec interface {
+	MediaType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Registry keeps codecs in order of preference, the first one registered is used when the client accepts anything.
+type Registry struct {
+	codecs  []Codec
+	types   map[string]Codec
+	aliases []string
+}
+
+// ProblemJSON and ProblemXML are the RFC 7807 media types of problem details, accepted as aliases of their codec.
+const ProblemJSON = "application/problem+json"
+const ProblemXML = "application/problem+xml"
+
+var Default = NewRegistry()
+
+func init() {
+	Default.Register(JSON, ProblemJSON)
+	Default.Register(XML, "text/xml", ProblemXML)
+	Default.Register(YAML, "application/x-yaml", "text/yaml")
+	Default.Register(MessagePack, "application/x-msgpack", "application/vnd.msgpack")
+}
+
+func NewRegistry() *Registry {
+	return &Registry{types: map[string]Codec{}}
+}
+
+// Register adds a codec for its media type and the given aliases, replacing any codec registered for them before.
+func (r *Registry) Register(codec Codec, aliases ...string) {
+	r.codecs = append(r.codecs, codec)
+	r.types[codec.MediaType()] = codec
+	for _, alias := range aliases {
+		r.types[alias] = codec
+		r.aliases = append(r.aliases, alias)
+	}
+}
+
+// MediaTypes lists the canonical media types in order of preference.
+func (r *Registry) MediaTypes() []string {
+	var types []string
+	for _, codec := range r.codecs {
+		types = append(types, codec.MediaType())
+	}
+	return types
+}
+
+// ForContentType finds the codec of a request body, bodies without a Content-Type are read with the default codec.
+func (r *Registry) ForContentType(contentType string) (Codec, bool) {
+
+	if strings.TrimSpace(contentType) == "" {
+		return r.fallback()
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+
+	if err != nil {
+		return nil, false
+	}
+
+	codec, ok := r.types[mediaType]
+	return codec, ok
+}
+
+// Negotiate picks the codec of the response from an Accept header. Others are media types the handler writes by
+// itself, when one of them is preferred the returned codec is nil and the media type tells which one won.
+func (r *Registry) Negotiate(accept string, others ...string) (Codec, string, bool) {
+
+	if strings.TrimSpace(accept) == "" {
+		codec, ok := r.fallback()
+		if !ok {
+			return nil, "", false
+		}
+		return codec, codec.MediaType(), true
+	}
+
+	offers := append(append(r.MediaTypes(), others...), r.aliases...)
+
+	for _, accepted := range acceptedRanges(accept) {
+		for _, offer := range offers {
+			if matches(accepted.mediaType, offer) {
+				return r.types[offer], offer, true
+			}
+		}
+	}
+
+	return nil, "", false
+}
+
+func (r *Registry) fallback() (Codec, bool) {
+	if len(r.codecs) == 0 {
+		return nil, false
+	}
+	return r.codecs[0], true
+}
+
+type acceptedRange struct {
+	mediaType string
+	quality   float64
+}
+
+// acceptedRanges parses an Accept header, best first. Ranges with quality zero are left out and ties keep the order
+// they were sent in, with more specific ranges ahead of wildcards.
+func acceptedRanges(accept string) []acceptedRange {
+
+	var ranges []acceptedRange
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		if quality > 0 {
+			ranges = append(ranges, acceptedRange{mediaType: mediaType, quality: quality})
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].quality != ranges[j].quality {
+			return ranges[i].quality > ranges[j].quality
+		}
+		return wildcards(ranges[i].mediaType) < wildcards(ranges[j].mediaType)
+	})
+
+	return ranges
+}
+
+func wildcards(mediaType string) int {
+	return strings.Count(mediaType, "*")
+}
+
+func matches(accepted string, offer string) bool {
+
+	if accepted == "*/*" || accepted == offer {
+		return true
+	}
+
+	if strings.HasSuffix(accepted, "/*") {
+		return strings.HasPrefix(offer, strings.TrimSuffix(accepted, "*"))
+	}
+
+	return false
+}
@@ -0,0 +1,20 @@
+package codec
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+// Unmarshal is strict in every codec: fields the payload does not have and data after the first value are errors.
+
+// ErrMultipleValues is returned when the data holds more than one value.
+var ErrMultipleValues = errors.New("more than one value sent")
+
+// UnknownFieldError is returned when the data has a field the payload does not.
+type UnknownFieldError struct {
+	Field string
+}
+
+func (e UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field %q", e.Field)
+}
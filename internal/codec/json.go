@@ -0,0 +1,44 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var JSON Codec = jsonCodec{}
+
+const unknownFieldPrefix = "json: unknown field "
+
+type jsonCodec struct{}
+
+func (jsonCodec) MediaType() string {
+	return "application/json"
+}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		// encoding/json has no type for this error, only its message tells the field
+		if strings.HasPrefix(err.Error(), unknownFieldPrefix) {
+			field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), unknownFieldPrefix))
+			return UnknownFieldError{Field: field}
+		}
+		return err
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return ErrMultipleValues
+	}
+
+	return nil
+}
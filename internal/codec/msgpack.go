@@ -0,0 +1,41 @@
+package codec
+
+import (
+	"bytes"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var MessagePack Codec = msgpackCodec{}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) MediaType() string {
+	return "application/msgpack"
+}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+
+	generic, err := toGeneric(v, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return msgpack.Marshal(generic)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+
+	var generic interface{}
+	reader := bytes.NewReader(data)
+
+	if err := msgpack.NewDecoder(reader).Decode(&generic); err != nil {
+		return err
+	}
+
+	if reader.Len() > 0 {
+		return ErrMultipleValues
+	}
+
+	return fromGeneric(generic, v)
+}
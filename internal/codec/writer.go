@@ -0,0 +1,35 @@
+package codec
+
+import "net/http"
+
+type writer struct {
+	http.ResponseWriter
+	codec Codec
+}
+
+// With remembers the codec negotiated for a response, the writer still flushes when the wrapped one does.
+func With(w http.ResponseWriter, codec Codec) http.ResponseWriter {
+	return writer{ResponseWriter: w, codec: codec}
+}
+
+// Of tells the codec negotiated for a response, responses that were not negotiated are written as json.
+func Of(w http.ResponseWriter) Codec {
+
+	if negotiated, ok := w.(writer); ok {
+		return negotiated.codec
+	}
+
+	return JSON
+}
+
+// Negotiated tells whether a codec was negotiated for a response.
+func Negotiated(w http.ResponseWriter) bool {
+	_, ok := w.(writer)
+	return ok
+}
+
+func (w writer) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
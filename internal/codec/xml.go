@@ -0,0 +1,264 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// XML writes the json form of a payload as elements: the root is named after the payload type, fields become
+// elements named like their json keys, list entries become item elements and null fields are left out.
+var XML Codec = xmlCodec{}
+
+const xmlItem = "item"
+
+type xmlCodec struct{}
+
+func (xmlCodec) MediaType() string {
+	return "application/xml"
+}
+
+func (xmlCodec) Marshal(v interface{}) ([]byte, error) {
+
+	raw, err := json.Marshal(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var buffer bytes.Buffer
+	buffer.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buffer)
+
+	if err = writeElement(encoder, decoder, rootName(v)); err != nil {
+		return nil, err
+	}
+
+	if err = encoder.Flush(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (xmlCodec) Unmarshal(data []byte, v interface{}) error {
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		token, err := decoder.Token()
+
+		if err != nil {
+			return err
+		}
+
+		if _, ok := token.(xml.StartElement); ok {
+			generic, err := readElement(decoder)
+			if err != nil {
+				return err
+			}
+			if !onlyRoot(decoder) {
+				return ErrMultipleValues
+			}
+			return decodeText(generic, v)
+		}
+	}
+}
+
+// onlyRoot tells whether nothing but blanks, comments and processing instructions follow the root element.
+func onlyRoot(decoder *xml.Decoder) bool {
+
+	for {
+		token, err := decoder.Token()
+
+		if err == io.EOF {
+			return true
+		}
+
+		switch value := token.(type) {
+		case xml.StartElement:
+			return false
+		case xml.CharData:
+			if len(bytes.TrimSpace(value)) > 0 {
+				return false
+			}
+		}
+
+		if err != nil {
+			return false
+		}
+	}
+}
+
+func writeElement(encoder *xml.Encoder, decoder *json.Decoder, name string) error {
+
+	token, err := decoder.Token()
+
+	if err != nil {
+		return err
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	switch value := token.(type) {
+	case nil:
+		return nil
+	case json.Delim:
+		if err = encoder.EncodeToken(start); err != nil {
+			return err
+		}
+
+		for decoder.More() {
+			child := xmlItem
+
+			if value == '{' {
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				child = key.(string)
+			}
+
+			if err = writeElement(encoder, decoder, child); err != nil {
+				return err
+			}
+		}
+
+		if _, err = decoder.Token(); err != nil {
+			return err
+		}
+
+		return encoder.EncodeToken(start.End())
+	}
+
+	return encoder.EncodeElement(fmt.Sprint(token), start)
+}
+
+// readElement reads what is left of an element whose start was consumed. Elements holding only item elements are
+// lists, elements holding other elements are objects, repeating one of them turns it into a list, and the rest is
+// text.
+func readElement(decoder *xml.Decoder) (interface{}, error) {
+
+	var text strings.Builder
+	var names []string
+	children := map[string][]interface{}{}
+
+	for {
+		token, err := decoder.Token()
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch value := token.(type) {
+		case xml.StartElement:
+			child, err := readElement(decoder)
+			if err != nil {
+				return nil, err
+			}
+			name := value.Name.Local
+			if _, ok := children[name]; !ok {
+				names = append(names, name)
+			}
+			children[name] = append(children[name], child)
+		case xml.CharData:
+			text.Write(value)
+		case xml.EndElement:
+			if len(names) == 0 {
+				return text.String(), nil
+			}
+
+			if len(names) == 1 && names[0] == xmlItem {
+				return children[xmlItem], nil
+			}
+
+			object := make(map[string]interface{}, len(names))
+			for _, name := range names {
+				if len(children[name]) == 1 {
+					object[name] = children[name][0]
+				} else {
+					object[name] = children[name]
+				}
+			}
+			return object, nil
+		}
+	}
+}
+
+var jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// decodeText fills a payload from values that are all text, converting them to the types of its json fields and
+// failing on elements the payload has no field for.
+func decodeText(generic interface{}, v interface{}) error {
+
+	var metadata mapstructure.Metadata
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Metadata:         &metadata,
+		TagName:          "json",
+		WeaklyTypedInput: true,
+		Result:           v,
+		DecodeHook: func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+			if from.Kind() != reflect.String || !reflect.PtrTo(to).Implements(jsonUnmarshaler) {
+				return data, nil
+			}
+			raw, _ := json.Marshal(data)
+			value := reflect.New(to)
+			if err := value.Interface().(json.Unmarshaler).UnmarshalJSON(raw); err != nil {
+				return nil, errors.Wrapf(err, "cannot read %q as %s", data, to)
+			}
+			return value.Elem().Interface(), nil
+		},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if err = decoder.Decode(generic); err != nil {
+		return err
+	}
+
+	if len(metadata.Unused) > 0 {
+		sort.Strings(metadata.Unused)
+		return UnknownFieldError{Field: metadata.Unused[0]}
+	}
+
+	return nil
+}
+
+func rootName(v interface{}) string {
+
+	t := reflect.TypeOf(v)
+
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil {
+		return "response"
+	}
+
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		return rootName(reflect.Zero(t.Elem()).Interface()) + "List"
+	}
+
+	if t.Name() == "" {
+		return "response"
+	}
+
+	name := []rune(t.Name())
+	name[0] = unicode.ToLower(name[0])
+	return string(name)
+}
@@ -0,0 +1,42 @@
+package codec
+
+import (
+	"bytes"
+	"gopkg.in/yaml.v2"
+	"io"
+)
+
+var YAML Codec = yamlCodec{}
+
+type yamlCodec struct{}
+
+func (yamlCodec) MediaType() string {
+	return "application/yaml"
+}
+
+func (yamlCodec) Marshal(v interface{}) ([]byte, error) {
+
+	generic, err := toGeneric(v, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(generic)
+}
+
+func (yamlCodec) Unmarshal(data []byte, v interface{}) error {
+
+	var generic, next interface{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	if err := decoder.Decode(&generic); err != nil {
+		return err
+	}
+
+	if err := decoder.Decode(&next); err != io.EOF {
+		return ErrMultipleValues
+	}
+
+	return fromGeneric(generic, v)
+}
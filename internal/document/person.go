@@ -1,10 +1,68 @@
 package document
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
 
 type Person struct {
-	Id    primitive.ObjectID `bson:"_id"`
-	Name  string             `bson:"name"`
-	Email string             `bson:"email"`
-	Age   int8               `bson:"age"`
+	Id        primitive.ObjectID `bson:"_id"`
+	Name      string             `bson:"name"`
+	Email     string             `bson:"email"`
+	Age       int                `bson:"age"`
+	BirthDate *time.Time         `bson:"birthDate,omitempty"`
+	Phones    []Phone            `bson:"phones,omitempty"`
+	Addresses []Address          `bson:"addresses,omitempty"`
+	Documents []Identifier       `bson:"documents,omitempty"`
+	Version   int64              `bson:"version" mapper:"-"`
+	CreatedAt *time.Time         `bson:"createdAt,omitempty"`
+	UpdatedAt *time.Time         `bson:"updatedAt,omitempty"`
+	CreatedBy string             `bson:"createdBy,omitempty"`
+	UpdatedBy string             `bson:"updatedBy,omitempty"`
+	DeletedAt *time.Time         `bson:"deletedAt,omitempty"`
+}
+
+type Phone struct {
+	Type   string `bson:"type"`
+	Number string `bson:"number"`
+}
+
+type Address struct {
+	Type       string `bson:"type,omitempty"`
+	Street     string `bson:"street"`
+	Number     string `bson:"number,omitempty"`
+	Complement string `bson:"complement,omitempty"`
+	District   string `bson:"district,omitempty"`
+	City       string `bson:"city"`
+	State      string `bson:"state,omitempty"`
+	PostalCode string `bson:"postalCode,omitempty"`
+	Country    string `bson:"country"`
+}
+
+type Identifier struct {
+	Type   string `bson:"type"`
+	Number string `bson:"number"`
+}
+
+// AgeAt is how old the person is at t, counted from the birth date when it is known and else the age last stored.
+func (p Person) AgeAt(t time.Time) int {
+
+	if p.BirthDate == nil {
+		return p.Age
+	}
+
+	return yearsBetween(*p.BirthDate, t)
+}
+
+// yearsBetween counts the birthdays from born until t, in UTC where birth dates are kept.
+func yearsBetween(born time.Time, t time.Time) int {
+
+	born, t = born.UTC(), t.UTC()
+	years := t.Year() - born.Year()
+
+	if t.Month() < born.Month() || (t.Month() == born.Month() && t.Day() < born.Day()) {
+		years--
+	}
+
+	return years
 }
@@ -0,0 +1,26 @@
+package document
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const (
+	Created  = "create"
+	Updated  = "update"
+	Deleted  = "delete"
+	Restored = "restore"
+	Reverted = "revert"
+)
+
+type Revision struct {
+	Id        primitive.ObjectID `bson:"_id,omitempty" mapper:"-"`
+	PersonId  primitive.ObjectID `bson:"personId"`
+	Version   int64              `bson:"version"`
+	Operation string             `bson:"operation"`
+	Before    *Person            `bson:"before,omitempty"`
+	After     *Person            `bson:"after"`
+	Reverts   int64              `bson:"reverts,omitempty"`
+	Actor     string             `bson:"actor"`
+	At        *time.Time         `bson:"at"`
+}
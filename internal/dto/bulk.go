@@ -0,0 +1,19 @@
+package dto
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type BulkOperation struct {
+	Op      string             `json:"op" enums:"create,update,delete"`
+	Id      primitive.ObjectID `json:"id,omitempty" swaggertype:"string"`
+	Version int64              `json:"version,omitempty"`
+	Person  *Person            `json:"person,omitempty"`
+}
+
+type BulkResult struct {
+	Status  int          `json:"status"`
+	Id      string       `json:"id,omitempty"`
+	Version int64        `json:"version,omitempty"`
+	Message string       `json:"message,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+// Date is a day of the calendar, written as 2006-01-02 and kept at midnight UTC.
+type Date struct {
+	time.Time
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(DateLayout))
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+
+	var raw string
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	t, err := time.Parse(DateLayout, raw)
+
+	if err != nil {
+		return err
+	}
+
+	d.Time = t
+	return nil
+}
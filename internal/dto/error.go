@@ -1,5 +0,0 @@
-package dto
-
-type Error struct {
-	Message string `json:"message"`
-}
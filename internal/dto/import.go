@@ -0,0 +1,16 @@
+package dto
+
+type ImportReport struct {
+	Imported int           `json:"imported"`
+	Failed   int           `json:"failed"`
+	Errors   []ImportError `json:"errors"`
+}
+
+// ImportError is about a line of the file, counting the header as line 1 and a quoted field spanning lines once.
+type ImportError struct {
+	Line    int          `json:"line"`
+	Status  int          `json:"status"`
+	Message string       `json:"message"`
+	Error   string       `json:"error,omitempty"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
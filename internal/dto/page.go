@@ -0,0 +1,23 @@
+package dto
+
+type Page struct {
+	Data   []Person `json:"data"`
+	Total  int64    `json:"total"`
+	Page   int64    `json:"page"`
+	Size   int64    `json:"size"`
+	Next   string   `json:"next,omitempty"`
+	Prev   string   `json:"prev,omitempty"`
+	Cursor string   `json:"cursor,omitempty"`
+}
+
+// SearchPage holds the people found most relevant first, Mode tells whether they were found by their terms or by
+// similarity.
+type SearchPage struct {
+	Data  []Person `json:"data"`
+	Total int64    `json:"total"`
+	Page  int64    `json:"page"`
+	Size  int64    `json:"size"`
+	Next  string   `json:"next,omitempty"`
+	Prev  string   `json:"prev,omitempty"`
+	Mode  string   `json:"mode" enums:"text,fuzzy" example:"text"`
+}
@@ -1,10 +1,50 @@
 package dto
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
 
+// MaxAge is the default bound of the age validated on people and accepted by filters.
+const MaxAge = 150
+
+// Person has an age computed from the birth date when it is known, v1 clients that only send the age keep working. The
+// age is a pointer so that an age of zero is told apart from a missing one.
 type Person struct {
-	Id    primitive.ObjectID `json:"id"`
-	Name  string             `json:"name" validate:"required"`
-	Email string             `json:"email" validate:"required,email"`
-	Age   int8               `json:"age" validate:"required"`
+	Id        primitive.ObjectID `json:"id"`
+	Name      string             `json:"name" validate:"required,name"`
+	Email     string             `json:"email" validate:"required,email,domain"`
+	Age       *int               `json:"age" validate:"omitempty,age" example:"34"`
+	BirthDate *Date              `json:"birthDate,omitempty" validate:"omitempty,past" swaggertype:"string" format:"date" example:"1990-05-17"`
+	Phones    []Phone            `json:"phones,omitempty" validate:"dive"`
+	Addresses []Address          `json:"addresses,omitempty" validate:"dive"`
+	Documents []Identifier       `json:"documents,omitempty" validate:"dive"`
+	CreatedAt *time.Time         `json:"createdAt,omitempty" readonly:"true"`
+	UpdatedAt *time.Time         `json:"updatedAt,omitempty" readonly:"true"`
+	CreatedBy string             `json:"createdBy,omitempty" readonly:"true"`
+	UpdatedBy string             `json:"updatedBy,omitempty" readonly:"true"`
+	DeletedAt *time.Time         `json:"deletedAt,omitempty" readonly:"true"`
+}
+
+type Phone struct {
+	Type   string `json:"type" validate:"required,oneof=mobile home work other" enums:"mobile,home,work,other"`
+	Number string `json:"number" validate:"required,e164" example:"+5511987654321"`
+}
+
+type Address struct {
+	Type       string `json:"type,omitempty" validate:"omitempty,oneof=home work billing shipping other" enums:"home,work,billing,shipping,other"`
+	Street     string `json:"street" validate:"required"`
+	Number     string `json:"number,omitempty"`
+	Complement string `json:"complement,omitempty"`
+	District   string `json:"district,omitempty"`
+	City       string `json:"city" validate:"required"`
+	State      string `json:"state,omitempty"`
+	PostalCode string `json:"postalCode,omitempty"`
+	Country    string `json:"country" validate:"required,len=2,alpha" example:"BR"`
+}
+
+// Identifier is a national document of the person, the number of a cpf must have valid check digits.
+type Identifier struct {
+	Type   string `json:"type" validate:"required,oneof=cpf rg passport other" enums:"cpf,rg,passport,other"`
+	Number string `json:"number" validate:"required" example:"529.982.247-25"`
 }
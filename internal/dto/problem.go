@@ -0,0 +1,20 @@
+package dto
+
+// Problem is a RFC 7807 problem details document. Its type is about:blank unless it carries extension members:
+// /problems/invalid-fields lists each invalid field in errors and /problems/duplicate-email tells in id who already
+// has the email.
+type Problem struct {
+	Type     string       `json:"type" example:"about:blank"`
+	Title    string       `json:"title" example:"Bad Request"`
+	Status   int          `json:"status" example:"400"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty" example:"/v1/person"`
+	Id       string       `json:"id,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field" example:"email"`
+	Rule    string `json:"rule" example:"email"`
+	Message string `json:"message" example:"email must be a valid email address"`
+}
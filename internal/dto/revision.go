@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type Revision struct {
+	PersonId  primitive.ObjectID `json:"personId"`
+	Version   int64              `json:"version"`
+	Operation string             `json:"operation" enums:"create,update,delete,restore,revert"`
+	Before    *Person            `json:"before,omitempty"`
+	After     *Person            `json:"after"`
+	Reverts   int64              `json:"reverts,omitempty"`
+	Actor     string             `json:"actor"`
+	At        *time.Time         `json:"at"`
+}
+
+type RevisionPage struct {
+	Data  []Revision `json:"data"`
+	Total int64      `json:"total"`
+	Page  int64      `json:"page"`
+	Size  int64      `json:"size"`
+	Next  string     `json:"next,omitempty"`
+	Prev  string     `json:"prev,omitempty"`
+}
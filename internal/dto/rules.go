@@ -0,0 +1,40 @@
+package dto
+
+// ValidationRules are the configurable rules people are validated with. Lengths are counted in characters and an
+// empty list of email domains allows any domain.
+type ValidationRules struct {
+	MinAge        int      `json:"minAge" example:"0"`
+	MaxAge        int      `json:"maxAge" example:"150"`
+	MinNameLength int      `json:"minNameLength" example:"1"`
+	MaxNameLength int      `json:"maxNameLength" example:"100"`
+	EmailDomains  []string `json:"emailDomains" example:"gmail.com"`
+}
+
+// DefaultValidationRules are used for the rules the properties do not set.
+func DefaultValidationRules() ValidationRules {
+	return ValidationRules{MinAge: 0, MaxAge: MaxAge, MinNameLength: 1, MaxNameLength: 100, EmailDomains: []string{}}
+}
+
+// WithDefaults gives each rule left at zero its default, so properties can set only some of them.
+func (r ValidationRules) WithDefaults() ValidationRules {
+
+	defaults := DefaultValidationRules()
+
+	if r.MaxAge == 0 {
+		r.MaxAge = defaults.MaxAge
+	}
+
+	if r.MinNameLength == 0 {
+		r.MinNameLength = defaults.MinNameLength
+	}
+
+	if r.MaxNameLength == 0 {
+		r.MaxNameLength = defaults.MaxNameLength
+	}
+
+	if r.EmailDomains == nil {
+		r.EmailDomains = defaults.EmailDomains
+	}
+
+	return r
+}
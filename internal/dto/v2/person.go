@@ -0,0 +1,34 @@
+package v2
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"person/internal/dto"
+	"time"
+)
+
+// Person is the v2 representation of a person, it has a birth date instead of an age and groups the name and the
+// contact info.
+type Person struct {
+	Id        primitive.ObjectID `json:"id"`
+	Name      Name               `json:"name"`
+	BirthDate *dto.Date          `json:"birthDate" validate:"required,past" swaggertype:"string" format:"date" example:"1990-05-17"`
+	Contact   Contact            `json:"contact"`
+	Addresses []dto.Address      `json:"addresses,omitempty" validate:"dive"`
+	Documents []dto.Identifier   `json:"documents,omitempty" validate:"dive"`
+	CreatedAt *time.Time         `json:"createdAt,omitempty" readonly:"true"`
+	UpdatedAt *time.Time         `json:"updatedAt,omitempty" readonly:"true"`
+	CreatedBy string             `json:"createdBy,omitempty" readonly:"true"`
+	UpdatedBy string             `json:"updatedBy,omitempty" readonly:"true"`
+	DeletedAt *time.Time         `json:"deletedAt,omitempty" readonly:"true"`
+}
+
+// Name is kept as a single name, split at its first space.
+type Name struct {
+	First string `json:"first" validate:"required" example:"João"`
+	Last  string `json:"last,omitempty" example:"da Silva"`
+}
+
+type Contact struct {
+	Email  string      `json:"email" validate:"required,email,domain"`
+	Phones []dto.Phone `json:"phones,omitempty" validate:"dive"`
+}
@@ -0,0 +1,17 @@
+package v2
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type Revision struct {
+	PersonId  primitive.ObjectID `json:"personId"`
+	Version   int64              `json:"version"`
+	Operation string             `json:"operation" enums:"create,update,delete,restore,revert"`
+	Before    *Person            `json:"before,omitempty"`
+	After     *Person            `json:"after"`
+	Reverts   int64              `json:"reverts,omitempty"`
+	Actor     string             `json:"actor"`
+	At        *time.Time         `json:"at"`
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"person/internal/repository"
+	"strings"
+)
+
+const ActorHeader = "X-Actor"
+
+// withActor keeps the principal set by an authentication middleware, falling back to the X-Actor header.
+func withActor(r *http.Request) context.Context {
+
+	ctx := r.Context()
+
+	if repository.ActorFrom(ctx) != repository.Anonymous {
+		return ctx
+	}
+
+	if actor := strings.TrimSpace(r.Header.Get(ActorHeader)); actor != "" {
+		return repository.WithActor(ctx, actor)
+	}
+
+	return ctx
+}
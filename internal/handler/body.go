@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"person/internal/codec"
+	"person/internal/dto"
+	"person/internal/i18n"
+	"person/internal/useful"
+	"reflect"
+)
+
+// DefaultMaxBodySize is the limit in bytes of a request body when the handler is not given one.
+const DefaultMaxBodySize int64 = 1 << 20
+
+var errUnsupportedMediaType = errors.New("unsupported media type")
+var errBodyTooLarge = errors.New("request body too large")
+
+func (p *PersonHandler) maxBodySize() int64 {
+
+	if p.MaxBodySize > 0 {
+		return p.MaxBodySize
+	}
+
+	return DefaultMaxBodySize
+}
+
+// limitedBody fails with errBodyTooLarge as soon as more than the bytes left are read.
+type limitedBody struct {
+	reader io.Reader
+	left   int64
+}
+
+func (l *limitedBody) Read(b []byte) (int, error) {
+
+	if l.left < 0 {
+		return 0, errBodyTooLarge
+	}
+
+	if int64(len(b)) > l.left+1 {
+		b = b[:l.left+1]
+	}
+
+	n, err := l.reader.Read(b)
+	l.left -= int64(n)
+
+	if l.left < 0 {
+		return n - 1, errBodyTooLarge
+	}
+
+	return n, err
+}
+
+// limitBody is the request body failing past the size limit of the handler, for bodies read as a stream.
+func (p *PersonHandler) limitBody(r *http.Request) io.Reader {
+	return &limitedBody{reader: r.Body, left: p.maxBodySize()}
+}
+
+// readBody reads the whole request body, failing past the size limit of the handler.
+func (p *PersonHandler) readBody(r *http.Request) ([]byte, error) {
+	return ioutil.ReadAll(p.limitBody(r))
+}
+
+// decodeBody reads the request body with the codec registered for its Content-Type, which rejects unknown fields
+// and more than one value.
+func (p *PersonHandler) decodeBody(r *http.Request, v interface{}) error {
+
+	c, ok := codec.Default.ForContentType(r.Header.Get("Content-Type"))
+
+	if !ok {
+		return errUnsupportedMediaType
+	}
+
+	body, err := p.readBody(r)
+
+	if err != nil {
+		return err
+	}
+
+	return c.Unmarshal(body, v)
+}
+
+// decodeFailed builds the response for an error reading a request body, returning false when there is none.
+func decodeFailed(w http.ResponseWriter, r *http.Request, err error) bool {
+
+	var unknown codec.UnknownFieldError
+
+	switch {
+	case err == nil:
+		return false
+	case err == errUnsupportedMediaType:
+		log.Errorln(useful.UnsupportedMediaType, r.Header.Get("Content-Type"))
+		useful.BuildError(w, r, http.StatusUnsupportedMediaType, useful.UnsupportedMediaType)
+	case err == errBodyTooLarge:
+		log.Errorln(useful.BodyTooLarge, err)
+		useful.BuildError(w, r, http.StatusRequestEntityTooLarge, useful.BodyTooLarge)
+	case err == codec.ErrMultipleValues:
+		log.Errorln(useful.MultipleValues, err)
+		useful.BuildError(w, r, http.StatusBadRequest, useful.MultipleValues)
+	case errors.As(err, &unknown):
+		log.Errorln(useful.UnknownFields, err)
+		field := dto.FieldError{Field: unknown.Field, Rule: "unknown", Message: fieldMessage(r, useful.UnknownField, unknown.Field)}
+		useful.BuildFieldErrors(w, r, http.StatusBadRequest, useful.UnknownFields, []dto.FieldError{field})
+	default:
+		log.Errorln(useful.ParserError, err)
+		useful.BuildError(w, r, http.StatusUnprocessableEntity, useful.BrokenBody)
+	}
+
+	return true
+}
+
+func unknownField(err error) bool {
+	var unknown codec.UnknownFieldError
+	return errors.As(err, &unknown)
+}
+
+// sentReadOnlyId builds the response when the body carries an id other than the one of the person being written,
+// ids are chosen by the server and cannot be changed.
+func sentReadOnlyId(w http.ResponseWriter, r *http.Request, body interface{}, id string) bool {
+
+	sent, _ := reflect.Indirect(reflect.ValueOf(body)).FieldByName("Id").Interface().(primitive.ObjectID)
+
+	if sent.IsZero() || sent.Hex() == id {
+		return false
+	}
+
+	log.Errorln(useful.ValidateBodyError, "read only id sent", sent.Hex())
+	field := dto.FieldError{Field: "id", Rule: "readonly", Message: fieldMessage(r, useful.ReadOnlyField, "id")}
+	useful.BuildFieldErrors(w, r, http.StatusBadRequest, useful.InvalidFields, []dto.FieldError{field})
+	return true
+}
+
+// ignoreReadOnly clears the fields tagged readonly of a dto pointer, which clients may send back from a previous read
+// but are always set by the server.
+func ignoreReadOnly(body interface{}) {
+
+	value := reflect.ValueOf(body).Elem()
+
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).Tag.Get("readonly") == "true" {
+			value.Field(i).Set(reflect.Zero(value.Field(i).Type()))
+		}
+	}
+}
+
+func fieldMessage(r *http.Request, message string, field string) string {
+	return fmt.Sprintf(useful.Localize(i18n.FromRequest(r), message), field)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"github.com/pkg/errors"
+	"net/http"
+	"person/internal/document"
+	"person/internal/dto"
+	"person/internal/i18n"
+	"person/internal/mapper"
+	"person/internal/repository"
+	"person/internal/useful"
+)
+
+// v1Mapper converts the people of bulk and import, which only take the v1 representation whatever the mapper of the
+// handler, so they cannot break when served by another version.
+var v1Mapper = &mapper.PersonMapper{}
+
+func (p *PersonHandler) bulkOperation(item dto.BulkOperation) (repository.BulkOperation, error) {
+
+	operation := repository.BulkOperation{Operation: item.Op}
+
+	switch item.Op {
+	case document.Created, document.Updated:
+		if item.Person == nil {
+			return operation, errors.New("person is required")
+		}
+		p.Normalizer.Struct(item.Person)
+		if err := p.Validator.Struct(item.Person); err != nil {
+			return operation, err
+		}
+		operation.Person = v1Mapper.ToDocument(*item.Person)
+	case document.Deleted:
+	default:
+		return operation, errors.New("op must be create, update or delete")
+	}
+
+	if item.Op == document.Created {
+		return operation, nil
+	}
+
+	if item.Id.IsZero() {
+		return operation, errors.New("id is required")
+	}
+
+	if item.Op == document.Updated {
+		operation.Fields = v1Mapper.Fields(*item.Person)
+	}
+
+	operation.Person.Id = item.Id
+	operation.Person.Version = item.Version
+
+	return operation, nil
+}
+
+func brokenBulkOperation(locale i18n.Locale, item dto.BulkOperation, err error) dto.BulkResult {
+
+	result := dto.BulkResult{Status: http.StatusBadRequest, Message: useful.BrokenBody, Error: err.Error(), Fields: fieldErrors(locale, err)}
+
+	if len(result.Fields) > 0 {
+		result.Message = useful.InvalidFields
+	}
+
+	result.Message = useful.Localize(locale, result.Message)
+
+	if !item.Id.IsZero() {
+		result.Id = item.Id.Hex()
+	}
+
+	return result
+}
+
+func skippedBulkOperation(locale i18n.Locale) dto.BulkResult {
+	return dto.BulkResult{Status: http.StatusFailedDependency, Message: useful.Localize(locale, useful.BulkSkipped)}
+}
+
+// bulkResult answers for one operation with the status and the localized message its own endpoint would.
+func bulkResult(locale i18n.Locale, operation string, result repository.BulkResult) dto.BulkResult {
+
+	item := dto.BulkResult{Id: result.Person.Id.Hex()}
+
+	if result.Person.Id.IsZero() {
+		item.Id = ""
+	}
+
+	if duplicate, ok := result.Err.(repository.DuplicateEmailError); ok {
+		item.Status = http.StatusConflict
+		item.Message = useful.Localize(locale, useful.DuplicateEmail)
+		item.Error = duplicate.Error()
+		return item
+	}
+
+	switch result.Err {
+	case nil:
+		item.Status = map[string]int{
+			document.Created: http.StatusCreated,
+			document.Updated: http.StatusOK,
+			document.Deleted: http.StatusNoContent,
+		}[operation]
+		if operation != document.Deleted {
+			item.Version = result.Person.Version
+		}
+	case repository.ErrNotFound:
+		item.Status = http.StatusNotFound
+		item.Message = useful.PersonNotFound
+	case repository.ErrVersionMismatch:
+		item.Status = http.StatusPreconditionFailed
+		item.Message = useful.VersionMismatch
+	case repository.ErrSkipped:
+		return skippedBulkOperation(locale)
+	default:
+		item.Status = http.StatusInternalServerError
+		item.Message = useful.InternalErrorOccurred
+	}
+
+	item.Message = useful.Localize(locale, item.Message)
+	return item
+}
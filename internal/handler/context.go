@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"person/internal/useful"
+)
+
+// StatusClientClosedRequest is the non standard status used when the client goes away before the response.
+const StatusClientClosedRequest = 499
+
+// abortedByContext builds the response when the repository gave up because the request context
+// was canceled or its deadline expired, returning false for any other error.
+func abortedByContext(w http.ResponseWriter, r *http.Request, err error) bool {
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		log.Warnln(useful.RequestCanceled, err)
+		useful.BuildError(w, r, StatusClientClosedRequest, useful.RequestCanceled)
+		return true
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Errorln(useful.RequestTimeout, err)
+		useful.BuildError(w, r, http.StatusGatewayTimeout, useful.RequestTimeout)
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"encoding/csv"
+	"github.com/pkg/errors"
+	"mime"
+	"net/http"
+	"person/internal/codec"
+	"person/internal/document"
+	"person/internal/dto"
+	"person/internal/i18n"
+	"person/internal/useful"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const TextCsv = "text/csv"
+
+var csvColumns = []string{"id", "name", "email", "age", "createdAt", "updatedAt", "createdBy", "updatedBy", "deletedAt", "birthDate"}
+
+var importedColumns = []string{"name", "email"}
+
+// acceptsCsv tells whether csv is preferred to every registered codec, the same way Negotiate weighs them. A response
+// Negotiate already gave a codec to is never csv, routes that do not offer it leave it out of the negotiation.
+func acceptsCsv(w http.ResponseWriter, r *http.Request) bool {
+
+	if codec.Negotiated(w) {
+		return false
+	}
+
+	_, mediaType, _ := codec.Default.Negotiate(r.Header.Get("Accept"), TextCsv)
+	return mediaType == TextCsv
+}
+
+func isCsv(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == TextCsv
+}
+
+func startCsv(w http.ResponseWriter, writer *csv.Writer) error {
+	w.Header().Set("Content-Type", TextCsv+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="people.csv"`)
+	w.WriteHeader(http.StatusOK)
+	return writer.Write(csvColumns)
+}
+
+// csvRecord writes a stored person as a row, csv has a single layout whatever the version of the api.
+func csvRecord(person document.Person) []string {
+	return []string{
+		person.Id.Hex(),
+		person.Name,
+		person.Email,
+		strconv.Itoa(person.AgeAt(time.Now())),
+		csvTime(person.CreatedAt),
+		csvTime(person.UpdatedAt),
+		person.CreatedBy,
+		person.UpdatedBy,
+		csvTime(person.DeletedAt),
+		csvDate(person.BirthDate),
+	}
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func csvDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(dto.DateLayout)
+}
+
+// csvHeader finds the position of the imported columns, ignoring the others so an export can be imported back.
+func csvHeader(header []string) (map[string]int, error) {
+
+	positions := map[string]int{}
+
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		positions[column] = i
+	}
+
+	for _, column := range importedColumns {
+		if _, ok := positions[column]; !ok {
+			return nil, errors.Errorf("column %s is missing", column)
+		}
+	}
+
+	_, age := positions["age"]
+	_, birthDate := positions["birthdate"]
+
+	if !age && !birthDate {
+		return nil, errors.New("column age or birthDate is missing")
+	}
+
+	return positions, nil
+}
+
+func brokenLine(locale i18n.Locale, line int, err error) dto.ImportError {
+
+	broken := dto.ImportError{Line: line, Status: http.StatusBadRequest, Message: useful.BrokenBody, Error: err.Error(), Fields: fieldErrors(locale, err)}
+
+	if len(broken.Fields) > 0 {
+		broken.Message = useful.InvalidFields
+	}
+
+	broken.Message = useful.Localize(locale, broken.Message)
+	return broken
+}
+
+// csvValue is empty for the optional columns the header does not have.
+func csvValue(positions map[string]int, record []string, column string) string {
+
+	position, ok := positions[column]
+
+	if !ok {
+		return ""
+	}
+
+	return strings.TrimSpace(record[position])
+}
+
+func csvPerson(positions map[string]int, record []string) (dto.Person, error) {
+
+	person := dto.Person{
+		Name:  strings.TrimSpace(record[positions["name"]]),
+		Email: strings.TrimSpace(record[positions["email"]]),
+	}
+
+	if value := csvValue(positions, record, "age"); value != "" {
+		age, err := strconv.Atoi(value)
+		if err != nil {
+			return person, errors.New("age must be an integer")
+		}
+		person.Age = &age
+	}
+
+	if value := csvValue(positions, record, "birthdate"); value != "" {
+		birthDate, err := time.Parse(dto.DateLayout, value)
+		if err != nil {
+			return person, errors.Errorf("birthDate must be a date like %s", dto.DateLayout)
+		}
+		person.BirthDate = &dto.Date{Time: birthDate}
+	}
+
+	return person, nil
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"person/internal/document"
+	"person/internal/repository"
+)
+
+type cursorToken struct {
+	Id   primitive.ObjectID `json:"id"`
+	Sort string             `json:"sort,omitempty"`
+	Name string             `json:"name,omitempty"`
+}
+
+func encodeCursor(sort []repository.Sort, last document.Person) string {
+
+	token := cursorToken{Id: last.Id, Sort: sortKey(sort)}
+
+	if token.Sort == "name" || token.Sort == "-name" {
+		token.Name = last.Name
+	}
+
+	raw, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(sort []repository.Sort, value string) (*repository.Cursor, error) {
+
+	key := sortKey(sort)
+
+	if len(sort) > 1 || (len(sort) == 1 && sort[0].Field != "id" && sort[0].Field != "name") {
+		return nil, errors.Errorf("cursor cannot be used with sort %q", key)
+	}
+
+	if value == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid cursor")
+	}
+
+	var token cursorToken
+
+	if err = json.Unmarshal(raw, &token); err != nil {
+		return nil, errors.Wrap(err, "invalid cursor")
+	}
+
+	if token.Sort != key {
+		return nil, errors.Errorf("cursor was issued for sort %q", token.Sort)
+	}
+
+	return &repository.Cursor{Id: token.Id, Name: token.Name}, nil
+}
+
+func sortKey(sort []repository.Sort) string {
+
+	key := ""
+
+	for i, s := range sort {
+		if i > 0 {
+			key += ","
+		}
+		if s.Descending {
+			key += "-"
+		}
+		key += s.Field
+	}
+
+	return key
+}
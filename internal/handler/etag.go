@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func etag(version int64) string {
+	return fmt.Sprintf("\"%d\"", version)
+}
+
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", etag(version))
+}
+
+// ifMatch returns the version required by the If-Match header, zero when any version is accepted.
+func ifMatch(r *http.Request) (int64, error) {
+
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(value, "W/"), "\""), 10, 64)
+
+	if err != nil || version < 1 {
+		return 0, errors.Errorf("invalid If-Match %q", value)
+	}
+
+	return version, nil
+}
+
+func ifNoneMatch(r *http.Request, version int64) bool {
+
+	for _, value := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		value = strings.TrimPrefix(strings.TrimSpace(value), "W/")
+		if value == "*" || value == etag(version) {
+			return true
+		}
+	}
+
+	return false
+}
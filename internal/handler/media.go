@@ -0,0 +1,47 @@
+package handler
+
+import (
+	log "github.com/sirupsen/logrus"
+	"mime"
+	"net/http"
+	"person/internal/codec"
+	"person/internal/useful"
+	"strings"
+)
+
+// acceptedTypes lists the media types of the Accept header, without their parameters.
+func acceptedTypes(r *http.Request) []string {
+
+	var types []string
+
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(accepted); err == nil {
+			types = append(types, mediaType)
+		}
+	}
+
+	return types
+}
+
+// Negotiate picks the codec of the responses from the Accept header, answering 406 when no registered codec nor any
+// of the others, media types the handler writes by itself, is accepted.
+func Negotiate(registry *codec.Registry, others ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			chosen, _, ok := registry.Negotiate(r.Header.Get("Accept"), others...)
+
+			if !ok {
+				log.Errorln(useful.NotAcceptable, r.Header.Get("Accept"))
+				useful.BuildError(w, r, http.StatusNotAcceptable, useful.NotAcceptable)
+				return
+			}
+
+			if chosen != nil {
+				w = codec.With(w, chosen)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const Ndjson = "application/x-ndjson"
+
+// flushEvery is how many people are written between flushes of a streamed export.
+const flushEvery = 100
+
+func acceptsNdjson(r *http.Request) bool {
+
+	types := acceptedTypes(r)
+
+	if len(types) == 0 {
+		return true
+	}
+
+	for _, mediaType := range types {
+		if mediaType == Ndjson || mediaType == "application/*" || mediaType == "*/*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ndjsonWriter writes one json object per line, flushing the response every flushEvery lines.
+type ndjsonWriter struct {
+	w       http.ResponseWriter
+	encoder *json.Encoder
+	written int
+}
+
+func newNdjsonWriter(w http.ResponseWriter) *ndjsonWriter {
+	return &ndjsonWriter{w: w, encoder: json.NewEncoder(w)}
+}
+
+func (n *ndjsonWriter) start() {
+	n.w.Header().Set("Content-Type", Ndjson)
+	n.w.WriteHeader(http.StatusOK)
+}
+
+func (n *ndjsonWriter) write(value interface{}) error {
+
+	if err := n.encoder.Encode(value); err != nil {
+		return err
+	}
+
+	if n.written++; n.written%flushEvery == 0 {
+		n.flush()
+	}
+
+	return nil
+}
+
+func (n *ndjsonWriter) flush() {
+	flush(n.w)
+}
+
+func flush(w http.ResponseWriter) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
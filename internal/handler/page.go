@@ -0,0 +1,33 @@
+package handler
+
+// page is written the same way as dto.Page, with the people of whichever version the mapper gives.
+type page struct {
+	Data   interface{} `json:"data"`
+	Total  int64       `json:"total"`
+	Page   int64       `json:"page"`
+	Size   int64       `json:"size"`
+	Next   string      `json:"next,omitempty"`
+	Prev   string      `json:"prev,omitempty"`
+	Cursor string      `json:"cursor,omitempty"`
+}
+
+// searchPage is written the same way as dto.SearchPage.
+type searchPage struct {
+	Data  interface{} `json:"data"`
+	Total int64       `json:"total"`
+	Page  int64       `json:"page"`
+	Size  int64       `json:"size"`
+	Next  string      `json:"next,omitempty"`
+	Prev  string      `json:"prev,omitempty"`
+	Mode  string      `json:"mode"`
+}
+
+// revisionPage is written the same way as dto.RevisionPage.
+type revisionPage struct {
+	Data  interface{} `json:"data"`
+	Total int64       `json:"total"`
+	Page  int64       `json:"page"`
+	Size  int64       `json:"size"`
+	Next  string      `json:"next,omitempty"`
+	Prev  string      `json:"prev,omitempty"`
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"mime"
+)
+
+const MergePatch = "application/merge-patch+json"
+const JsonPatch = "application/json-patch+json"
+
+var errUnsupportedPatch = errors.New("unsupported patch media type")
+
+// applyPatch applies a RFC 7386 merge patch or a RFC 6902 json patch on the original json document.
+func applyPatch(contentType string, original []byte, patch []byte) ([]byte, error) {
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+
+	if err != nil {
+		return nil, errUnsupportedPatch
+	}
+
+	switch mediaType {
+	case MergePatch:
+		return jsonpatch.MergePatch(original, patch)
+	case JsonPatch:
+		operations, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, err
+		}
+		return operations.Apply(original)
+	}
+
+	return nil, errUnsupportedPatch
+}
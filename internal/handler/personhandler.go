@@ -1,238 +1,1085 @@
 package handler
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"gopkg.in/go-playground/validator.v9"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"person/internal/codec"
+	"person/internal/document"
 	"person/internal/dto"
+	"person/internal/i18n"
 	"person/internal/mapper"
+	"person/internal/normalize"
 	"person/internal/repository"
 	"person/internal/useful"
+	"sort"
+	"strconv"
 )
 
 type PersonHandler struct {
-	Mapper     mapper.Mapper
-	Repository repository.Repository
+	Mapper      mapper.Mapper
+	Repository  repository.Repository
+	MaxBodySize int64
+	Normalizer  normalize.Normalizer
+	Validator   *Validator
 }
 
 func NewPersonHandler(mapper mapper.Mapper, repo repository.Repository) *PersonHandler {
-	return &PersonHandler{Mapper: mapper, Repository: repo}
+	return &PersonHandler{Mapper: mapper, Repository: repo, Validator: defaultValidator}
 }
 
 // FindPeople godoc
 // @Summary Find people
-// @Description Find people
-// @Produce  json
-// @Success 200 {array} dto.Person
-// @Failure 500 {object} dto.Error "When a internal error occur."
+// @Description Find people paginated, filtered and sorted, or every matching person as CSV when text/csv is accepted
+// @Produce  json,xml,application/yaml,application/msgpack,text/csv
+// @Param page query int false "Page number, starting at 1" default(1)
+// @Param size query int false "Page size, at most 100" default(20)
+// @Param name query string false "Part of the name, case insensitive"
+// @Param email query string false "Email, case insensitive"
+// @Param minAge query int false "Minimum age, from the birth date when the person has one"
+// @Param maxAge query int false "Maximum age, from the birth date when the person has one"
+// @Param updatedSince query string false "Only people changed at or after this RFC 3339 date time" example(2020-06-01T00:00:00Z)
+// @Param sort query string false "Comma separated fields among id, name, email and age, prefixed with - for descending order" example(name,-age)
+// @Param includeDeleted query bool false "Also list deleted people, for administration" default(false)
+// @Param cursor query string false "Opaque token from a previous page cursor, send it empty to start. Supports only sort by id or name and cannot be used with page"
+// @Success 200 {object} dto.Page
+// @Header 200 {string} Content-Disposition "Attachment file name, when answering CSV"
+// @Failure 400 {object} dto.Problem "When the client sends an invalid query parameter."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
 // @Router /person [get]
 // @Tags person
-func (p *PersonHandler) Find(w http.ResponseWriter, _ *http.Request) {
+func (p *PersonHandler) Find(w http.ResponseWriter, r *http.Request) {
 
-	log.Infoln(useful.FindAll)
+	query, err := buildQuery(r.URL.Query(), p.Validator.Rules())
 
-	peopleDocument, err := p.Repository.Find()
+	if err != nil {
+		log.Errorln(useful.BrokenQuery, err)
+		useful.BuildError(w, r, http.StatusBadRequest, useful.BrokenQuery)
+		return
+	}
+
+	if acceptsCsv(w, r) {
+		log.Infoln(useful.ExportCsv, query)
+		p.exportCsv(w, r, query)
+		return
+	}
+
+	log.Infoln(useful.FindAll, query)
+
+	peopleDocument, total, err := p.Repository.Find(r.Context(), query)
+
+	if abortedByContext(w, r, err) {
+		return
+	}
 
 	if err != nil {
 		log.Errorln(useful.GetDataFromDbError, err)
-		useful.BuildError(w, http.StatusInternalServerError, useful.InternalErrorOccurred)
+		useful.BuildError(w, r, http.StatusInternalServerError, useful.InternalErrorOccurred)
+		return
+	}
+
+	peopleDTO := p.Mapper.ListDocumentToListDto(peopleDocument)
+
+	page := page{Data: peopleDTO, Total: total, Page: query.Page, Size: query.Size}
+
+	if usesCursor(r.URL.Query()) {
+		if int64(len(peopleDocument)) == query.Size {
+			page.Cursor = encodeCursor(query.Sort, peopleDocument[len(peopleDocument)-1])
+			page.Next = cursorLink(r, page.Cursor)
+		}
+		useful.BuildSuccess(w, http.StatusOK, page)
 		return
 	}
 
-	peopleDTO, err := p.Mapper.ListDocumentToListDto(peopleDocument)
+	if query.Skip()+query.Size < total {
+		page.Next = pageLink(r, query.Page+1)
+	}
+
+	if query.Page > 1 {
+		page.Prev = pageLink(r, query.Page-1)
+	}
+
+	useful.BuildSuccess(w, http.StatusOK, page)
+}
+
+// exportCsv writes people as the cursor gives them, once the first one is written errors can only be logged.
+func (p *PersonHandler) exportCsv(w http.ResponseWriter, r *http.Request, query repository.Query) {
+
+	writer := csv.NewWriter(w)
+	started := false
+	written := 0
+
+	err := p.Repository.Stream(r.Context(), query, func(person document.Person) error {
+
+		if !started {
+			started = true
+			if err := startCsv(w, writer); err != nil {
+				return err
+			}
+		}
+
+		if err := writer.Write(csvRecord(person)); err != nil {
+			return err
+		}
+
+		if written++; written%flushEvery == 0 {
+			writer.Flush()
+			flush(w)
+		}
+
+		return writer.Error()
+	})
+
+	if err != nil && started {
+		if r.Context().Err() != nil {
+			log.Warnln(useful.ExportStopped, err)
+		} else {
+			log.Errorln(useful.StreamError, err)
+		}
+		return
+	}
+
+	if abortedByContext(w, r, err) {
+		return
+	}
 
 	if err != nil {
-		log.Errorln(useful.ParserError, err)
-		useful.BuildError(w, http.StatusInternalServerError, useful.InternalErrorOccurred)
+		log.Errorln(useful.GetDataFromDbError, err)
+		useful.BuildError(w, r, http.StatusInternalServerError, useful.InternalErrorOccurred)
 		return
 	}
 
-	if peopleDTO == nil {
-		useful.BuildSuccess(w, http.StatusOK, []string{})
+	if !started {
+		_ = startCsv(w, writer)
+	}
+
+	writer.Flush()
+	flush(w)
+
+	if err = writer.Error(); err != nil {
+		log.Errorln(useful.StreamError, err)
+	}
+}
+
+// ExportPeople godoc
+// @Summary Export people
+// @Description Stream every person matching the listing filters as one json object per line
+// @Produce  application/x-ndjson
+// @Param name query string false "Part of the name, case insensitive"
+// @Param email query string false "Email, case insensitive"
+// @Param minAge query int false "Minimum age, from the birth date when the person has one"
+// @Param maxAge query int false "Maximum age, from the birth date when the person has one"
+// @Param includeDeleted query bool false "Also export deleted people" default(false)
+// @Param updatedSince query string false "Only people changed at or after this RFC 3339 date time" example(2020-06-01T00:00:00Z)
+// @Param sort query string false "Comma separated fields among id, name, email and age, prefixed with - for descending order" example(name,-age)
+// @Success 200 {object} dto.Person "One per line"
+// @Failure 400 {object} dto.Problem "When the client sends an invalid query parameter."
+// @Failure 406 {object} dto.Problem "When the client does not accept application/x-ndjson."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Router /person/export [get]
+// @Tags person
+func (p *PersonHandler) Export(w http.ResponseWriter, r *http.Request) {
+
+	if !acceptsNdjson(r) {
+		log.Errorln(useful.NdjsonOnly, r.Header.Get("Accept"))
+		useful.BuildError(w, r, http.StatusNotAcceptable, useful.NdjsonOnly)
 		return
 	}
 
-	useful.BuildSuccess(w, http.StatusOK, peopleDTO)
+	query, err := buildQuery(r.URL.Query(), p.Validator.Rules())
+
+	if err != nil {
+		log.Errorln(useful.BrokenQuery, err)
+		useful.BuildError(w, r, http.StatusBadRequest, useful.BrokenQuery)
+		return
+	}
+
+	log.Infoln(useful.Export, query)
+
+	writer := newNdjsonWriter(w)
+	started := false
+
+	err = p.Repository.Stream(r.Context(), query, func(person document.Person) error {
+
+		personDTO := p.Mapper.DocumentToDto(person)
+
+		if !started {
+			started = true
+			writer.start()
+		}
+
+		return writer.write(personDTO)
+	})
+
+	if err != nil && started {
+		if r.Context().Err() != nil {
+			log.Warnln(useful.ExportStopped, err)
+		} else {
+			log.Errorln(useful.StreamError, err)
+		}
+		return
+	}
+
+	if abortedByContext(w, r, err) {
+		return
+	}
+
+	if err != nil {
+		log.Errorln(useful.GetDataFromDbError, err)
+		useful.BuildError(w, r, http.StatusInternalServerError, useful.InternalErrorOccurred)
+		return
+	}
+
+	if !started {
+		writer.start()
+	}
+
+	writer.flush()
+}
+
+// SearchPeople godoc
+// @Summary Search people
+// @Description Search people by name and email, most relevant first. Terms are matched ignoring case and accents, so Joao finds João. Without a mode, people are compared by similarity when no term is found, to find partial and misspelled names
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Param q query string true "Words to look for in the name and email" example(joao silva)
+// @Param mode query string false "Search only by terms or only by similarity" Enums(text, fuzzy)
+// @Param page query int false "Page number, starting at 1" default(1)
+// @Param size query int false "Page size, at most 100" default(20)
+// @Success 200 {object} dto.SearchPage
+// @Failure 400 {object} dto.Problem "When the client sends an invalid query parameter."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Router /person/search [get]
+// @Tags person
+func (p *PersonHandler) Search(w http.ResponseWriter, r *http.Request) {
+
+	query, mode, err := buildSearchQuery(r.URL.Query())
+
+	if err != nil {
+		log.Errorln(useful.BrokenQuery, err)
+		useful.BuildError(w, r, http.StatusBadRequest, useful.BrokenQuery)
+		return
+	}
+
+	log.Infoln(useful.Search, query.Text, mode)
+
+	query.Fuzzy = mode == searchFuzzy
+	peopleDocument, total, err := p.Repository.Search(r.Context(), query)
+
+	if err == nil && total == 0 && mode == "" {
+		query.Fuzzy = true
+		peopleDocument, total, err = p.Repository.Search(r.Context(), query)
+	}
+
+	if abortedByContext(w, r, err) {
+		return
+	}
+
+	if err != nil {
+		log.Errorln(useful.GetDataFromDbError, err)
+		useful.BuildError(w, r, http.StatusInternalServerError, useful.InternalErrorOccurred)
+		return
+	}
+
+	page := searchPage{Data: p.Mapper.ListDocumentToListDto(peopleDocument), Total: total, Page: query.Page, Size: query.Size, Mode: searchText}
+
+	if query.Fuzzy {
+		page.Mode = searchFuzzy
+	}
+
+	if query.Skip()+query.Size < total {
+		page.Next = pageLink(r, query.Page+1)
+	}
+
+	if query.Page > 1 {
+		page.Prev = pageLink(r, query.Page-1)
+	}
+
+	useful.BuildSuccess(w, http.StatusOK, page)
 }
 
 // FindPerson godoc
 // @Summary Find person
 // @Description Find person
-// @Produce  json
+// @Produce  json,xml,application/yaml,application/msgpack
 // @Param id path string true "Person id"
-// @Success 200 {array} dto.Person
-// @Failure 404 {object} dto.Error "When not find a person."
-// @Failure 500 {object} dto.Error "When a internal error occur."
+// @Param asOf query string false "Reconstruct the person as it was at this RFC 3339 date time" example(2020-06-01T00:00:00Z)
+// @Param If-None-Match header string false "ETag of a cached person"
+// @Success 200 {object} dto.Person
+// @Header 200 {string} ETag "Version of the person"
+// @Success 304 "When the person still matches If-None-Match."
+// @Failure 400 {object} dto.Problem "When the client sends a invalid id or asOf"
+// @Failure 404 {object} dto.Problem "When not find a person."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
 // @Router /person/{id} [get]
 // @Tags person
 func (p *PersonHandler) FindById(w http.ResponseWriter, r *http.Request) {
 
 	id := mux.Vars(r)["id"]
 
+	if _, ok := r.URL.Query()["asOf"]; ok {
+		p.findAsOf(w, r, id)
+		return
+	}
+
 	log.Infoln(useful.FindById, id)
 
-	personDocument, err := p.Repository.FindById(id)
+	personDocument, err := p.Repository.FindById(r.Context(), id)
+
+	if abortedByContext(w, r, err) {
+		return
+	}
 
 	if err != nil {
 		log.Errorln(useful.PersonNotFound, err)
-		useful.BuildError(w, http.StatusNotFound, useful.PersonNotFound)
+		useful.BuildError(w, r, http.StatusNotFound, useful.PersonNotFound)
 		return
 	}
 
-	personDTO, err := p.Mapper.DocumentToDto(personDocument)
+	setETag(w, personDocument.Version)
+
+	if ifNoneMatch(r, personDocument.Version) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	personDTO := p.Mapper.DocumentToDto(personDocument)
+
+	useful.BuildSuccess(w, http.StatusOK, personDTO)
+}
+
+func (p *PersonHandler) findAsOf(w http.ResponseWriter, r *http.Request, id string) {
+
+	objID, err := primitive.ObjectIDFromHex(id)
 
 	if err != nil {
-		log.Errorln(useful.ParserError, err)
-		useful.BuildError(w, http.StatusInternalServerError, useful.ParserError)
+		log.Errorln(useful.BrokenId, err)
+		useful.BuildError(w, r, http.StatusBadRequest, useful.BrokenId)
+		return
+	}
+
+	asOf, err := parseTime(r.URL.Query(), "asOf")
+
+	if err != nil || asOf == nil {
+		log.Errorln(useful.BrokenQuery, err)
+		useful.BuildError(w, r, http.StatusBadRequest, useful.BrokenQuery)
+		return
+	}
+
+	log.Infoln(useful.FindAsOf, id, asOf)
+
+	personDocument, err := p.Repository.FindAsOf(r.Context(), objID, *asOf)
+
+	if abortedByContext(w, r, err) || writeFailed(w, r, err, useful.GetDataFromDbError) {
 		return
 	}
 
+	personDTO := p.Mapper.DocumentToDto(personDocument)
+
 	useful.BuildSuccess(w, http.StatusOK, personDTO)
 }
 
+// PersonHistory godoc
+// @Summary Person history
+// @Description Every revision of a person, newest first, with the values before and after each change
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Param id path string true "Person id"
+// @Param page query int false "Page number, starting at 1" default(1)
+// @Param size query int false "Page size, at most 100" default(20)
+// @Success 200 {object} dto.RevisionPage
+// @Failure 400 {object} dto.Problem "When the client sends a invalid id or query parameter"
+// @Failure 404 {object} dto.Problem "When the person has no history."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Router /person/{id}/history [get]
+// @Tags person
+func (p *PersonHandler) History(w http.ResponseWriter, r *http.Request) {
+
+	id := mux.Vars(r)["id"]
+	objID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		log.Errorln(useful.BrokenId, err)
+		useful.BuildError(w, r, http.StatusBadRequest, useful.BrokenId)
+		return
+	}
+
+	query, err := buildPageQuery(r.URL.Query())
+
+	if err != nil {
+		log.Errorln(useful.BrokenQuery, err)
+		useful.BuildError(w, r, http.StatusBadRequest, useful.BrokenQuery)
+		return
+	}
+
+	log.Infoln(useful.History, id)
+
+	revisions, total, err := p.Repository.History(r.Context(), objID, query)
+
+	if abortedByContext(w, r, err) {
+		return
+	}
+
+	if err != nil {
+		log.Errorln(useful.GetDataFromDbError, err)
+		useful.BuildError(w, r, http.StatusInternalServerError, useful.InternalErrorOccurred)
+		return
+	}
+
+	if total == 0 {
+		useful.BuildError(w, r, http.StatusNotFound, useful.PersonNotFound)
+		return
+	}
+
+	revisionsDTO := p.Mapper.ListRevisionToListDto(revisions)
+
+	page := revisionPage{Data: revisionsDTO, Total: total, Page: query.Page, Size: query.Size}
+
+	if query.Skip()+query.Size < total {
+		page.Next = pageLink(r, query.Page+1)
+	}
+
+	if query.Page > 1 {
+		page.Prev = pageLink(r, query.Page-1)
+	}
+
+	useful.BuildSuccess(w, http.StatusOK, page)
+}
+
 // CreatePerson godoc
 // @Summary Create person
 // @Description Create person
-// @Accept  json
+// @Accept  json,xml,application/yaml,application/msgpack
 // @Param person body dto.Person true "Create person"
-// @Produce  json
+// @Param X-Actor header string false "Who performs the change, when not authenticated"
+// @Produce  json,xml,application/yaml,application/msgpack
 // @Success 201 {object} dto.Person
-// @Failure 400 {object} dto.Error "When the client sends the body with an invalid field."
-// @Failure 422 {object} dto.Error "When the client sends a broken body."
-// @Failure 500 {object} dto.Error "When a internal error occur."
+// @Header 201 {string} ETag "Version of the person"
+// @Failure 400 {object} dto.Problem "When the client sends an invalid or unknown field, another id or more than one value. Other read only fields are ignored."
+// @Failure 422 {object} dto.Problem "When the client sends a broken body."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 409 {object} dto.Problem "When another person already has the email, its id is returned."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Failure 415 {object} dto.Problem "When the body is sent in an unsupported media type."
+// @Failure 413 {object} dto.Problem "When the body is larger than the configured limit."
 // @Router /person [post]
 // @Tags person
 func (p *PersonHandler) Create(w http.ResponseWriter, r *http.Request) {
 
-	v := validator.New()
-	var body dto.Person
+	body := p.Mapper.NewDto()
 
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		log.Errorln(useful.ParserError, err)
-		useful.BuildError(w, http.StatusUnprocessableEntity, useful.BrokenBody)
+	if decodeFailed(w, r, p.decodeBody(r, body)) || sentReadOnlyId(w, r, body, "") {
 		return
 	}
 
+	ignoreReadOnly(body)
+	p.Normalizer.Struct(body)
 	log.Infoln(useful.Create, body)
 
-	if err := v.Struct(body); err != nil {
+	if err := p.Validator.Struct(body); err != nil {
 		log.Errorln(useful.ValidateBodyError, err)
-		useful.BuildError(w, http.StatusBadRequest, useful.BrokenBody)
+		useful.BuildFieldErrors(w, r, http.StatusBadRequest, useful.InvalidFields, fieldErrors(i18n.FromRequest(r), err))
 		return
 	}
 
-	personDocument, err := p.Mapper.DtoToDocument(body)
+	personDocument, err := p.Repository.Create(withActor(r), p.Mapper.DtoToDocument(body))
 
-	if err != nil {
-		log.Errorln(useful.ParserError, err)
-		useful.BuildError(w, http.StatusInternalServerError, useful.ParserError)
+	if abortedByContext(w, r, err) || writeFailed(w, r, err, useful.CreateError) {
+		return
+	}
+
+	personDTO := p.Mapper.DocumentToDto(personDocument)
+
+	setETag(w, personDocument.Version)
+	useful.BuildSuccess(w, http.StatusCreated, personDTO)
+}
+
+// ImportPeople godoc
+// @Summary Import people
+// @Description Create a person for each line of a CSV whose header row names the name, email and age or birthDate columns, other columns are ignored
+// @Accept  text/csv
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Param people body string true "CSV with a header row" example(name,email,age)
+// @Param X-Actor header string false "Who performs the changes, when not authenticated"
+// @Success 200 {object} dto.ImportReport
+// @Failure 400 {object} dto.Problem "When the header row is missing a column."
+// @Failure 415 {object} dto.Problem "When the body is not sent as text/csv."
+// @Failure 413 {object} dto.Problem "When the body is larger than the configured limit."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Router /person/import [post]
+// @Tags person
+func (p *PersonHandler) Import(w http.ResponseWriter, r *http.Request) {
+
+	if !isCsv(r) {
+		log.Errorln(useful.UnsupportedCsv, r.Header.Get("Content-Type"))
+		useful.BuildError(w, r, http.StatusUnsupportedMediaType, useful.UnsupportedCsv)
 		return
 	}
 
-	personDocument, err = p.Repository.Create(personDocument)
+	log.Infoln(useful.Import)
+
+	reader := csv.NewReader(p.limitBody(r))
+	header, err := reader.Read()
+
+	if err == errBodyTooLarge {
+		decodeFailed(w, r, err)
+		return
+	}
 
 	if err != nil {
-		log.Errorln(useful.CreateError, err)
-		useful.BuildError(w, http.StatusInternalServerError, useful.CreateError)
+		log.Errorln(useful.BrokenCsv, err)
+		useful.BuildError(w, r, http.StatusBadRequest, useful.BrokenCsv)
 		return
 	}
 
-	personDTO, err := p.Mapper.DocumentToDto(personDocument)
+	positions, err := csvHeader(header)
 
 	if err != nil {
-		log.Errorln(useful.ParserError, err)
-		useful.BuildError(w, http.StatusInternalServerError, useful.ParserError)
+		log.Errorln(useful.BrokenCsv, err)
+		useful.BuildError(w, r, http.StatusBadRequest, useful.BrokenCsv)
 		return
 	}
 
-	useful.BuildSuccess(w, http.StatusCreated, personDTO)
+	locale := i18n.FromRequest(r)
+	report := dto.ImportReport{Errors: []dto.ImportError{}}
+	var operations []repository.BulkOperation
+	var lines []int
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		// the people read so far are not imported, the client is told to send a smaller file
+		if err == errBodyTooLarge {
+			decodeFailed(w, r, err)
+			return
+		}
+
+		if parseError, ok := err.(*csv.ParseError); ok && parseError.Err != csv.ErrFieldCount {
+			report.Errors = append(report.Errors, brokenLine(locale, parseError.StartLine, err))
+			break
+		}
+
+		if err != nil {
+			report.Errors = append(report.Errors, brokenLine(locale, line, err))
+			continue
+		}
+
+		person, err := csvPerson(positions, record)
+
+		if err == nil {
+			p.Normalizer.Struct(&person)
+			err = p.Validator.Struct(person)
+		}
+
+		if err != nil {
+			report.Errors = append(report.Errors, brokenLine(locale, line, err))
+			continue
+		}
+
+		personDocument := v1Mapper.ToDocument(person)
+
+		operations = append(operations, repository.BulkOperation{Operation: document.Created, Person: personDocument})
+		lines = append(lines, line)
+	}
+
+	for start := 0; start < len(operations); start += repository.MaxBulkSize {
+		end := start + repository.MaxBulkSize
+
+		if end > len(operations) {
+			end = len(operations)
+		}
+
+		results, err := p.Repository.Bulk(withActor(r), operations[start:end], false)
+
+		if abortedByContext(w, r, err) {
+			return
+		}
+
+		if err != nil {
+			log.Errorln(useful.ImportError, err)
+			useful.BuildError(w, r, http.StatusInternalServerError, useful.ImportError)
+			return
+		}
+
+		for j, result := range results {
+			if result.Err == nil {
+				report.Imported++
+				continue
+			}
+			item := bulkResult(locale, document.Created, result)
+			report.Errors = append(report.Errors, dto.ImportError{Line: lines[start+j], Status: item.Status, Message: item.Message, Error: item.Error})
+		}
+	}
+
+	sort.SliceStable(report.Errors, func(i, j int) bool {
+		return report.Errors[i].Line < report.Errors[j].Line
+	})
+	report.Failed = len(report.Errors)
+
+	useful.BuildSuccess(w, http.StatusOK, report)
+}
+
+// BulkPeople godoc
+// @Summary Bulk create, update and delete people
+// @Description Run many operations in a single request, each one answered with the status its own endpoint would give
+// @Accept  json
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Param operations body []dto.BulkOperation true "Operations, update and delete check the version when it is sent"
+// @Param ordered query bool false "Stop at the first failure, the following operations are answered with 424" default(true)
+// @Param X-Actor header string false "Who performs the changes, when not authenticated"
+// @Success 200 {array} dto.BulkResult
+// @Failure 400 {object} dto.Problem "When the client sends an invalid query parameter or an unknown field."
+// @Failure 413 {object} dto.Problem "When more than 1000 operations are sent or the body is larger than the configured limit."
+// @Failure 422 {object} dto.Problem "When the client sends a broken body."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Router /person/_bulk [post]
+// @Tags person
+func (p *PersonHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+
+	var body []dto.BulkOperation
+	raw, err := p.readBody(r)
+
+	if err == nil {
+		err = codec.JSON.Unmarshal(raw, &body)
+	}
+
+	if decodeFailed(w, r, err) {
+		return
+	}
+
+	ordered := true
+
+	if r.URL.Query().Get("ordered") != "" {
+		var err error
+		if ordered, err = parseBool(r.URL.Query(), "ordered"); err != nil {
+			log.Errorln(useful.BrokenQuery, err)
+			useful.BuildError(w, r, http.StatusBadRequest, useful.BrokenQuery)
+			return
+		}
+	}
+
+	if len(body) > repository.MaxBulkSize {
+		log.Errorln(useful.BulkTooLarge, len(body))
+		useful.BuildError(w, r, http.StatusRequestEntityTooLarge, useful.BulkTooLarge)
+		return
+	}
+
+	log.Infoln(useful.Bulk, len(body), ordered)
+
+	locale := i18n.FromRequest(r)
+	results := make([]dto.BulkResult, len(body))
+	var operations []repository.BulkOperation
+	var positions []int
+
+	for i, item := range body {
+		operation, err := p.bulkOperation(item)
+
+		if err == nil {
+			operations = append(operations, operation)
+			positions = append(positions, i)
+			continue
+		}
+
+		results[i] = brokenBulkOperation(locale, item, err)
+
+		if ordered {
+			for j := i + 1; j < len(body); j++ {
+				results[j] = skippedBulkOperation(locale)
+			}
+			break
+		}
+	}
+
+	if len(operations) > 0 {
+		bulkResults, err := p.Repository.Bulk(withActor(r), operations, ordered)
+
+		if abortedByContext(w, r, err) {
+			return
+		}
+
+		if err != nil {
+			log.Errorln(useful.BulkError, err)
+			useful.BuildError(w, r, http.StatusInternalServerError, useful.BulkError)
+			return
+		}
+
+		for j, result := range bulkResults {
+			results[positions[j]] = bulkResult(locale, operations[j].Operation, result)
+		}
+	}
+
+	useful.BuildSuccess(w, http.StatusOK, results)
 }
 
 // UpdatePerson godoc
 // @Summary Update person
-// @Description Update person
-// @Produce  json
+// @Description Update person, the birth date, phones, addresses and documents are kept when not sent
+// @Accept  json,xml,application/yaml,application/msgpack
+// @Produce  json,xml,application/yaml,application/msgpack
 // @Param id path string true "Person id"
-// @Success 200 {array} dto.Person
-// @Failure 400 {object} dto.Error "When the client sends the body with an invalid field."
-// @Failure 422 {object} dto.Error "When the client sends a broken body."
-// @Failure 404 {object} dto.Error "When not find a person."
-// @Failure 500 {object} dto.Error "When a internal error occur."
+// @Param If-Match header string false "ETag the person must still have"
+// @Param X-Actor header string false "Who performs the change, when not authenticated"
+// @Success 200 {object} dto.Person
+// @Header 200 {string} ETag "Version of the person"
+// @Failure 400 {object} dto.Problem "When the client sends an invalid or unknown field, another id or more than one value. Other read only fields are ignored."
+// @Failure 422 {object} dto.Problem "When the client sends a broken body."
+// @Failure 404 {object} dto.Problem "When not find a person."
+// @Failure 412 {object} dto.Problem "When the person does not match If-Match."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 409 {object} dto.Problem "When another person already has the email, its id is returned."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Failure 415 {object} dto.Problem "When the body is sent in an unsupported media type."
+// @Failure 413 {object} dto.Problem "When the body is larger than the configured limit."
 // @Router /person/{id} [put]
 // @Tags person
 func (p *PersonHandler) Update(w http.ResponseWriter, r *http.Request) {
+	p.update(w, r, false)
+}
+
+// update replaces the fields the mapper says the body has, or every field when whole.
+func (p *PersonHandler) update(w http.ResponseWriter, r *http.Request, whole bool) {
 
 	id := mux.Vars(r)["id"]
-	v := validator.New()
-	var body dto.Person
+	body := p.Mapper.NewDto()
 
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		log.Errorln(useful.ParserError, err)
-		useful.BuildError(w, http.StatusUnprocessableEntity, useful.BrokenBody)
+	if decodeFailed(w, r, p.decodeBody(r, body)) || sentReadOnlyId(w, r, body, id) {
 		return
 	}
 
+	ignoreReadOnly(body)
+	p.Normalizer.Struct(body)
 	log.Infoln(useful.Update, id)
 
-	if err := v.Struct(body); err != nil {
+	if err := p.Validator.Struct(body); err != nil {
 		log.Errorln(useful.ValidateBodyError, err)
-		useful.BuildError(w, http.StatusBadRequest, useful.BrokenBody)
+		useful.BuildFieldErrors(w, r, http.StatusBadRequest, useful.InvalidFields, fieldErrors(i18n.FromRequest(r), err))
+		return
+	}
+
+	objID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		log.Errorln(useful.ParserError, err)
+		useful.BuildError(w, r, http.StatusUnprocessableEntity, useful.BrokenId)
 		return
 	}
 
+	version, err := ifMatch(r)
+
+	if err != nil {
+		log.Errorln(useful.VersionMismatch, err)
+		useful.BuildError(w, r, http.StatusPreconditionFailed, useful.VersionMismatch)
+		return
+	}
+
+	personDocument := p.Mapper.DtoToDocument(body)
+
+	personDocument.Id = objID
+	personDocument.Version = version
+
+	if whole {
+		personDocument, err = p.Repository.Update(withActor(r), personDocument)
+	} else {
+		personDocument, err = p.Repository.Patch(withActor(r), personDocument, p.Mapper.UpdatedFields(body))
+	}
+
+	if abortedByContext(w, r, err) || writeFailed(w, r, err, useful.UpdateError) {
+		return
+	}
+
+	personDTO := p.Mapper.DocumentToDto(personDocument)
+
+	setETag(w, personDocument.Version)
+	useful.BuildSuccess(w, http.StatusOK, personDTO)
+}
+
+// PatchPerson godoc
+// @Summary Patch person
+// @Description Change only some fields of a person with a JSON Merge Patch (RFC 7386) or a JSON Patch (RFC 6902)
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Param id path string true "Person id"
+// @Param If-Match header string false "ETag the person must still have"
+// @Param X-Actor header string false "Who performs the change, when not authenticated"
+// @Param patch body object true "Merge patch like {\"email\": \"new@gmail.com\"} or json patch like [{\"op\": \"replace\", \"path\": \"/email\", \"value\": \"new@gmail.com\"}]"
+// @Success 200 {object} dto.Person
+// @Header 200 {string} ETag "Version of the person"
+// @Failure 400 {object} dto.Problem "When the patched person has an invalid or unknown field or another id."
+// @Failure 404 {object} dto.Problem "When not find a person."
+// @Failure 412 {object} dto.Problem "When the person does not match If-Match or changes while patched."
+// @Failure 415 {object} dto.Problem "When the patch is not a merge patch nor a json patch."
+// @Failure 422 {object} dto.Problem "When the client sends a broken id or patch."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 409 {object} dto.Problem "When another person already has the email, its id is returned."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Failure 413 {object} dto.Problem "When the body is larger than the configured limit."
+// @Router /person/{id} [patch]
+// @Tags person
+func (p *PersonHandler) Patch(w http.ResponseWriter, r *http.Request) {
+
+	id := mux.Vars(r)["id"]
+
 	objID, err := primitive.ObjectIDFromHex(id)
 
 	if err != nil {
 		log.Errorln(useful.ParserError, err)
-		useful.BuildError(w, http.StatusUnprocessableEntity, useful.BrokenId)
+		useful.BuildError(w, r, http.StatusUnprocessableEntity, useful.BrokenId)
 		return
 	}
 
-	body.Id = objID
-	personDocument, err := p.Mapper.DtoToDocument(body)
+	version, err := ifMatch(r)
+
+	if err != nil {
+		log.Errorln(useful.VersionMismatch, err)
+		useful.BuildError(w, r, http.StatusPreconditionFailed, useful.VersionMismatch)
+		return
+	}
+
+	patch, err := p.readBody(r)
+
+	if err == errBodyTooLarge {
+		log.Errorln(useful.BodyTooLarge, err)
+		useful.BuildError(w, r, http.StatusRequestEntityTooLarge, useful.BodyTooLarge)
+		return
+	}
 
 	if err != nil {
 		log.Errorln(useful.ParserError, err)
-		useful.BuildError(w, http.StatusInternalServerError, useful.ParserError)
+		useful.BuildError(w, r, http.StatusUnprocessableEntity, useful.BrokenPatch)
 		return
 	}
 
-	count, err := p.Repository.Update(personDocument)
+	log.Infoln(useful.Patch, id)
+
+	personDocument, err := p.Repository.FindById(r.Context(), id)
+
+	if abortedByContext(w, r, err) {
+		return
+	}
 
 	if err != nil {
-		log.Errorln(useful.UpdateError, err)
-		useful.BuildError(w, http.StatusInternalServerError, useful.UpdateError)
+		log.Errorln(useful.PersonNotFound, err)
+		useful.BuildError(w, r, http.StatusNotFound, useful.PersonNotFound)
 		return
 	}
 
-	if count == 0 {
-		log.Errorln(useful.PersonNotFound)
-		useful.BuildError(w, http.StatusNotFound, useful.PersonNotFound)
+	if version != 0 && version != personDocument.Version {
+		log.Errorln(useful.VersionMismatch, version, personDocument.Version)
+		useful.BuildError(w, r, http.StatusPreconditionFailed, useful.VersionMismatch)
+		return
+	}
+
+	personDTO := p.Mapper.DocumentToDto(personDocument)
+
+	original, _ := json.Marshal(personDTO)
+	patched, err := applyPatch(r.Header.Get("Content-Type"), original, patch)
+
+	if err == errUnsupportedPatch {
+		log.Errorln(useful.UnsupportedPatch, r.Header.Get("Content-Type"))
+		useful.BuildError(w, r, http.StatusUnsupportedMediaType, useful.UnsupportedPatch)
 		return
 	}
 
-	personDTO, err := p.Mapper.DocumentToDto(personDocument)
+	body := p.Mapper.NewDto()
+
+	if err == nil {
+		err = codec.JSON.Unmarshal(patched, body)
+	}
+
+	// unknown fields are refused as in a body sent to create or update
+	if unknownField(err) {
+		decodeFailed(w, r, err)
+		return
+	}
 
 	if err != nil {
 		log.Errorln(useful.ParserError, err)
-		useful.BuildError(w, http.StatusInternalServerError, useful.ParserError)
+		useful.BuildError(w, r, http.StatusUnprocessableEntity, useful.BrokenPatch)
+		return
+	}
+
+	if sentReadOnlyId(w, r, body, id) {
+		return
+	}
+
+	p.Normalizer.Struct(body)
+
+	if err := p.Validator.Struct(body); err != nil {
+		log.Errorln(useful.ValidateBodyError, err)
+		useful.BuildFieldErrors(w, r, http.StatusBadRequest, useful.InvalidFields, fieldErrors(i18n.FromRequest(r), err))
+		return
+	}
+
+	patchedDocument := p.Mapper.DtoToDocument(body)
+
+	patchedDocument.Id = objID
+
+	changes := repository.Changes(personDocument, patchedDocument)
+
+	if len(changes) > 0 {
+		patchedDocument.Version = personDocument.Version
+		personDocument, err = p.Repository.Patch(withActor(r), patchedDocument, changes)
+	}
+
+	if abortedByContext(w, r, err) || writeFailed(w, r, err, useful.UpdateError) {
 		return
 	}
 
+	personDTO = p.Mapper.DocumentToDto(personDocument)
+
+	setETag(w, personDocument.Version)
 	useful.BuildSuccess(w, http.StatusOK, personDTO)
 }
 
+// RestorePerson godoc
+// @Summary Restore person
+// @Description Restore a deleted person before it is purged
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Param id path string true "Person id"
+// @Param X-Actor header string false "Who performs the change, when not authenticated"
+// @Success 200 {object} dto.Person
+// @Header 200 {string} ETag "Version of the person"
+// @Failure 400 {object} dto.Problem "When the client sends a invalid id"
+// @Failure 404 {object} dto.Problem "When not find a deleted person."
+// @Failure 409 {object} dto.Problem "When another person already has the email, its id is returned."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Router /person/{id}/restore [post]
+// @Tags person
+func (p *PersonHandler) Restore(w http.ResponseWriter, r *http.Request) {
+
+	id := mux.Vars(r)["id"]
+	objID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		log.Errorln(useful.BrokenId, err)
+		useful.BuildError(w, r, http.StatusBadRequest, useful.BrokenId)
+		return
+	}
+
+	log.Infoln(useful.Restore, id)
+
+	personDocument, err := p.Repository.Restore(withActor(r), objID)
+
+	if abortedByContext(w, r, err) || writeFailed(w, r, err, useful.RestoreError) {
+		return
+	}
+
+	personDTO := p.Mapper.DocumentToDto(personDocument)
+
+	setETag(w, personDocument.Version)
+	useful.BuildSuccess(w, http.StatusOK, personDTO)
+}
+
+// RevertPerson godoc
+// @Summary Revert person
+// @Description Update the person with the data it had at a revision of its history
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Param id path string true "Person id"
+// @Param rev path int true "Version of the revision to go back to"
+// @Param If-Match header string false "ETag the person must still have"
+// @Param X-Actor header string false "Who performs the change, when not authenticated"
+// @Success 200 {object} dto.Person
+// @Header 200 {string} ETag "Version of the person"
+// @Failure 400 {object} dto.Problem "When the client sends a invalid revision or the revision does not pass validation."
+// @Failure 404 {object} dto.Problem "When not find the person or the revision."
+// @Failure 409 {object} dto.Problem "When another person already has the email, its id is returned."
+// @Failure 412 {object} dto.Problem "When the person does not match If-Match."
+// @Failure 422 {object} dto.Problem "When the client sends a invalid id."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Router /person/{id}/revisions/{rev}/revert [post]
+// @Tags person
+func (p *PersonHandler) Revert(w http.ResponseWriter, r *http.Request) {
+
+	id := mux.Vars(r)["id"]
+	objID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		log.Errorln(useful.BrokenId, err)
+		useful.BuildError(w, r, http.StatusUnprocessableEntity, useful.BrokenId)
+		return
+	}
+
+	rev, err := strconv.ParseInt(mux.Vars(r)["rev"], 10, 64)
+
+	if err != nil || rev < 1 {
+		log.Errorln(useful.BrokenRevision, err)
+		useful.BuildError(w, r, http.StatusBadRequest, useful.BrokenRevision)
+		return
+	}
+
+	log.Infoln(useful.Revert, id, rev)
+
+	revision, err := p.Repository.FindRevision(r.Context(), objID, rev)
+
+	if abortedByContext(w, r, err) {
+		return
+	}
+
+	if err == repository.ErrNotFound {
+		log.Errorln(useful.RevisionNotFound, err)
+		useful.BuildError(w, r, http.StatusNotFound, useful.RevisionNotFound)
+		return
+	}
+
+	if err != nil {
+		log.Errorln(useful.GetDataFromDbError, err)
+		useful.BuildError(w, r, http.StatusInternalServerError, useful.InternalErrorOccurred)
+		return
+	}
+
+	personDTO := p.Mapper.DocumentToDto(*revision.After)
+
+	body, _ := json.Marshal(personDTO)
+	update := r.WithContext(repository.WithRevert(r.Context(), rev))
+	update.Body = ioutil.NopCloser(bytes.NewReader(body))
+	update.Header = r.Header.Clone()
+	update.Header.Set("Content-Type", codec.JSON.MediaType())
+
+	// the revision holds the whole person, so the fields it does not have are cleared
+	p.update(w, update, true)
+}
+
 // DeletePerson godoc
-// @Summary Update person
-// @Description Update person
-// @Produce  json
+// @Summary Delete person
+// @Description Delete person, it can be restored until the retention period ends
+// @Produce  json,xml,application/yaml,application/msgpack
 // @Param id path string true "Person id"
+// @Param If-Match header string false "ETag the person must still have"
+// @Param X-Actor header string false "Who performs the change, when not authenticated"
 // @Success 204
-// @Failure 400 {object} dto.Error "When the client sends a invalid id"
-// @Failure 404 {object} dto.Error "When not find a person."
+// @Failure 400 {object} dto.Problem "When the client sends a invalid id"
+// @Failure 404 {object} dto.Problem "When not find a person."
+// @Failure 412 {object} dto.Problem "When the person does not match If-Match."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
 // @Router /person/{id} [delete]
 // @Tags person
 func (p *PersonHandler) Delete(w http.ResponseWriter, r *http.Request) {
@@ -242,25 +1089,54 @@ func (p *PersonHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		log.Errorln(useful.BrokenId, err)
-		useful.BuildError(w, http.StatusBadRequest, useful.BrokenId)
+		useful.BuildError(w, r, http.StatusBadRequest, useful.BrokenId)
 		return
 	}
 
 	log.Infoln(useful.Delete, id)
 
-	count, err := p.Repository.Delete(objID)
+	version, err := ifMatch(r)
+
+	if err != nil {
+		log.Errorln(useful.VersionMismatch, err)
+		useful.BuildError(w, r, http.StatusPreconditionFailed, useful.VersionMismatch)
+		return
+	}
+
+	count, err := p.Repository.Delete(withActor(r), objID, version)
+
+	if abortedByContext(w, r, err) {
+		return
+	}
+
+	if err == repository.ErrNotFound || err == repository.ErrVersionMismatch {
+		writeFailed(w, r, err, useful.DeleteError)
+		return
+	}
 
 	if err != nil {
 		log.Errorln(useful.DeleteError, err)
-		useful.BuildError(w, http.StatusBadRequest, useful.DeleteError)
+		useful.BuildError(w, r, http.StatusBadRequest, useful.DeleteError)
 		return
 	}
 
 	if count == 0 {
 		log.Errorln(useful.PersonNotFound)
-		useful.BuildError(w, http.StatusNotFound, useful.PersonNotFound)
+		useful.BuildError(w, r, http.StatusNotFound, useful.PersonNotFound)
 		return
 	}
 
 	useful.BuildSuccess(w, http.StatusNoContent, "")
 }
+
+// ValidationRules godoc
+// @Summary Validation rules
+// @Description Rules people are validated with, as configured, for clients to check what they send beforehand
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Success 200 {object} dto.ValidationRules
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Router /person/rules [get]
+// @Tags person
+func (p *PersonHandler) Rules(w http.ResponseWriter, r *http.Request) {
+	useful.BuildSuccess(w, http.StatusOK, p.Validator.Rules())
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"net/http"
+	"net/url"
+	"person/internal/dto"
+	"person/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var sortableFields = map[string]bool{"id": true, "name": true, "email": true, "age": true}
+
+const searchText = "text"
+const searchFuzzy = "fuzzy"
+
+// buildQuery accepts the ages in the range people are validated with.
+func buildQuery(values url.Values, rules dto.ValidationRules) (repository.Query, error) {
+
+	query, err := buildPageQuery(values)
+
+	if err != nil {
+		return query, err
+	}
+
+	query.Name = values.Get("name")
+	query.Email = values.Get("email")
+
+	if query.MinAge, err = parseAge(values, "minAge", rules); err != nil {
+		return query, err
+	}
+
+	if query.MaxAge, err = parseAge(values, "maxAge", rules); err != nil {
+		return query, err
+	}
+
+	if query.MinAge != nil && query.MaxAge != nil && *query.MinAge > *query.MaxAge {
+		return query, errors.New("minAge must not be greater than maxAge")
+	}
+
+	if query.IncludeDeleted, err = parseBool(values, "includeDeleted"); err != nil {
+		return query, err
+	}
+
+	if query.UpdatedSince, err = parseTime(values, "updatedSince"); err != nil {
+		return query, err
+	}
+
+	if query.Sort, err = parseSort(values.Get("sort")); err != nil {
+		return query, err
+	}
+
+	if usesCursor(values) {
+		if values.Get("page") != "" {
+			return query, errors.New("page and cursor cannot be used together")
+		}
+		query.After, err = decodeCursor(query.Sort, values.Get("cursor"))
+	}
+
+	return query, err
+}
+
+// buildSearchQuery also gives the mode asked, empty when the search is by terms and then by similarity if no term is
+// found.
+func buildSearchQuery(values url.Values) (repository.Query, string, error) {
+
+	query, err := buildPageQuery(values)
+
+	if err != nil {
+		return query, "", err
+	}
+
+	if query.Text = strings.TrimSpace(values.Get("q")); query.Text == "" {
+		return query, "", errors.New("q is required")
+	}
+
+	mode := values.Get("mode")
+
+	if mode != "" && mode != searchText && mode != searchFuzzy {
+		return query, "", errors.Errorf("mode must be %s or %s", searchText, searchFuzzy)
+	}
+
+	return query, mode, nil
+}
+
+func buildPageQuery(values url.Values) (repository.Query, error) {
+
+	query := repository.Query{Page: repository.DefaultPage, Size: repository.DefaultSize}
+
+	var err error
+
+	if query.Page, err = parsePositive(values, "page", repository.DefaultPage); err != nil {
+		return query, err
+	}
+
+	if query.Size, err = parsePositive(values, "size", repository.DefaultSize); err != nil {
+		return query, err
+	}
+
+	if query.Size > repository.MaxSize {
+		return query, errors.Errorf("size must be at most %d", repository.MaxSize)
+	}
+
+	return query, nil
+}
+
+func usesCursor(values url.Values) bool {
+	_, ok := values["cursor"]
+	return ok
+}
+
+func parsePositive(values url.Values, key string, def int64) (int64, error) {
+
+	raw := values.Get(key)
+
+	if raw == "" {
+		return def, nil
+	}
+
+	value, err := strconv.ParseInt(raw, 10, 64)
+
+	if err != nil || value < 1 {
+		return 0, errors.Errorf("%s must be a positive integer", key)
+	}
+
+	return value, nil
+}
+
+func parseBool(values url.Values, key string) (bool, error) {
+
+	raw := values.Get(key)
+
+	if raw == "" {
+		return false, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+
+	if err != nil {
+		return false, errors.Errorf("%s must be true or false", key)
+	}
+
+	return value, nil
+}
+
+func parseTime(values url.Values, key string) (*time.Time, error) {
+
+	raw := values.Get(key)
+
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := time.Parse(time.RFC3339, raw)
+
+	if err != nil {
+		return nil, errors.Errorf("%s must be a RFC 3339 date time", key)
+	}
+
+	return &value, nil
+}
+
+func parseAge(values url.Values, key string, rules dto.ValidationRules) (*int, error) {
+
+	raw := values.Get(key)
+
+	if raw == "" {
+		return nil, nil
+	}
+
+	age, err := strconv.Atoi(raw)
+
+	if err != nil || age < rules.MinAge || age > rules.MaxAge {
+		return nil, errors.Errorf("%s must be an integer between %d and %d", key, rules.MinAge, rules.MaxAge)
+	}
+
+	return &age, nil
+}
+
+func parseSort(raw string) ([]repository.Sort, error) {
+
+	var sort []repository.Sort
+
+	if raw == "" {
+		return sort, nil
+	}
+
+	for _, field := range strings.Split(raw, ",") {
+		s := repository.Sort{Field: strings.TrimSpace(field)}
+
+		if strings.HasPrefix(s.Field, "-") {
+			s.Field = s.Field[1:]
+			s.Descending = true
+		}
+
+		if !sortableFields[s.Field] {
+			return nil, errors.Errorf("cannot sort by %q", s.Field)
+		}
+
+		sort = append(sort, s)
+	}
+
+	return sort, nil
+}
+
+func pageLink(r *http.Request, page int64) string {
+	values := r.URL.Query()
+	values.Set("page", fmt.Sprint(page))
+	return fmt.Sprintf("%s?%s", r.URL.Path, values.Encode())
+}
+
+func cursorLink(r *http.Request, cursor string) string {
+	values := r.URL.Query()
+	values.Set("cursor", cursor)
+	return fmt.Sprintf("%s?%s", r.URL.Path, values.Encode())
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"errors"
+	"gopkg.in/go-playground/validator.v9"
+	"person/internal/document"
+	"person/internal/dto"
+	v2 "person/internal/dto/v2"
+	"person/internal/i18n"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Validator checks bodies against the rules configured, one is shared by the handlers of every version. It caches the
+// rules of each struct and is safe for concurrent use.
+type Validator struct {
+	validate *validator.Validate
+	rules    dto.ValidationRules
+}
+
+var defaultValidator = mustValidator(dto.DefaultValidationRules())
+
+// NewValidator names fields after their json keys, so errors point at what the client sent, and describes them in
+// every supported locale, with the bounds of the rules.
+func NewValidator(rules dto.ValidationRules) (*Validator, error) {
+
+	if err := checkRules(rules); err != nil {
+		return nil, err
+	}
+
+	if rules.EmailDomains == nil {
+		rules.EmailDomains = []string{}
+	}
+
+	v := &Validator{validate: validator.New(), rules: rules}
+
+	v.validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	v.validate.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		return field.Interface().(dto.Date).Time
+	}, dto.Date{})
+
+	for tag, fn := range map[string]validator.Func{"past": isPast, "age": v.isAge, "name": v.isName, "domain": v.isDomain} {
+		if err := v.validate.RegisterValidation(tag, fn); err != nil {
+			return nil, err
+		}
+	}
+
+	v.validate.RegisterStructValidation(v.validatePerson, dto.Person{})
+	v.validate.RegisterStructValidation(v.validatePersonV2, v2.Person{})
+	v.validate.RegisterStructValidation(validateIdentifier, dto.Identifier{})
+
+	if err := i18n.RegisterTranslations(v.validate); err != nil {
+		return nil, err
+	}
+
+	params := map[string][]string{
+		"age":       {strconv.Itoa(rules.MinAge), strconv.Itoa(rules.MaxAge)},
+		"birthdate": {strconv.Itoa(rules.MinAge), strconv.Itoa(rules.MaxAge)},
+		"name":      {strconv.Itoa(rules.MinNameLength), strconv.Itoa(rules.MaxNameLength)},
+		"domain":    {strings.Join(rules.EmailDomains, ", ")},
+	}
+
+	if err := i18n.RegisterRuleTranslations(v.validate, params); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func mustValidator(rules dto.ValidationRules) *Validator {
+
+	v, err := NewValidator(rules)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+func checkRules(rules dto.ValidationRules) error {
+
+	switch {
+	case rules.MinAge < 0 || rules.MaxAge < rules.MinAge:
+		return errors.New("ages must be at least 0 and minAge must not be greater than maxAge")
+	case rules.MinNameLength < 0 || rules.MaxNameLength < 1 || rules.MaxNameLength < rules.MinNameLength:
+		return errors.New("name lengths must be at least 0, maxNameLength at least 1 and not less than minNameLength")
+	}
+
+	return nil
+}
+
+func (v *Validator) Struct(s interface{}) error {
+	return v.validate.Struct(s)
+}
+
+// Rules are the rules in effect, as configured.
+func (v *Validator) Rules() dto.ValidationRules {
+	return v.rules
+}
+
+func (v *Validator) isAge(fl validator.FieldLevel) bool {
+	age := int(fl.Field().Int())
+	return age >= v.rules.MinAge && age <= v.rules.MaxAge
+}
+
+func (v *Validator) isName(fl validator.FieldLevel) bool {
+	return v.validName(fl.Field().String())
+}
+
+func (v *Validator) validName(name string) bool {
+	length := utf8.RuneCountInString(name)
+	return length >= v.rules.MinNameLength && length <= v.rules.MaxNameLength
+}
+
+// isDomain allows any domain when none is configured, domains are compared regardless of case.
+func (v *Validator) isDomain(fl validator.FieldLevel) bool {
+
+	if len(v.rules.EmailDomains) == 0 {
+		return true
+	}
+
+	email := fl.Field().String()
+	domain := email[strings.LastIndex(email, "@")+1:]
+
+	for _, allowed := range v.rules.EmailDomains {
+		if strings.EqualFold(domain, allowed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isPast(fl validator.FieldLevel) bool {
+	t, ok := fl.Field().Interface().(time.Time)
+	return ok && t.Before(time.Now())
+}
+
+// validatePerson requires the age only from who does not send a birth date, the age is computed from it otherwise and
+// has to be in the same range.
+func (v *Validator) validatePerson(sl validator.StructLevel) {
+
+	person := sl.Current().Interface().(dto.Person)
+
+	if person.BirthDate == nil && person.Age == nil {
+		sl.ReportError(person.Age, "age", "Age", "required", "")
+	}
+
+	if person.BirthDate != nil && !v.validBirthDate(*person.BirthDate) {
+		sl.ReportError(person.BirthDate, "birthDate", "BirthDate", "birthdate", "")
+	}
+}
+
+// validBirthDate checks the age a birth date gives, a date in the future is left to the past rule.
+func (v *Validator) validBirthDate(birthDate dto.Date) bool {
+
+	born := birthDate.Time
+
+	if !born.Before(time.Now()) {
+		return true
+	}
+
+	age := document.Person{BirthDate: &born}.AgeAt(time.Now())
+	return age >= v.rules.MinAge && age <= v.rules.MaxAge
+}
+
+// validatePersonV2 checks the length of the whole name, the first and last names are stored together, and the age the
+// birth date gives, as there is no age sent.
+func (v *Validator) validatePersonV2(sl validator.StructLevel) {
+
+	person := sl.Current().Interface().(v2.Person)
+
+	if person.Name.First != "" && !v.validName(strings.TrimSpace(person.Name.First+" "+person.Name.Last)) {
+		sl.ReportError(person.Name, "name", "Name", "name", "")
+	}
+
+	if person.BirthDate != nil && !v.validBirthDate(*person.BirthDate) {
+		sl.ReportError(person.BirthDate, "birthDate", "BirthDate", "birthdate", "")
+	}
+}
+
+func validateIdentifier(sl validator.StructLevel) {
+
+	identifier := sl.Current().Interface().(dto.Identifier)
+
+	if identifier.Type == "cpf" && identifier.Number != "" && !validCpf(identifier.Number) {
+		sl.ReportError(identifier.Number, "number", "Number", "cpf", "")
+	}
+}
+
+// validCpf checks the two digits a cpf ends with, with or without its dots and dash. Numbers whose digits are all the
+// same pass the checksum but are never issued.
+func validCpf(number string) bool {
+
+	digits := strings.NewReplacer(".", "", "-", "").Replace(number)
+
+	if len(digits) != 11 || strings.Count(digits, digits[:1]) == len(digits) {
+		return false
+	}
+
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	for _, n := range []int{9, 10} {
+		sum := 0
+		for i := 0; i < n; i++ {
+			sum += int(digits[i]-'0') * (n + 1 - i)
+		}
+		if check := sum * 10 % 11 % 10; check != int(digits[n]-'0') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fieldErrors lists each field that failed validation with the failing rule, described in the locale.
+func fieldErrors(locale i18n.Locale, err error) []dto.FieldError {
+
+	var invalid validator.ValidationErrors
+
+	if !errors.As(err, &invalid) {
+		return nil
+	}
+
+	fields := make([]dto.FieldError, 0, len(invalid))
+
+	for _, e := range invalid {
+		fields = append(fields, dto.FieldError{Field: fieldPath(e), Rule: e.Tag(), Message: locale.FieldMessage(e)})
+	}
+
+	return fields
+}
+
+// fieldPath is where the field is in the body, like phones[0].number, without the name of the validated struct.
+func fieldPath(e validator.FieldError) string {
+	namespace := e.Namespace()
+	return namespace[strings.Index(namespace, ".")+1:]
+}
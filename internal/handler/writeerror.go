@@ -0,0 +1,44 @@
+package handler
+
+import (
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"person/internal/repository"
+	"person/internal/useful"
+)
+
+// writeFailed builds the response for an error returned by a repository write, returning false when there is none.
+func writeFailed(w http.ResponseWriter, r *http.Request, err error, message string) bool {
+
+	if duplicate, ok := err.(repository.DuplicateEmailError); ok {
+		log.Errorln(useful.DuplicateEmail, err)
+		useful.BuildErrorWithId(w, r, http.StatusConflict, useful.DuplicateEmail, ownerId(duplicate))
+		return true
+	}
+
+	switch err {
+	case nil:
+		return false
+	case repository.ErrNotFound:
+		log.Errorln(useful.PersonNotFound, err)
+		useful.BuildError(w, r, http.StatusNotFound, useful.PersonNotFound)
+	case repository.ErrVersionMismatch:
+		log.Errorln(useful.VersionMismatch, err)
+		useful.BuildError(w, r, http.StatusPreconditionFailed, useful.VersionMismatch)
+	default:
+		log.Errorln(message, err)
+		useful.BuildError(w, r, http.StatusInternalServerError, message)
+	}
+
+	return true
+}
+
+// ownerId is the id of the person having the email, empty when that person could no longer be found.
+func ownerId(duplicate repository.DuplicateEmailError) string {
+
+	if duplicate.Id.IsZero() {
+		return ""
+	}
+
+	return duplicate.Id.Hex()
+}
@@ -0,0 +1,7 @@
+// Package handlerv2 documents the v2 surface of the api, served by the same handler as v1 with the v2 mapper.
+//
+// @title Person API
+// @version 2.0
+// @description This is a crud of people, with a birth date instead of an age and grouped name and contact info. Error messages are written in the language of the Accept-Language header: en, pt-BR or es, falling back to en.
+// @BasePath /v2
+package handlerv2
@@ -0,0 +1,257 @@
+package handlerv2
+
+import (
+	"net/http"
+	"person/internal/handler"
+	"person/internal/mapper"
+	"person/internal/repository"
+)
+
+// PersonHandler serves /v2/person, its methods only describe the v2 representation and leave the work to the v1
+// handler, which writes whatever the mapper gives. Bulk and import stay v1 only.
+type PersonHandler struct {
+	*handler.PersonHandler
+}
+
+func NewPersonHandler(repo repository.Repository) *PersonHandler {
+	return &PersonHandler{PersonHandler: handler.NewPersonHandler(&mapper.PersonMapperV2{}, repo)}
+}
+
+// FindPeople godoc
+// @Summary Find people
+// @Description Find people paginated, filtered and sorted
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Param page query int false "Page number, starting at 1" default(1)
+// @Param size query int false "Page size, at most 100" default(20)
+// @Param name query string false "Part of the name, case insensitive"
+// @Param email query string false "Email, case insensitive"
+// @Param minAge query int false "Minimum age, from the birth date when the person has one"
+// @Param maxAge query int false "Maximum age, from the birth date when the person has one"
+// @Param updatedSince query string false "Only people changed at or after this RFC 3339 date time" example(2020-06-01T00:00:00Z)
+// @Param sort query string false "Comma separated fields among id, name, email and age, prefixed with - for descending order" example(name,-age)
+// @Param includeDeleted query bool false "Also list deleted people, for administration" default(false)
+// @Param cursor query string false "Opaque token from a previous page cursor, send it empty to start. Supports only sort by id or name and cannot be used with page"
+// @Success 200 {object} dto.Page{data=[]v2.Person}
+// @Failure 400 {object} dto.Problem "When the client sends an invalid query parameter."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Router /person [get]
+// @Tags person
+func (p *PersonHandler) Find(w http.ResponseWriter, r *http.Request) {
+	p.PersonHandler.Find(w, r)
+}
+
+// ExportPeople godoc
+// @Summary Export people
+// @Description Stream every person matching the listing filters as one json object per line
+// @Produce  application/x-ndjson
+// @Param name query string false "Part of the name, case insensitive"
+// @Param email query string false "Email, case insensitive"
+// @Param minAge query int false "Minimum age, from the birth date when the person has one"
+// @Param maxAge query int false "Maximum age, from the birth date when the person has one"
+// @Param includeDeleted query bool false "Also export deleted people" default(false)
+// @Param updatedSince query string false "Only people changed at or after this RFC 3339 date time" example(2020-06-01T00:00:00Z)
+// @Param sort query string false "Comma separated fields among id, name, email and age, prefixed with - for descending order" example(name,-age)
+// @Success 200 {object} v2.Person "One per line"
+// @Failure 400 {object} dto.Problem "When the client sends an invalid query parameter."
+// @Failure 406 {object} dto.Problem "When the client does not accept application/x-ndjson."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Router /person/export [get]
+// @Tags person
+func (p *PersonHandler) Export(w http.ResponseWriter, r *http.Request) {
+	p.PersonHandler.Export(w, r)
+}
+
+// FindPerson godoc
+// @Summary Find person
+// @Description Find person
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Param id path string true "Person id"
+// @Param asOf query string false "Reconstruct the person as it was at this RFC 3339 date time" example(2020-06-01T00:00:00Z)
+// @Param If-None-Match header string false "ETag of a cached person"
+// @Success 200 {object} v2.Person
+// @Header 200 {string} ETag "Version of the person"
+// @Success 304 "When the person still matches If-None-Match."
+// @Failure 400 {object} dto.Problem "When the client sends a invalid id or asOf"
+// @Failure 404 {object} dto.Problem "When not find a person."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Router /person/{id} [get]
+// @Tags person
+func (p *PersonHandler) FindById(w http.ResponseWriter, r *http.Request) {
+	p.PersonHandler.FindById(w, r)
+}
+
+// PersonHistory godoc
+// @Summary Person history
+// @Description Every revision of a person, newest first, with the values before and after each change
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Param id path string true "Person id"
+// @Param page query int false "Page number, starting at 1" default(1)
+// @Param size query int false "Page size, at most 100" default(20)
+// @Success 200 {object} dto.RevisionPage{data=[]v2.Revision}
+// @Failure 400 {object} dto.Problem "When the client sends a invalid id or query parameter"
+// @Failure 404 {object} dto.Problem "When the person has no history."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Router /person/{id}/history [get]
+// @Tags person
+func (p *PersonHandler) History(w http.ResponseWriter, r *http.Request) {
+	p.PersonHandler.History(w, r)
+}
+
+// CreatePerson godoc
+// @Summary Create person
+// @Description Create person
+// @Accept  json,xml,application/yaml,application/msgpack
+// @Param person body v2.Person true "Create person"
+// @Param X-Actor header string false "Who performs the change, when not authenticated"
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Success 201 {object} v2.Person
+// @Header 201 {string} ETag "Version of the person"
+// @Failure 400 {object} dto.Problem "When the client sends an invalid or unknown field, another id or more than one value. Other read only fields are ignored."
+// @Failure 422 {object} dto.Problem "When the client sends a broken body."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 409 {object} dto.Problem "When another person already has the email, its id is returned."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Failure 415 {object} dto.Problem "When the body is sent in an unsupported media type."
+// @Failure 413 {object} dto.Problem "When the body is larger than the configured limit."
+// @Router /person [post]
+// @Tags person
+func (p *PersonHandler) Create(w http.ResponseWriter, r *http.Request) {
+	p.PersonHandler.Create(w, r)
+}
+
+// UpdatePerson godoc
+// @Summary Update person
+// @Description Update person
+// @Accept  json,xml,application/yaml,application/msgpack
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Param id path string true "Person id"
+// @Param person body v2.Person true "Update person"
+// @Param If-Match header string false "ETag the person must still have"
+// @Param X-Actor header string false "Who performs the change, when not authenticated"
+// @Success 200 {object} v2.Person
+// @Header 200 {string} ETag "Version of the person"
+// @Failure 400 {object} dto.Problem "When the client sends an invalid or unknown field, another id or more than one value. Other read only fields are ignored."
+// @Failure 422 {object} dto.Problem "When the client sends a broken body."
+// @Failure 404 {object} dto.Problem "When not find a person."
+// @Failure 412 {object} dto.Problem "When the person does not match If-Match."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 409 {object} dto.Problem "When another person already has the email, its id is returned."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Failure 415 {object} dto.Problem "When the body is sent in an unsupported media type."
+// @Failure 413 {object} dto.Problem "When the body is larger than the configured limit."
+// @Router /person/{id} [put]
+// @Tags person
+func (p *PersonHandler) Update(w http.ResponseWriter, r *http.Request) {
+	p.PersonHandler.Update(w, r)
+}
+
+// PatchPerson godoc
+// @Summary Patch person
+// @Description Change only some fields of a person with a JSON Merge Patch (RFC 7386) or a JSON Patch (RFC 6902)
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Param id path string true "Person id"
+// @Param If-Match header string false "ETag the person must still have"
+// @Param X-Actor header string false "Who performs the change, when not authenticated"
+// @Param patch body object true "Merge patch like {\"contact\": {\"email\": \"new@gmail.com\"}} or json patch like [{\"op\": \"replace\", \"path\": \"/contact/email\", \"value\": \"new@gmail.com\"}]"
+// @Success 200 {object} v2.Person
+// @Header 200 {string} ETag "Version of the person"
+// @Failure 400 {object} dto.Problem "When the patched person has an invalid or unknown field or another id."
+// @Failure 404 {object} dto.Problem "When not find a person."
+// @Failure 412 {object} dto.Problem "When the person does not match If-Match or changes while patched."
+// @Failure 415 {object} dto.Problem "When the patch is not a merge patch nor a json patch."
+// @Failure 422 {object} dto.Problem "When the client sends a broken id or patch."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 409 {object} dto.Problem "When another person already has the email, its id is returned."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Failure 413 {object} dto.Problem "When the body is larger than the configured limit."
+// @Router /person/{id} [patch]
+// @Tags person
+func (p *PersonHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	p.PersonHandler.Patch(w, r)
+}
+
+// RestorePerson godoc
+// @Summary Restore person
+// @Description Restore a deleted person before it is purged
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Param id path string true "Person id"
+// @Param X-Actor header string false "Who performs the change, when not authenticated"
+// @Success 200 {object} v2.Person
+// @Header 200 {string} ETag "Version of the person"
+// @Failure 400 {object} dto.Problem "When the client sends a invalid id"
+// @Failure 404 {object} dto.Problem "When not find a deleted person."
+// @Failure 409 {object} dto.Problem "When another person already has the email, its id is returned."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Router /person/{id}/restore [post]
+// @Tags person
+func (p *PersonHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	p.PersonHandler.Restore(w, r)
+}
+
+// RevertPerson godoc
+// @Summary Revert person
+// @Description Update the person with the data it had at a revision of its history, which needs a birth date in v2
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Param id path string true "Person id"
+// @Param rev path int true "Version of the revision to go back to"
+// @Param If-Match header string false "ETag the person must still have"
+// @Param X-Actor header string false "Who performs the change, when not authenticated"
+// @Success 200 {object} v2.Person
+// @Header 200 {string} ETag "Version of the person"
+// @Failure 400 {object} dto.Problem "When the client sends a invalid revision or the revision does not pass validation."
+// @Failure 404 {object} dto.Problem "When not find the person or the revision."
+// @Failure 409 {object} dto.Problem "When another person already has the email, its id is returned."
+// @Failure 412 {object} dto.Problem "When the person does not match If-Match."
+// @Failure 422 {object} dto.Problem "When the client sends a invalid id."
+// @Failure 500 {object} dto.Problem "When a internal error occur."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Router /person/{id}/revisions/{rev}/revert [post]
+// @Tags person
+func (p *PersonHandler) Revert(w http.ResponseWriter, r *http.Request) {
+	p.PersonHandler.Revert(w, r)
+}
+
+// DeletePerson godoc
+// @Summary Delete person
+// @Description Delete person, it can be restored until the retention period ends
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Param id path string true "Person id"
+// @Param If-Match header string false "ETag the person must still have"
+// @Param X-Actor header string false "Who performs the change, when not authenticated"
+// @Success 204
+// @Failure 400 {object} dto.Problem "When the client sends a invalid id"
+// @Failure 404 {object} dto.Problem "When not find a person."
+// @Failure 412 {object} dto.Problem "When the person does not match If-Match."
+// @Failure 504 {object} dto.Problem "When the database takes too long to answer."
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Router /person/{id} [delete]
+// @Tags person
+func (p *PersonHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	p.PersonHandler.Delete(w, r)
+}
+
+// ValidationRules godoc
+// @Summary Validation rules
+// @Description Rules people are validated with, as configured, for clients to check what they send beforehand. The name length is the length of the first and last names joined by a space
+// @Produce  json,xml,application/yaml,application/msgpack
+// @Success 200 {object} dto.ValidationRules
+// @Failure 406 {object} dto.Problem "When none of the accepted media types can be produced."
+// @Router /person/rules [get]
+// @Tags person
+func (p *PersonHandler) Rules(w http.ResponseWriter, r *http.Request) {
+	p.PersonHandler.Rules(w, r)
+}
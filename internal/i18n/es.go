@@ -0,0 +1,49 @@
+package i18n
+
+import (
+	ut "github.com/go-playground/universal-translator"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// validator has no Spanish translations, these cover the rules the api uses, {0} is the field and {1} the rule param.
+var spanish = map[string]string{
+	"required": "{0} es un campo obligatorio",
+	"email":    "{0} debe ser una dirección de correo electrónico válida",
+	"min":      "{0} debe ser como mínimo {1}",
+	"max":      "{0} debe ser como máximo {1}",
+	"len":      "{0} debe tener una longitud de {1}",
+	"gt":       "{0} debe ser mayor que {1}",
+	"gte":      "{0} debe ser mayor o igual a {1}",
+	"lt":       "{0} debe ser menor que {1}",
+	"lte":      "{0} debe ser menor o igual a {1}",
+	"oneof":    "{0} debe ser uno de [{1}]",
+	"numeric":  "{0} debe ser un valor numérico válido",
+	"url":      "{0} debe ser una URL válida",
+	"e164":     "{0} debe ser un número de teléfono válido en formato E.164",
+	"alpha":    "{0} sólo puede contener caracteres alfabéticos",
+}
+
+func registerMessages(v *validator.Validate, trans ut.Translator, messages map[string]string) error {
+
+	for tag, text := range messages {
+		text := text
+
+		register := func(trans ut.Translator) error {
+			return trans.Add(tag, text, true)
+		}
+
+		translate := func(trans ut.Translator, e validator.FieldError) string {
+			message, err := trans.T(e.Tag(), e.Field(), e.Param())
+			if err != nil {
+				return e.(error).Error()
+			}
+			return message
+		}
+
+		if err := v.RegisterTranslation(tag, trans, register, translate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
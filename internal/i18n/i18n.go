@@ -0,0 +1,211 @@
+package i18n
+
+import (
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/pt_BR"
+	ut "github.com/go-playground/universal-translator"
+	"gopkg.in/go-playground/validator.v9"
+	entranslations "gopkg.in/go-playground/validator.v9/translations/en"
+	ptbrtranslations "gopkg.in/go-playground/validator.v9/translations/pt_BR"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const English = "en"
+const BrazilianPortuguese = "pt-BR"
+const Spanish = "es"
+
+// Locale is a language messages are written in, Tag is its BCP 47 language tag.
+type Locale struct {
+	Tag        string
+	translator ut.Translator
+}
+
+var universal = ut.New(en.New(), en.New(), pt_BR.New(), es.New())
+
+var supported = []Locale{
+	{Tag: English, translator: translator("en")},
+	{Tag: BrazilianPortuguese, translator: translator("pt_BR")},
+	{Tag: Spanish, translator: translator("es")},
+}
+
+// Default is used when none of the languages the client accepts is supported.
+var Default = supported[0]
+
+func translator(locale string) ut.Translator {
+	t, _ := universal.GetTranslator(locale)
+	return shared{t}
+}
+
+// shared lets each validator register the messages on the translators every validator uses, a message already added
+// is kept as it is.
+type shared struct {
+	ut.Translator
+}
+
+func (s shared) Add(key interface{}, text string, override bool) error {
+	return existing(s.Translator.Add(key, text, override))
+}
+
+func (s shared) AddCardinal(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return existing(s.Translator.AddCardinal(key, text, rule, override))
+}
+
+func (s shared) AddOrdinal(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return existing(s.Translator.AddOrdinal(key, text, rule, override))
+}
+
+func (s shared) AddRange(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return existing(s.Translator.AddRange(key, text, rule, override))
+}
+
+func existing(err error) error {
+	if _, ok := err.(*ut.ErrConflictingTranslation); ok {
+		return nil
+	}
+	return err
+}
+
+// FromRequest picks the locale of a response from the Accept-Language header.
+func FromRequest(r *http.Request) Locale {
+	return Negotiate(r.Header.Get("Accept-Language"))
+}
+
+// Negotiate picks the supported locale the client prefers, matching the exact tag first and then its language alone,
+// so pt-PT is answered in pt-BR and es-MX in es.
+func Negotiate(acceptLanguage string) Locale {
+
+	for _, tag := range acceptedTags(acceptLanguage) {
+		if tag == "*" {
+			return Default
+		}
+
+		for _, locale := range supported {
+			if strings.EqualFold(tag, locale.Tag) {
+				return locale
+			}
+		}
+
+		for _, locale := range supported {
+			if strings.EqualFold(language(tag), language(locale.Tag)) {
+				return locale
+			}
+		}
+	}
+
+	return Default
+}
+
+// RegisterTranslations teaches a validator to describe its errors in every supported locale.
+func RegisterTranslations(v *validator.Validate) error {
+
+	if err := entranslations.RegisterDefaultTranslations(v, translator("en")); err != nil {
+		return err
+	}
+
+	if err := ptbrtranslations.RegisterDefaultTranslations(v, translator("pt_BR")); err != nil {
+		return err
+	}
+
+	if err := registerMessages(v, translator("es"), spanish); err != nil {
+		return err
+	}
+
+	for locale, messages := range rules {
+		if err := registerMessages(v, translator(locale), messages); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RegisterRuleTranslations describes the configurable rules in every supported locale, params are the bounds each
+// rule shows after the field name.
+func RegisterRuleTranslations(v *validator.Validate, params map[string][]string) error {
+
+	for locale, messages := range configurable {
+		trans := translator(locale)
+
+		for tag, text := range messages {
+			tag, text := tag, text
+
+			register := func(trans ut.Translator) error {
+				return trans.Add(tag, text, true)
+			}
+
+			translate := func(trans ut.Translator, e validator.FieldError) string {
+				message, err := trans.T(e.Tag(), append([]string{e.Field()}, params[tag]...)...)
+				if err != nil {
+					return e.(error).Error()
+				}
+				return message
+			}
+
+			if err := v.RegisterTranslation(tag, trans, register, translate); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// FieldMessage describes a validation error, in English when the locale has no translation for its rule.
+func (l Locale) FieldMessage(e validator.FieldError) string {
+
+	if message := e.Translate(l.translator); message != e.(error).Error() {
+		return message
+	}
+
+	return e.Translate(Default.translator)
+}
+
+type acceptedTag struct {
+	tag     string
+	quality float64
+}
+
+// acceptedTags lists the language tags of an Accept-Language header, best first, leaving out those with quality zero.
+func acceptedTags(acceptLanguage string) []string {
+
+	var accepted []acceptedTag
+
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		params := strings.Split(part, ";")
+		tag := strings.TrimSpace(params[0])
+		quality := 1.0
+
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		if tag != "" && quality > 0 {
+			accepted = append(accepted, acceptedTag{tag: tag, quality: quality})
+		}
+	}
+
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].quality > accepted[j].quality
+	})
+
+	tags := make([]string, 0, len(accepted))
+	for _, a := range accepted {
+		tags = append(tags, a.tag)
+	}
+
+	return tags
+}
+
+func language(tag string) string {
+	return strings.SplitN(tag, "-", 2)[0]
+}
@@ -0,0 +1,39 @@
+package i18n
+
+// rules describes the validations the api registers itself, by the locale of the translator.
+var rules = map[string]map[string]string{
+	"en": {
+		"past": "{0} must be a date in the past",
+		"cpf":  "{0} must be a valid CPF",
+	},
+	"pt_BR": {
+		"past": "{0} deve ser uma data no passado",
+		"cpf":  "{0} deve ser um CPF válido",
+	},
+	"es": {
+		"past": "{0} debe ser una fecha en el pasado",
+		"cpf":  "{0} debe ser un CPF válido",
+	},
+}
+
+// configurable describes the validations whose bounds come from the properties, {1} and on are the bounds.
+var configurable = map[string]map[string]string{
+	"en": {
+		"age":       "{0} must be between {1} and {2}",
+		"birthdate": "{0} must give an age between {1} and {2}",
+		"name":      "{0} must be between {1} and {2} characters long",
+		"domain":    "{0} must use one of the domains {1}",
+	},
+	"pt_BR": {
+		"age":       "{0} deve estar entre {1} e {2}",
+		"birthdate": "{0} deve resultar em uma idade entre {1} e {2}",
+		"name":      "{0} deve ter entre {1} e {2} caracteres",
+		"domain":    "{0} deve usar um dos domínios {1}",
+	},
+	"es": {
+		"age":       "{0} debe estar entre {1} y {2}",
+		"birthdate": "{0} debe dar una edad entre {1} y {2}",
+		"name":      "{0} debe tener entre {1} y {2} caracteres",
+		"domain":    "{0} debe usar uno de los dominios {1}",
+	},
+}
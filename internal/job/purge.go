@@ -0,0 +1,55 @@
+package job
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"person/internal/repository"
+	"person/internal/useful"
+	"time"
+)
+
+type Purge struct {
+	Repository repository.Repository
+	Retention  time.Duration
+	Interval   time.Duration
+}
+
+// Start runs the purge every interval until the context is done, a zero interval disables it.
+func (p Purge) Start(ctx context.Context) {
+
+	if p.Interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(p.Interval)
+		defer ticker.Stop()
+
+		for {
+			p.Run(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Run permanently removes the people deleted longer than the retention period ago.
+func (p Purge) Run(ctx context.Context) {
+
+	deletedBefore := time.Now().Add(-p.Retention)
+
+	log.Infoln(useful.Purge, deletedBefore)
+
+	count, err := p.Repository.Purge(ctx, deletedBefore)
+
+	if err != nil {
+		log.Errorln(useful.PurgeError, err)
+		return
+	}
+
+	log.Infoln(useful.Purged, count)
+}
@@ -2,11 +2,17 @@ package mapper
 
 import (
 	"person/internal/document"
-	"person/internal/dto"
 )
 
+// Mapper converts people between their storage and the representation of one version of the api, the dtos are the
+// types of that version. NewDto gives a pointer to an empty dto for a body to be decoded into, which is what
+// DtoToDocument takes, any other dto is a bug and panics. Lists are never nil, so they are written as empty lists.
+// UpdatedFields are the stored fields an update with the dto replaces, so it leaves alone what only other versions set.
 type Mapper interface {
-	DocumentToDto(document document.Person) (dto.Person, error)
-	ListDocumentToListDto(document []document.Person) ([]dto.Person, error)
-	DtoToDocument(dto dto.Person) (document.Person, error)
+	NewDto() interface{}
+	DocumentToDto(document document.Person) interface{}
+	ListDocumentToListDto(document []document.Person) interface{}
+	DtoToDocument(dto interface{}) document.Person
+	UpdatedFields(dto interface{}) []string
+	ListRevisionToListDto(revisions []document.Revision) interface{}
 }
@@ -1,28 +1,123 @@
 package mapper
 
 import (
-	"github.com/mitchellh/mapstructure"
 	"person/internal/document"
 	"person/internal/dto"
+	"time"
 )
 
+//go:generate go run ../../cmd/mappergen -from person/internal/document -to person/internal/dto -types Person,Phone,Address,Identifier,Revision -output personmapper_gen.go
+
+// PersonMapper maps people to the v1 representation, dto.Person, with the conversions mappergen writes from the
+// fields both sides have.
 type PersonMapper struct {
 }
 
-func (p *PersonMapper) DocumentToDto(document document.Person) (dto.Person, error) {
-	var person dto.Person
-	err := mapstructure.Decode(document, &person)
-	return person, err
+func (p *PersonMapper) NewDto() interface{} {
+	return &dto.Person{}
+}
+
+func (p *PersonMapper) DocumentToDto(document document.Person) interface{} {
+	person := personToDto(document)
+	person.Age = intPointer(document.AgeAt(time.Now()))
+	return person
+}
+
+func (p *PersonMapper) ListDocumentToListDto(documents []document.Person) interface{} {
+
+	people := make([]dto.Person, len(documents))
+
+	for i, person := range documents {
+		people[i] = personToDto(person)
+		people[i].Age = intPointer(person.AgeAt(time.Now()))
+	}
+
+	return people
+}
+
+func (p *PersonMapper) DtoToDocument(person interface{}) document.Person {
+	return p.ToDocument(*person.(*dto.Person))
 }
 
-func (p *PersonMapper) ListDocumentToListDto(document []document.Person) ([]dto.Person, error) {
-	var people []dto.Person
-	err := mapstructure.Decode(document, &people)
-	return people, err
+// ToDocument is the typed DtoToDocument, for the endpoints only v1 has. It stores the age the birth date gives today,
+// so the age kept agrees with the birth date when saved.
+func (p *PersonMapper) ToDocument(person dto.Person) document.Person {
+	result := personToDocument(person)
+	result.Age = result.AgeAt(time.Now())
+	return result
 }
 
-func (p *PersonMapper) DtoToDocument(dto dto.Person) (document.Person, error) {
-	var person document.Person
-	err := mapstructure.Decode(dto, &person)
-	return person, err
+func (p *PersonMapper) UpdatedFields(person interface{}) []string {
+	return p.Fields(*person.(*dto.Person))
+}
+
+// Fields is the typed UpdatedFields. The birth date, contacts and documents are only replaced when sent, so a client
+// that knows just the name, email and age keeps what v2 clients saved.
+func (p *PersonMapper) Fields(person dto.Person) []string {
+
+	fields := []string{"name", "email", "age"}
+
+	if person.BirthDate != nil {
+		fields = append(fields, "birthDate")
+	}
+
+	if person.Phones != nil {
+		fields = append(fields, "phones")
+	}
+
+	if person.Addresses != nil {
+		fields = append(fields, "addresses")
+	}
+
+	if person.Documents != nil {
+		fields = append(fields, "documents")
+	}
+
+	return fields
+}
+
+func (p *PersonMapper) ListRevisionToListDto(revisions []document.Revision) interface{} {
+
+	result := revisionListToDto(revisions)
+
+	if result == nil {
+		result = []dto.Revision{}
+	}
+
+	return result
+}
+
+// timeToDate and dateToTime are found by mappergen, a document keeps a birth date as the time the day a dto shows
+// starts.
+func timeToDate(t *time.Time) *dto.Date {
+
+	if t == nil {
+		return nil
+	}
+
+	return &dto.Date{Time: *t}
+}
+
+func dateToTime(d *dto.Date) *time.Time {
+
+	if d == nil {
+		return nil
+	}
+
+	t := d.Time
+	return &t
+}
+
+// intPointer and intValue are found by mappergen, a missing age is stored as zero.
+func intPointer(value int) *int {
+	return &value
+}
+
+func intValue(value *int) int {
+
+	if value == nil {
+		return 0
+	}
+
+	return *value
 }
@@ -0,0 +1,322 @@
+// Code generated by mappergen. DO NOT EDIT.
+
+package mapper
+
+import (
+	"person/internal/document"
+	"person/internal/dto"
+)
+
+func personToDto(person document.Person) dto.Person {
+
+	result := dto.Person{
+		Id:        person.Id,
+		Name:      person.Name,
+		Email:     person.Email,
+		Age:       intPointer(person.Age),
+		BirthDate: timeToDate(person.BirthDate),
+		Phones:    phoneListToDto(person.Phones),
+		Addresses: addressListToDto(person.Addresses),
+		Documents: identifierListToDto(person.Documents),
+		CreatedAt: person.CreatedAt,
+		UpdatedAt: person.UpdatedAt,
+		CreatedBy: person.CreatedBy,
+		UpdatedBy: person.UpdatedBy,
+		DeletedAt: person.DeletedAt,
+	}
+
+	return result
+}
+
+func personListToDto(list []document.Person) []dto.Person {
+
+	if list == nil {
+		return nil
+	}
+
+	result := make([]dto.Person, len(list))
+
+	for i, item := range list {
+		result[i] = personToDto(item)
+	}
+
+	return result
+}
+
+func personToDocument(person dto.Person) document.Person {
+
+	result := document.Person{
+		Id:        person.Id,
+		Name:      person.Name,
+		Email:     person.Email,
+		Age:       intValue(person.Age),
+		BirthDate: dateToTime(person.BirthDate),
+		Phones:    phoneListToDocument(person.Phones),
+		Addresses: addressListToDocument(person.Addresses),
+		Documents: identifierListToDocument(person.Documents),
+		CreatedAt: person.CreatedAt,
+		UpdatedAt: person.UpdatedAt,
+		CreatedBy: person.CreatedBy,
+		UpdatedBy: person.UpdatedBy,
+		DeletedAt: person.DeletedAt,
+	}
+
+	return result
+}
+
+func personListToDocument(list []dto.Person) []document.Person {
+
+	if list == nil {
+		return nil
+	}
+
+	result := make([]document.Person, len(list))
+
+	for i, item := range list {
+		result[i] = personToDocument(item)
+	}
+
+	return result
+}
+
+func phoneToDto(phone document.Phone) dto.Phone {
+
+	result := dto.Phone{
+		Type:   phone.Type,
+		Number: phone.Number,
+	}
+
+	return result
+}
+
+func phoneListToDto(list []document.Phone) []dto.Phone {
+
+	if list == nil {
+		return nil
+	}
+
+	result := make([]dto.Phone, len(list))
+
+	for i, item := range list {
+		result[i] = phoneToDto(item)
+	}
+
+	return result
+}
+
+func phoneToDocument(phone dto.Phone) document.Phone {
+
+	result := document.Phone{
+		Type:   phone.Type,
+		Number: phone.Number,
+	}
+
+	return result
+}
+
+func phoneListToDocument(list []dto.Phone) []document.Phone {
+
+	if list == nil {
+		return nil
+	}
+
+	result := make([]document.Phone, len(list))
+
+	for i, item := range list {
+		result[i] = phoneToDocument(item)
+	}
+
+	return result
+}
+
+func addressToDto(address document.Address) dto.Address {
+
+	result := dto.Address{
+		Type:       address.Type,
+		Street:     address.Street,
+		Number:     address.Number,
+		Complement: address.Complement,
+		District:   address.District,
+		City:       address.City,
+		State:      address.State,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+	}
+
+	return result
+}
+
+func addressListToDto(list []document.Address) []dto.Address {
+
+	if list == nil {
+		return nil
+	}
+
+	result := make([]dto.Address, len(list))
+
+	for i, item := range list {
+		result[i] = addressToDto(item)
+	}
+
+	return result
+}
+
+func addressToDocument(address dto.Address) document.Address {
+
+	result := document.Address{
+		Type:       address.Type,
+		Street:     address.Street,
+		Number:     address.Number,
+		Complement: address.Complement,
+		District:   address.District,
+		City:       address.City,
+		State:      address.State,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+	}
+
+	return result
+}
+
+func addressListToDocument(list []dto.Address) []document.Address {
+
+	if list == nil {
+		return nil
+	}
+
+	result := make([]document.Address, len(list))
+
+	for i, item := range list {
+		result[i] = addressToDocument(item)
+	}
+
+	return result
+}
+
+func identifierToDto(identifier document.Identifier) dto.Identifier {
+
+	result := dto.Identifier{
+		Type:   identifier.Type,
+		Number: identifier.Number,
+	}
+
+	return result
+}
+
+func identifierListToDto(list []document.Identifier) []dto.Identifier {
+
+	if list == nil {
+		return nil
+	}
+
+	result := make([]dto.Identifier, len(list))
+
+	for i, item := range list {
+		result[i] = identifierToDto(item)
+	}
+
+	return result
+}
+
+func identifierToDocument(identifier dto.Identifier) document.Identifier {
+
+	result := document.Identifier{
+		Type:   identifier.Type,
+		Number: identifier.Number,
+	}
+
+	return result
+}
+
+func identifierListToDocument(list []dto.Identifier) []document.Identifier {
+
+	if list == nil {
+		return nil
+	}
+
+	result := make([]document.Identifier, len(list))
+
+	for i, item := range list {
+		result[i] = identifierToDocument(item)
+	}
+
+	return result
+}
+
+func revisionToDto(revision document.Revision) dto.Revision {
+
+	result := dto.Revision{
+		PersonId:  revision.PersonId,
+		Version:   revision.Version,
+		Operation: revision.Operation,
+		Reverts:   revision.Reverts,
+		Actor:     revision.Actor,
+		At:        revision.At,
+	}
+
+	if revision.Before != nil {
+		before := personToDto(*revision.Before)
+		result.Before = &before
+	}
+
+	if revision.After != nil {
+		after := personToDto(*revision.After)
+		result.After = &after
+	}
+
+	return result
+}
+
+func revisionListToDto(list []document.Revision) []dto.Revision {
+
+	if list == nil {
+		return nil
+	}
+
+	result := make([]dto.Revision, len(list))
+
+	for i, item := range list {
+		result[i] = revisionToDto(item)
+	}
+
+	return result
+}
+
+func revisionToDocument(revision dto.Revision) document.Revision {
+
+	result := document.Revision{
+		PersonId:  revision.PersonId,
+		Version:   revision.Version,
+		Operation: revision.Operation,
+		Reverts:   revision.Reverts,
+		Actor:     revision.Actor,
+		At:        revision.At,
+	}
+
+	if revision.Before != nil {
+		before := personToDocument(*revision.Before)
+		result.Before = &before
+	}
+
+	if revision.After != nil {
+		after := personToDocument(*revision.After)
+		result.After = &after
+	}
+
+	return result
+}
+
+func revisionListToDocument(list []dto.Revision) []document.Revision {
+
+	if list == nil {
+		return nil
+	}
+
+	result := make([]document.Revision, len(list))
+
+	for i, item := range list {
+		result[i] = revisionToDocument(item)
+	}
+
+	return result
+}
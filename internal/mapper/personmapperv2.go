@@ -0,0 +1,118 @@
+package mapper
+
+import (
+	"person/internal/document"
+	v2 "person/internal/dto/v2"
+	"strings"
+	"time"
+)
+
+// PersonMapperV2 maps people to the v2 representation, v2.Person, from the same documents as v1.
+type PersonMapperV2 struct {
+}
+
+func (p *PersonMapperV2) NewDto() interface{} {
+	return &v2.Person{}
+}
+
+func (p *PersonMapperV2) DocumentToDto(document document.Person) interface{} {
+	return toV2(document)
+}
+
+func (p *PersonMapperV2) ListDocumentToListDto(documents []document.Person) interface{} {
+
+	people := make([]v2.Person, 0, len(documents))
+
+	for _, person := range documents {
+		people = append(people, toV2(person))
+	}
+
+	return people
+}
+
+func (p *PersonMapperV2) DtoToDocument(person interface{}) document.Person {
+
+	body := person.(*v2.Person)
+	result := document.Person{
+		Id:        body.Id,
+		Name:      strings.TrimSpace(body.Name.First + " " + body.Name.Last),
+		Email:     body.Contact.Email,
+		BirthDate: dateToTime(body.BirthDate),
+		Phones:    phoneListToDocument(body.Contact.Phones),
+		Addresses: addressListToDocument(body.Addresses),
+		Documents: identifierListToDocument(body.Documents),
+		CreatedAt: body.CreatedAt,
+		UpdatedAt: body.UpdatedAt,
+		CreatedBy: body.CreatedBy,
+		UpdatedBy: body.UpdatedBy,
+		DeletedAt: body.DeletedAt,
+	}
+
+	result.Age = result.AgeAt(time.Now())
+	return result
+}
+
+// UpdatedFields are every stored field, v2 has them all.
+func (p *PersonMapperV2) UpdatedFields(person interface{}) []string {
+	return []string{"name", "email", "age", "birthDate", "phones", "addresses", "documents"}
+}
+
+func (p *PersonMapperV2) ListRevisionToListDto(revisions []document.Revision) interface{} {
+
+	result := make([]v2.Revision, 0, len(revisions))
+
+	for _, revision := range revisions {
+		result = append(result, v2.Revision{
+			PersonId:  revision.PersonId,
+			Version:   revision.Version,
+			Operation: revision.Operation,
+			Before:    toV2Pointer(revision.Before),
+			After:     toV2Pointer(revision.After),
+			Reverts:   revision.Reverts,
+			Actor:     revision.Actor,
+			At:        revision.At,
+		})
+	}
+
+	return result
+}
+
+func toV2(person document.Person) v2.Person {
+
+	first, last := splitName(person.Name)
+	return v2.Person{
+		Id:        person.Id,
+		Name:      v2.Name{First: first, Last: last},
+		BirthDate: timeToDate(person.BirthDate),
+		Contact:   v2.Contact{Email: person.Email, Phones: phoneListToDto(person.Phones)},
+		Addresses: addressListToDto(person.Addresses),
+		Documents: identifierListToDto(person.Documents),
+		CreatedAt: person.CreatedAt,
+		UpdatedAt: person.UpdatedAt,
+		CreatedBy: person.CreatedBy,
+		UpdatedBy: person.UpdatedBy,
+		DeletedAt: person.DeletedAt,
+	}
+}
+
+func toV2Pointer(person *document.Person) *v2.Person {
+
+	if person == nil {
+		return nil
+	}
+
+	result := toV2(*person)
+	return &result
+}
+
+// splitName takes the first word as the first name and the rest, however many words, as the last name.
+func splitName(name string) (string, string) {
+
+	words := strings.Fields(name)
+
+	if len(words) == 0 {
+		return "", ""
+	}
+
+	return words[0], strings.Join(words[1:], " ")
+}
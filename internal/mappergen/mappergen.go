@@ -0,0 +1,391 @@
+package mappergen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/pkg/errors"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Config describes one generated file. The structs named in Types are converted both ways between the packages From
+// and To, given by import path, by functions written into the package in Dir.
+type Config struct {
+	From   string
+	To     string
+	Types  []string
+	Dir    string
+	Output string
+}
+
+var predeclared = map[string]bool{
+	"bool": true, "byte": true, "complex64": true, "complex128": true, "error": true, "float32": true, "float64": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true, "rune": true, "string": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+}
+
+type field struct {
+	name string
+	typ  string
+	skip bool
+}
+
+type pkg struct {
+	name    string
+	path    string
+	structs map[string][]field
+}
+
+type generator struct {
+	from       pkg
+	to         pkg
+	types      []string
+	converters map[string]string
+	out        bytes.Buffer
+}
+
+// Generate returns the formatted source of the conversions. Fields are paired by name and a field tagged mapper:"-"
+// is left out, generation fails when any other field has no counterpart or no way to be converted. Fields of
+// different types are converted by the conversions being generated or by a function of the package in Dir taking one
+// type and returning the other.
+func Generate(config Config) ([]byte, error) {
+
+	root, module, err := findModule(config.Dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	g := generator{types: config.Types, converters: map[string]string{}}
+
+	if g.from, err = parsePackage(root, module, config.From); err != nil {
+		return nil, err
+	}
+
+	if g.to, err = parsePackage(root, module, config.To); err != nil {
+		return nil, err
+	}
+
+	name, err := g.findConverters(config.Dir, config.Output)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(&g.out, "// Code generated by mappergen. DO NOT EDIT.\n\npackage %s\n\n", name)
+	fmt.Fprintf(&g.out, "import (\n%q\n%q\n)\n", g.from.path, g.to.path)
+
+	for _, typ := range config.Types {
+		if err = g.generate(typ, g.from, g.to); err != nil {
+			return nil, err
+		}
+		if err = g.generate(typ, g.to, g.from); err != nil {
+			return nil, err
+		}
+	}
+
+	source, err := format.Source(g.out.Bytes())
+	return source, errors.Wrap(err, "generated code does not compile")
+}
+
+func findModule(dir string) (string, string, error) {
+
+	dir, err := filepath.Abs(dir)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	for {
+		file, err := os.Open(filepath.Join(dir, "go.mod"))
+
+		if err == nil {
+			defer file.Close()
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				if line := strings.TrimSpace(scanner.Text()); strings.HasPrefix(line, "module ") {
+					return dir, strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module")), `"`), nil
+				}
+			}
+			return "", "", errors.Errorf("%s has no module line", file.Name())
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", errors.New("go.mod not found")
+		}
+		dir = parent
+	}
+}
+
+func parseDir(dir string, skip string) (map[string]*ast.Package, error) {
+	return parser.ParseDir(token.NewFileSet(), dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != skip
+	}, 0)
+}
+
+func parsePackage(root string, module string, path string) (pkg, error) {
+
+	result := pkg{path: path, structs: map[string][]field{}}
+
+	if path != module && !strings.HasPrefix(path, module+"/") {
+		return result, errors.Errorf("%s is not in module %s", path, module)
+	}
+
+	packages, err := parseDir(filepath.Join(root, strings.TrimPrefix(path, module)), "")
+
+	if err != nil {
+		return result, err
+	}
+
+	for name, p := range packages {
+		result.name = name
+		for _, file := range p.Files {
+			for _, decl := range file.Decls {
+				if err = result.collect(decl); err != nil {
+					return result, err
+				}
+			}
+		}
+	}
+
+	if result.name == "" {
+		return result, errors.Errorf("no package found in %s", path)
+	}
+
+	return result, nil
+}
+
+// collect keeps the fields of the structs declared, an embedded field is kept without a name.
+func (p *pkg) collect(decl ast.Decl) error {
+
+	gen, ok := decl.(*ast.GenDecl)
+
+	if !ok || gen.Tok != token.TYPE {
+		return nil
+	}
+
+	for _, spec := range gen.Specs {
+		typeSpec := spec.(*ast.TypeSpec)
+		structType, ok := typeSpec.Type.(*ast.StructType)
+
+		if !ok {
+			continue
+		}
+
+		var fields []field
+
+		for _, f := range structType.Fields.List {
+			if len(f.Names) == 0 {
+				fields = append(fields, field{typ: qualify(p.name, f.Type)})
+				continue
+			}
+
+			skip := false
+			if f.Tag != nil {
+				skip = reflect.StructTag(strings.Trim(f.Tag.Value, "`")).Get("mapper") == "-"
+			}
+
+			for _, name := range f.Names {
+				fields = append(fields, field{name: name.Name, typ: qualify(p.name, f.Type), skip: skip})
+			}
+		}
+
+		p.structs[typeSpec.Name.Name] = fields
+	}
+
+	return nil
+}
+
+// qualify writes a type as the package in Dir refers to it, so types of either side can be compared to each other and
+// to the ones of the converters.
+func qualify(name string, expr ast.Expr) string {
+
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if predeclared[t.Name] {
+			return t.Name
+		}
+		return name + "." + t.Name
+	case *ast.SelectorExpr:
+		return fmt.Sprintf("%s.%s", t.X, t.Sel.Name)
+	case *ast.StarExpr:
+		return "*" + qualify(name, t.X)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + qualify(name, t.Elt)
+		}
+	case *ast.MapType:
+		return fmt.Sprintf("map[%s]%s", qualify(name, t.Key), qualify(name, t.Value))
+	}
+
+	return fmt.Sprintf("%T", expr)
+}
+
+func (g *generator) findConverters(dir string, output string) (string, error) {
+
+	packages, err := parseDir(dir, output)
+
+	if err != nil {
+		return "", err
+	}
+
+	name := ""
+
+	for n, p := range packages {
+		name = n
+		for _, file := range p.Files {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv != nil || fn.Type.Params.NumFields() != 1 || fn.Type.Results.NumFields() != 1 {
+					continue
+				}
+				in := qualify(n, fn.Type.Params.List[0].Type)
+				out := qualify(n, fn.Type.Results.List[0].Type)
+				g.converters[in+" "+out] = fn.Name.Name
+			}
+		}
+	}
+
+	if name == "" {
+		return "", errors.Errorf("no package found in %s", dir)
+	}
+
+	return name, nil
+}
+
+func funcName(typ string, to pkg) string {
+	return strings.ToLower(typ[:1]) + typ[1:] + "To" + strings.ToUpper(to.name[:1]) + to.name[1:]
+}
+
+func listFuncName(typ string, to pkg) string {
+	return strings.ToLower(typ[:1]) + typ[1:] + "ListTo" + strings.ToUpper(to.name[:1]) + to.name[1:]
+}
+
+func (g *generator) generate(typ string, from pkg, to pkg) error {
+
+	fromFields, ok := from.structs[typ]
+
+	if !ok {
+		return errors.Errorf("%s.%s is not a struct", from.name, typ)
+	}
+
+	toFields, ok := to.structs[typ]
+
+	if !ok {
+		return errors.Errorf("%s.%s is not a struct", to.name, typ)
+	}
+
+	counterparts := map[string]field{}
+
+	for _, f := range append(fromFields, toFields...) {
+		if f.name == "" {
+			return errors.Errorf("%s embeds %s, which is not supported", typ, f.typ)
+		}
+	}
+
+	for _, f := range toFields {
+		if !f.skip {
+			counterparts[f.name] = f
+		}
+	}
+
+	param := strings.ToLower(typ[:1]) + typ[1:]
+	var literal, pointers bytes.Buffer
+
+	for _, f := range fromFields {
+		if f.skip {
+			continue
+		}
+
+		counterpart, ok := counterparts[f.name]
+
+		if !ok {
+			return errors.Errorf("%s.%s.%s has no counterpart in %s.%s, tag it mapper:\"-\" to leave it out", from.name, typ, f.name, to.name, typ)
+		}
+
+		delete(counterparts, f.name)
+		value := param + "." + f.name
+
+		if expr, ok := g.convert(f.typ, counterpart.typ, value); ok {
+			fmt.Fprintf(&literal, "%s: %s,\n", f.name, expr)
+			continue
+		}
+
+		element, ok := g.mapped(strings.TrimPrefix(f.typ, "*"), strings.TrimPrefix(counterpart.typ, "*"))
+
+		if !ok || !strings.HasPrefix(f.typ, "*") || !strings.HasPrefix(counterpart.typ, "*") {
+			return errors.Errorf("cannot convert %s.%s.%s from %s to %s", from.name, typ, f.name, f.typ, counterpart.typ)
+		}
+
+		variable := strings.ToLower(f.name[:1]) + f.name[1:]
+		fmt.Fprintf(&pointers, "\nif %s != nil {\n%s := %s(*%s)\nresult.%s = &%s\n}\n", value, variable, funcName(element, to), value, f.name, variable)
+	}
+
+	for _, f := range toFields {
+		if _, ok := counterparts[f.name]; ok {
+			return errors.Errorf("%s.%s.%s has no counterpart in %s.%s, tag it mapper:\"-\" to leave it out", to.name, typ, f.name, from.name, typ)
+		}
+	}
+
+	fmt.Fprintf(&g.out, "\nfunc %s(%s %s.%s) %s.%s {\n\n", funcName(typ, to), param, from.name, typ, to.name, typ)
+	fmt.Fprintf(&g.out, "result := %s.%s{\n%s}\n%s\nreturn result\n}\n", to.name, typ, literal.String(), pointers.String())
+
+	fmt.Fprintf(&g.out, "\nfunc %s(list []%s.%s) []%s.%s {\n\n", listFuncName(typ, to), from.name, typ, to.name, typ)
+	fmt.Fprintf(&g.out, "if list == nil {\nreturn nil\n}\n\nresult := make([]%s.%s, len(list))\n\n", to.name, typ)
+	fmt.Fprintf(&g.out, "for i, item := range list {\nresult[i] = %s(item)\n}\n\nreturn result\n}\n", funcName(typ, to))
+
+	return nil
+}
+
+// convert gives the expression turning value of type from into type to, if there is one.
+func (g *generator) convert(from string, to string, value string) (string, bool) {
+
+	if from == to {
+		return value, true
+	}
+
+	if converter, ok := g.converters[from+" "+to]; ok {
+		return fmt.Sprintf("%s(%s)", converter, value), true
+	}
+
+	if typ, ok := g.mapped(from, to); ok {
+		return fmt.Sprintf("%s(%s)", funcName(typ, g.side(to)), value), true
+	}
+
+	if strings.HasPrefix(from, "[]") && strings.HasPrefix(to, "[]") {
+		if typ, ok := g.mapped(from[2:], to[2:]); ok {
+			return fmt.Sprintf("%s(%s)", listFuncName(typ, g.side(to[2:])), value), true
+		}
+	}
+
+	return "", false
+}
+
+// mapped tells whether from and to are the same one of the types being generated, on opposite sides.
+func (g *generator) mapped(from string, to string) (string, bool) {
+
+	for _, typ := range g.types {
+		if (from == g.from.name+"."+typ && to == g.to.name+"."+typ) || (from == g.to.name+"."+typ && to == g.from.name+"."+typ) {
+			return typ, true
+		}
+	}
+
+	return "", false
+}
+
+func (g *generator) side(typ string) pkg {
+
+	if strings.HasPrefix(typ, g.to.name+".") {
+		return g.to
+	}
+
+	return g.from
+}
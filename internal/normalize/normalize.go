@@ -0,0 +1,158 @@
+package normalize
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Rule rewrites a value sent by a client before it is validated and stored.
+type Rule func(string) string
+
+// Rules are the rules that can be enabled for a field, in the properties files.
+var Rules = map[string]Rule{
+	"trim":      strings.TrimSpace,
+	"collapse":  Collapse,
+	"lowercase": strings.ToLower,
+	"nfc":       norm.NFC.String,
+	"title":     Title,
+}
+
+// particles are kept lowercase by Title, as in João da Silva and in a last name like da Silva.
+var particles = map[string]bool{
+	"da": true, "das": true, "de": true, "del": true, "do": true, "dos": true, "e": true, "la": true, "y": true,
+}
+
+// Normalizer applies the rules of each field to a dto, fields are known by their json name at any depth and the rules
+// of a field reach every string inside it, so name covers both the name of v1 and the first and last names of v2. The
+// zero value changes nothing.
+type Normalizer struct {
+	fields map[string][]Rule
+}
+
+// New fails on a rule that is not one of Rules, the rules of a field are applied in the order given.
+func New(fields map[string][]string) (Normalizer, error) {
+
+	normalizer := Normalizer{fields: map[string][]Rule{}}
+
+	for field, names := range fields {
+		for _, name := range names {
+			rule, ok := Rules[strings.ToLower(name)]
+
+			if !ok {
+				return Normalizer{}, errors.Errorf("unknown rule %q for field %s", name, field)
+			}
+
+			normalizer.fields[field] = append(normalizer.fields[field], rule)
+		}
+	}
+
+	return normalizer, nil
+}
+
+// Struct normalizes in place the struct value points to.
+func (n Normalizer) Struct(value interface{}) {
+
+	if len(n.fields) == 0 {
+		return
+	}
+
+	n.walk(reflect.ValueOf(value), nil)
+}
+
+func (n Normalizer) walk(value reflect.Value, rules []Rule) {
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !value.IsNil() {
+			n.walk(value.Elem(), rules)
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			n.walk(value.Index(i), rules)
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+
+			if field.PkgPath != "" {
+				continue
+			}
+
+			fieldRules := rules
+			if own, ok := n.fields[jsonName(field)]; ok {
+				fieldRules = append(append([]Rule{}, rules...), own...)
+			}
+
+			n.walk(value.Field(i), fieldRules)
+		}
+	case reflect.String:
+		if len(rules) > 0 && value.CanSet() {
+			value.SetString(apply(rules, value.String()))
+		}
+	}
+}
+
+func apply(rules []Rule, value string) string {
+
+	for _, rule := range rules {
+		value = rule(value)
+	}
+
+	return value
+}
+
+func jsonName(field reflect.StructField) string {
+
+	if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" {
+		return name
+	}
+
+	return field.Name
+}
+
+// Collapse turns each run of whitespace into a single space, leaving the ends to trim.
+func Collapse(value string) string {
+
+	var builder strings.Builder
+	space := false
+
+	for _, r := range value {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			builder.WriteByte(' ')
+			space = false
+		}
+		builder.WriteRune(r)
+	}
+
+	if space {
+		builder.WriteByte(' ')
+	}
+
+	return builder.String()
+}
+
+// Title capitalizes each word and lowercases the rest, particles are only capitalized when they are the whole value.
+func Title(value string) string {
+
+	caser := cases.Title(language.Und)
+	words := strings.Split(value, " ")
+
+	for i, word := range words {
+		if len(words) > 1 && particles[strings.ToLower(word)] {
+			words[i] = strings.ToLower(word)
+			continue
+		}
+		words[i] = caser.String(word)
+	}
+
+	return strings.Join(words, " ")
+}
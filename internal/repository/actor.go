@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"person/internal/document"
+)
+
+const Anonymous = "anonymous"
+
+type actorKey struct{}
+
+// WithActor records who performs the writes done with the returned context.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func ActorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return Anonymous
+}
+
+func stampCreated(ctx context.Context, person *document.Person) {
+	person.Version = 1
+	person.CreatedAt = now()
+	person.UpdatedAt = person.CreatedAt
+	person.CreatedBy = ActorFrom(ctx)
+	person.UpdatedBy = person.CreatedBy
+	person.DeletedAt = nil
+}
+
+func stampUpdated(ctx context.Context, person *document.Person) {
+	person.Version++
+	person.UpdatedAt = now()
+	person.UpdatedBy = ActorFrom(ctx)
+}
+
+// audited adds to a $set who changed the person and when, as stampUpdated does.
+func audited(ctx context.Context, set bson.M) bson.M {
+	set["updatedAt"] = now()
+	set["updatedBy"] = ActorFrom(ctx)
+	return set
+}
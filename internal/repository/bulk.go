@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/pkg/errors"
+	"person/internal/document"
+)
+
+const MaxBulkSize = 1000
+
+// ErrSkipped is the result of the operations after a failure in an ordered bulk.
+var ErrSkipped = errors.New("operation skipped after a previous failure")
+
+// BulkOperation is one of document.Created, document.Updated or document.Deleted applied to the person,
+// update and delete only read its id and version, a zero version skips the check. An update only writes the Fields
+// given, every field when there are none.
+type BulkOperation struct {
+	Operation string
+	Person    document.Person
+	Fields    []string
+}
+
+type BulkResult struct {
+	Person document.Person
+	Err    error
+}
+
+func unknownOperation(operation BulkOperation) error {
+	return errors.Errorf("unknown bulk operation %q", operation.Operation)
+}
+
+// skipRemaining marks as skipped the results not decided yet, from the given index on.
+func skipRemaining(results []BulkResult, from int) {
+	for i := from; i < len(results); i++ {
+		if results[i].Err == nil {
+			results[i].Err = ErrSkipped
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"person/internal/document"
+	"reflect"
+	"time"
+)
+
+// Changes lists the stored fields that differ between two versions of a person.
+func Changes(before document.Person, after document.Person) []string {
+
+	var fields []string
+
+	if before.Name != after.Name {
+		fields = append(fields, "name")
+	}
+
+	if before.Email != after.Email {
+		fields = append(fields, "email")
+	}
+
+	if before.Age != after.Age {
+		fields = append(fields, "age")
+	}
+
+	if !sameTime(before.BirthDate, after.BirthDate) {
+		fields = append(fields, "birthDate")
+	}
+
+	if !sameList(before.Phones, after.Phones) {
+		fields = append(fields, "phones")
+	}
+
+	if !sameList(before.Addresses, after.Addresses) {
+		fields = append(fields, "addresses")
+	}
+
+	if !sameList(before.Documents, after.Documents) {
+		fields = append(fields, "documents")
+	}
+
+	return fields
+}
+
+func sameTime(a *time.Time, b *time.Time) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && a.Equal(*b))
+}
+
+// sameList takes a nil and an empty list as the same, Mongo keeps neither of them.
+func sameList(a interface{}, b interface{}) bool {
+	return reflect.ValueOf(a).Len() == 0 && reflect.ValueOf(b).Len() == 0 || reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,9 @@
+package repository
+
+import "time"
+
+// now is truncated to milliseconds, the precision MongoDB keeps, so every repository stores the same instant.
+func now() *time.Time {
+	t := time.Now().UTC().Truncate(time.Millisecond)
+	return &t
+}
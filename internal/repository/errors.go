@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrNotFound = errors.New("person not found")
+var ErrVersionMismatch = errors.New("person version does not match")
+
+// DuplicateEmailError is returned when another person, identified by Id, already has the email.
+// Id is zero when that person could no longer be found.
+type DuplicateEmailError struct {
+	Id primitive.ObjectID
+}
+
+func (e DuplicateEmailError) Error() string {
+
+	if e.Id.IsZero() {
+		return "email already used by another person"
+	}
+
+	return fmt.Sprintf("email already used by person %s", e.Id.Hex())
+}
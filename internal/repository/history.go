@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"person/internal/document"
+)
+
+type revertKey struct{}
+
+// WithRevert marks the update done with the returned context as going back to the given revision.
+func WithRevert(ctx context.Context, version int64) context.Context {
+	return context.WithValue(ctx, revertKey{}, version)
+}
+
+func revision(ctx context.Context, operation string, before *document.Person, after document.Person) document.Revision {
+
+	revision := document.Revision{
+		PersonId:  after.Id,
+		Version:   after.Version,
+		Operation: operation,
+		Before:    before,
+		After:     &after,
+		Actor:     ActorFrom(ctx),
+		At:        after.UpdatedAt,
+	}
+
+	if reverts, ok := ctx.Value(revertKey{}).(int64); ok && operation == document.Updated {
+		revision.Operation = document.Reverted
+		revision.Reverts = reverts
+	}
+
+	return revision
+}
+
+// asOfRevision is the person a revision left behind, a deleted person did not exist from then on.
+func asOfRevision(revision document.Revision) (document.Person, error) {
+
+	if revision.After == nil || revision.After.DeletedAt != nil {
+		return document.Person{}, ErrNotFound
+	}
+
+	return *revision.After, nil
+}
+
+// applied runs on the previous person the same update Mongo did, every update increments the version.
+func applied(before document.Person, update bson.M) (document.Person, error) {
+
+	var after document.Person
+	values := bson.M{}
+
+	raw, err := bson.Marshal(before)
+
+	if err != nil {
+		return after, err
+	}
+
+	if err = bson.Unmarshal(raw, &values); err != nil {
+		return after, err
+	}
+
+	set, _ := update["$set"].(bson.M)
+	for key, value := range set {
+		values[key] = value
+	}
+
+	unset, _ := update["$unset"].(bson.M)
+	for key := range unset {
+		delete(values, key)
+	}
+
+	values["version"] = before.Version + 1
+
+	if raw, err = bson.Marshal(values); err != nil {
+		return after, err
+	}
+
+	err = bson.Unmarshal(raw, &after)
+	return after, err
+}
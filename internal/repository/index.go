@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"person/internal/document"
+)
+
+const duplicateKeyCode = 11000
+const indexOptionsConflictCode = 85
+
+const emailIndexName = "email_unique"
+
+// emailCollation compares emails ignoring case, as strength 2 only considers base letters and accents.
+var emailCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// searchIndex backs Search, without a language so names are neither stemmed nor dropped as stop words. Text indexes
+// ignore case and accents.
+var searchIndex = mongo.IndexModel{
+	Keys: bson.D{{Key: "name", Value: "text"}, {Key: "email", Value: "text"}},
+	Options: options.Index().SetName("name_email_text").SetDefaultLanguage("none").
+		SetWeights(bson.D{{Key: "name", Value: nameWeight}, {Key: "email", Value: emailWeight}}),
+}
+
+// gramsIndex backs the fuzzy Search, which compares only the people sharing a gram with the text.
+var gramsIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "grams", Value: 1}},
+	Options: options.Index().SetName("grams"),
+}
+
+// emailIndex leaves deleted people out, so their email can be used again before they are purged.
+var emailIndex = mongo.IndexModel{
+	Keys: bson.D{{Key: "email", Value: 1}},
+	Options: options.Index().SetName(emailIndexName).SetUnique(true).SetCollation(emailCollation).
+		SetPartialFilterExpression(bson.M{"deletedAt": nil}),
+}
+
+// EnsureIndexes replaces an email index created before it left deleted people out.
+func EnsureIndexes(ctx context.Context, collection *mongo.Collection) error {
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{emailIndex, searchIndex, gramsIndex})
+
+	if e, ok := err.(mongo.CommandError); ok && e.Code == indexOptionsConflictCode {
+		if _, err = collection.Indexes().DropOne(ctx, emailIndexName); err != nil {
+			return err
+		}
+		_, err = collection.Indexes().CreateMany(ctx, []mongo.IndexModel{emailIndex, searchIndex, gramsIndex})
+	}
+
+	return err
+}
+
+// EnsureSearchGrams keeps the grams of the people saved before a fuzzy search used them.
+func EnsureSearchGrams(ctx context.Context, collection *mongo.Collection) error {
+
+	opts := options.Find().SetProjection(bson.M{"name": 1, "email": 1})
+	cur, err := collection.Find(ctx, bson.M{"grams": bson.M{"$exists": false}}, opts)
+
+	if err != nil {
+		return err
+	}
+
+	defer cur.Close(ctx)
+
+	var models []mongo.WriteModel
+
+	for cur.Next(ctx) {
+		var person document.Person
+		if err = cur.Decode(&person); err != nil {
+			return err
+		}
+		update := bson.M{"$set": bson.M{"grams": personGrams(person)}}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": person.Id}).SetUpdate(update))
+
+		if len(models) == MaxBulkSize {
+			if _, err = collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+				return err
+			}
+			models = nil
+		}
+	}
+
+	if err = cur.Err(); err != nil || len(models) == 0 {
+		return err
+	}
+
+	_, err = collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func EnsureRevisionIndexes(ctx context.Context, revisions *mongo.Collection) error {
+	_, err := revisions.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "personId", Value: 1}, {Key: "version", Value: -1}},
+		Options: options.Index().SetName("person_version"),
+	})
+	return err
+}
+
+func isDuplicateKey(err error) bool {
+
+	switch e := err.(type) {
+	case mongo.WriteException:
+		for _, writeError := range e.WriteErrors {
+			if writeError.Code == duplicateKeyCode {
+				return true
+			}
+		}
+	case mongo.CommandError:
+		return e.Code == duplicateKeyCode
+	}
+
+	return false
+}
@@ -0,0 +1,518 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"math"
+	"person/internal/document"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type MemoryRepository struct {
+	mutex     *sync.RWMutex
+	people    map[primitive.ObjectID]document.Person
+	revisions map[primitive.ObjectID][]document.Revision
+}
+
+func NewMemoryRepository() MemoryRepository {
+	return MemoryRepository{
+		mutex:     &sync.RWMutex{},
+		people:    map[primitive.ObjectID]document.Person{},
+		revisions: map[primitive.ObjectID][]document.Revision{},
+	}
+}
+
+func (m MemoryRepository) Find(ctx context.Context, query Query) ([]document.Person, int64, error) {
+
+	var people []document.Person
+
+	if err := ctx.Err(); err != nil {
+		return people, 0, err
+	}
+
+	m.mutex.RLock()
+	var matched []document.Person
+	for _, person := range m.people {
+		if matches(query, person) {
+			matched = append(matched, person)
+		}
+	}
+	m.mutex.RUnlock()
+
+	total := int64(len(matched))
+
+	sort.Slice(matched, func(i, j int) bool {
+		return less(query.Sort, matched[i], matched[j])
+	})
+
+	if query.After != nil {
+		cursor := document.Person{Id: query.After.Id, Name: query.After.Name}
+		start := sort.Search(len(matched), func(i int) bool {
+			return less(query.Sort, cursor, matched[i])
+		})
+		matched = matched[start:]
+	}
+
+	for i := query.Skip(); i < int64(len(matched)) && int64(len(people)) < query.Size; i++ {
+		people = append(people, matched[i])
+	}
+
+	return people, total, nil
+}
+
+func (m MemoryRepository) Stream(ctx context.Context, query Query, fn func(document.Person) error) error {
+
+	query.Page = 1
+	query.Size = math.MaxInt64
+	people, _, err := m.Find(ctx, query)
+
+	if err != nil {
+		return err
+	}
+
+	for _, person := range people {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if err = fn(person); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m MemoryRepository) Search(ctx context.Context, query Query) ([]document.Person, int64, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	score := scorer(query)
+
+	m.mutex.RLock()
+	var found []scored
+	for _, person := range m.people {
+		if person.DeletedAt != nil {
+			continue
+		}
+		if s := score(person); s > 0 {
+			found = append(found, scored{person: person, score: s})
+		}
+	}
+	m.mutex.RUnlock()
+
+	people, total := rank(query, found)
+	return people, total, nil
+}
+
+func (m MemoryRepository) FindById(ctx context.Context, id string) (document.Person, error) {
+
+	if err := ctx.Err(); err != nil {
+		return document.Person{}, err
+	}
+
+	objectID, _ := primitive.ObjectIDFromHex(id)
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	person, ok := m.people[objectID]
+
+	if !ok || person.DeletedAt != nil {
+		return document.Person{}, ErrNotFound
+	}
+
+	return person, nil
+}
+
+func (m MemoryRepository) Create(ctx context.Context, person document.Person) (document.Person, error) {
+
+	if err := ctx.Err(); err != nil {
+		return person, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err := m.duplicate(primitive.NilObjectID, person.Email); err != nil {
+		return person, err
+	}
+
+	person.Id = primitive.NewObjectID()
+	stampCreated(ctx, &person)
+	m.people[person.Id] = person
+	m.record(revision(ctx, document.Created, nil, person))
+
+	return person, nil
+}
+
+// storedFields are the fields an update writes, the ones fieldValues gives in Mongo.
+var storedFields = []string{"name", "email", "age", "birthDate", "phones", "addresses", "documents"}
+
+func (m MemoryRepository) Update(ctx context.Context, document document.Person) (document.Person, error) {
+	return m.Patch(ctx, document, storedFields)
+}
+
+func (m MemoryRepository) Patch(ctx context.Context, changed document.Person, fields []string) (document.Person, error) {
+
+	if err := ctx.Err(); err != nil {
+		return changed, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	person, err := m.current(changed.Id, changed.Version)
+
+	if err != nil {
+		return person, err
+	}
+
+	before := person
+
+	if contains(fields, "email") {
+		if err := m.duplicate(person.Id, changed.Email); err != nil {
+			return changed, err
+		}
+	}
+
+	for _, field := range fields {
+		switch field {
+		case "name":
+			person.Name = changed.Name
+		case "email":
+			person.Email = changed.Email
+		case "age":
+			person.Age = changed.Age
+		case "birthDate":
+			person.BirthDate = changed.BirthDate
+		case "phones":
+			person.Phones = changed.Phones
+		case "addresses":
+			person.Addresses = changed.Addresses
+		case "documents":
+			person.Documents = changed.Documents
+		}
+	}
+	stampUpdated(ctx, &person)
+	m.people[changed.Id] = person
+	m.record(revision(ctx, document.Updated, &before, person))
+
+	return person, nil
+}
+
+func (m MemoryRepository) Delete(ctx context.Context, id primitive.ObjectID, version int64) (int64, error) {
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	person, err := m.current(id, version)
+
+	if err == ErrNotFound && version == 0 {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	before := person
+	person.DeletedAt = now()
+	stampUpdated(ctx, &person)
+	m.people[id] = person
+	m.record(revision(ctx, document.Deleted, &before, person))
+
+	return 1, nil
+}
+
+func (m MemoryRepository) Restore(ctx context.Context, id primitive.ObjectID) (document.Person, error) {
+
+	if err := ctx.Err(); err != nil {
+		return document.Person{}, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	person, ok := m.people[id]
+
+	if !ok || person.DeletedAt == nil {
+		return document.Person{}, ErrNotFound
+	}
+
+	if err := m.duplicate(id, person.Email); err != nil {
+		return document.Person{}, err
+	}
+
+	before := person
+	person.DeletedAt = nil
+	stampUpdated(ctx, &person)
+	m.people[id] = person
+	m.record(revision(ctx, document.Restored, &before, person))
+
+	return person, nil
+}
+
+func (m MemoryRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var count int64
+
+	for id, person := range m.people {
+		if person.DeletedAt != nil && person.DeletedAt.Before(deletedBefore) {
+			delete(m.people, id)
+			delete(m.revisions, id)
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (m MemoryRepository) Bulk(ctx context.Context, operations []BulkOperation, ordered bool) ([]BulkResult, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkResult, len(operations))
+
+	for i, operation := range operations {
+		results[i] = m.bulkOne(ctx, operation)
+
+		if results[i].Err != nil && ordered {
+			skipRemaining(results, i+1)
+			break
+		}
+	}
+
+	return results, nil
+}
+
+func (m MemoryRepository) bulkOne(ctx context.Context, operation BulkOperation) BulkResult {
+
+	switch operation.Operation {
+	case document.Created:
+		person, err := m.Create(ctx, operation.Person)
+		return BulkResult{Person: person, Err: err}
+	case document.Updated:
+		fields := operation.Fields
+		if len(fields) == 0 {
+			fields = storedFields
+		}
+		person, err := m.Patch(ctx, operation.Person, fields)
+		return BulkResult{Person: person, Err: err}
+	case document.Deleted:
+		count, err := m.Delete(ctx, operation.Person.Id, operation.Person.Version)
+		if err == nil && count == 0 {
+			err = ErrNotFound
+		}
+		return BulkResult{Person: operation.Person, Err: err}
+	}
+
+	return BulkResult{Person: operation.Person, Err: unknownOperation(operation)}
+}
+
+func (m MemoryRepository) History(ctx context.Context, id primitive.ObjectID, query Query) ([]document.Revision, int64, error) {
+
+	var revisions []document.Revision
+
+	if err := ctx.Err(); err != nil {
+		return revisions, 0, err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	recorded := m.revisions[id]
+	total := int64(len(recorded))
+
+	for i := total - 1 - query.Skip(); i >= 0 && int64(len(revisions)) < query.Size; i-- {
+		revisions = append(revisions, recorded[i])
+	}
+
+	return revisions, total, nil
+}
+
+func (m MemoryRepository) FindAsOf(ctx context.Context, id primitive.ObjectID, asOf time.Time) (document.Person, error) {
+
+	if err := ctx.Err(); err != nil {
+		return document.Person{}, err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	recorded := m.revisions[id]
+
+	for i := len(recorded) - 1; i >= 0; i-- {
+		if !recorded[i].At.After(asOf) {
+			return asOfRevision(recorded[i])
+		}
+	}
+
+	return document.Person{}, ErrNotFound
+}
+
+func (m MemoryRepository) FindRevision(ctx context.Context, id primitive.ObjectID, version int64) (document.Revision, error) {
+
+	if err := ctx.Err(); err != nil {
+		return document.Revision{}, err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, revision := range m.revisions[id] {
+		if revision.Version == version {
+			return revision, nil
+		}
+	}
+
+	return document.Revision{}, ErrNotFound
+}
+
+// record must be called holding the lock, revisions are kept oldest first.
+func (m MemoryRepository) record(revision document.Revision) {
+	revision.Id = primitive.NewObjectID()
+	m.revisions[revision.PersonId] = append(m.revisions[revision.PersonId], revision)
+}
+
+// duplicate must be called holding the lock, it enforces the unique email index of EnsureIndexes, which leaves deleted
+// people out.
+func (m MemoryRepository) duplicate(id primitive.ObjectID, email string) error {
+
+	for _, person := range m.people {
+		if person.Id != id && person.DeletedAt == nil && strings.EqualFold(person.Email, email) {
+			return DuplicateEmailError{Id: person.Id}
+		}
+	}
+
+	return nil
+}
+
+// current must be called holding the lock, it checks the version the same way versionFilter does.
+func (m MemoryRepository) current(id primitive.ObjectID, version int64) (document.Person, error) {
+
+	person, ok := m.people[id]
+
+	if !ok || person.DeletedAt != nil {
+		return document.Person{}, ErrNotFound
+	}
+
+	if version != 0 && person.Version != version {
+		return document.Person{}, ErrVersionMismatch
+	}
+
+	return person, nil
+}
+
+func contains(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(query Query, person document.Person) bool {
+
+	if !query.IncludeDeleted && person.DeletedAt != nil {
+		return false
+	}
+
+	if query.Name != "" && !strings.Contains(strings.ToLower(person.Name), strings.ToLower(query.Name)) {
+		return false
+	}
+
+	if query.Email != "" && !strings.EqualFold(person.Email, query.Email) {
+		return false
+	}
+
+	if query.UpdatedSince != nil && (person.UpdatedAt == nil || person.UpdatedAt.Before(*query.UpdatedSince)) {
+		return false
+	}
+
+	if query.MinAge != nil && person.AgeAt(*now()) < *query.MinAge {
+		return false
+	}
+
+	if query.MaxAge != nil && person.AgeAt(*now()) > *query.MaxAge {
+		return false
+	}
+
+	return true
+}
+
+// less orders people the same way buildSort does in Mongo, by the query sort and then by id.
+func less(by []Sort, a document.Person, b document.Person) bool {
+
+	for _, s := range by {
+		c := compare(s.Field, a, b)
+		if s.Descending {
+			c = -c
+		}
+		if c != 0 {
+			return c < 0
+		}
+	}
+
+	return bytes.Compare(a.Id[:], b.Id[:]) < 0
+}
+
+func compare(field string, a document.Person, b document.Person) int {
+
+	switch field {
+	case "id":
+		return bytes.Compare(a.Id[:], b.Id[:])
+	case "name":
+		return strings.Compare(a.Name, b.Name)
+	case "email":
+		return strings.Compare(a.Email, b.Email)
+	case "age":
+		today := *now()
+		return compareTimes(birthOrder(b, today), birthOrder(a, today))
+	}
+
+	return 0
+}
+
+// birthOrder is the birth date of a person, or the one the stored age gives today for who has none, as ageOrder does in
+// Mongo. Sorting by it follows the birth dates, which keep the age current, instead of the ages stored.
+func birthOrder(person document.Person, today time.Time) time.Time {
+
+	if person.BirthDate != nil {
+		return *person.BirthDate
+	}
+
+	today = today.UTC()
+	return time.Date(today.Year()-person.Age, today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func compareTimes(a time.Time, b time.Time) int {
+
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+
+	return 0
+}
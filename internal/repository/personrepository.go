@@ -2,94 +2,993 @@ package repository
 
 import (
 	"context"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"person/internal/document"
+	"regexp"
+	"strings"
+	"time"
 )
 
+// storedPerson is a person as kept in the collection, with the grams a fuzzy search finds it by.
+type storedPerson struct {
+	document.Person `bson:",inline"`
+	Grams           []string `bson:"grams"`
+}
+
+func stored(person document.Person) storedPerson {
+	return storedPerson{Person: person, Grams: personGrams(person)}
+}
+
 type PersonRepository struct {
 	Collection *mongo.Collection
+	Revisions  *mongo.Collection
+	Timeout    Timeout
 }
 
-func (p PersonRepository) Find() ([]document.Person, error) {
+func (p PersonRepository) Find(ctx context.Context, query Query) ([]document.Person, int64, error) {
 
 	var people []document.Person
-	ctx := context.TODO()
+	ctx, cancel := withTimeout(ctx, p.Timeout.Find)
+	defer cancel()
+	filter := buildFilter(query)
 
-	cur, err := p.Collection.Find(ctx, bson.D{})
+	// total counts every person matching the query, also the ones before the cursor.
+	uncursored := query
+	uncursored.After = nil
+	total, err := p.Collection.CountDocuments(ctx, buildFilter(uncursored))
 
-	if err == nil {
-		for cur.Next(ctx) {
-			var result document.Person
-			err = cur.Decode(&result)
-			if err != nil {
-				log.Error(err)
-				break
-			}
-			people = append(people, result)
+	if err != nil {
+		return people, 0, contextError(ctx, err)
+	}
+
+	cur, err := p.find(ctx, filter, query.Sort, query.Skip(), query.Size)
+
+	if err != nil {
+		return people, total, contextError(ctx, err)
+	}
+
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var result document.Person
+		err = cur.Decode(&result)
+		if err != nil {
+			log.Error(err)
+			return people, total, err
+		}
+		people = append(people, result)
+	}
+
+	return people, total, contextError(ctx, cur.Err())
+}
+
+// Stream calls fn with every person matching the query, in its order, ignoring page and size.
+// People are decoded one at a time from the cursor, an error from fn stops the stream and is returned.
+func (p PersonRepository) Stream(ctx context.Context, query Query, fn func(document.Person) error) error {
+
+	ctx, cancel := withTimeout(ctx, p.Timeout.Stream)
+	defer cancel()
+
+	cur, err := p.find(ctx, buildFilter(query), query.Sort, 0, 0)
+
+	if err != nil {
+		return contextError(ctx, err)
+	}
+
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var person document.Person
+		if err = cur.Decode(&person); err != nil {
+			return err
+		}
+		if err = fn(person); err != nil {
+			return err
+		}
+	}
+
+	return contextError(ctx, cur.Err())
+}
+
+// find reads the people of the filter in the order asked, through an aggregation when sorted by age since its order is
+// computed. A zero limit reads them all.
+func (p PersonRepository) find(ctx context.Context, filter bson.M, sort []Sort, skip int64, limit int64) (*mongo.Cursor, error) {
+
+	if !sortedByAge(sort) {
+		opts := options.Find().SetSort(buildSort(sort))
+		if limit > 0 {
+			opts.SetSkip(skip).SetLimit(limit)
+		}
+		return p.Collection.Find(ctx, filter, opts)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$addFields", Value: bson.M{ageOrderField: ageOrder(*now())}}},
+		{{Key: "$sort", Value: buildSort(sort)}},
+	}
+
+	if skip > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$skip", Value: skip}})
+	}
+
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+
+	return p.Collection.Aggregate(ctx, pipeline)
+}
+
+// Search finds people by the terms of the text index, or compares the people sharing a gram with the text when the
+// query is fuzzy, since the similarity of misspelled names cannot be indexed.
+func (p PersonRepository) Search(ctx context.Context, query Query) ([]document.Person, int64, error) {
+
+	ctx, cancel := withTimeout(ctx, p.Timeout.Search)
+	defer cancel()
+
+	if query.Fuzzy {
+		return p.fuzzySearch(ctx, query)
+	}
+
+	var people []document.Person
+	searched := searchTerms(query.Text)
+
+	if len(searched) == 0 {
+		return people, 0, nil
+	}
+
+	filter := bson.M{"$text": bson.M{"$search": strings.Join(searched, " ")}, "deletedAt": nil}
+
+	total, err := p.Collection.CountDocuments(ctx, filter)
+
+	if err != nil {
+		return people, 0, contextError(ctx, err)
+	}
+
+	relevance := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": relevance}).
+		SetSkip(query.Skip()).
+		SetLimit(query.Size).
+		SetSort(bson.D{{Key: "score", Value: relevance}, {Key: "_id", Value: 1}})
+
+	cur, err := p.Collection.Find(ctx, filter, opts)
+
+	if err != nil {
+		return people, total, contextError(ctx, err)
+	}
+
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var person document.Person
+		if err = cur.Decode(&person); err != nil {
+			return people, total, err
+		}
+		people = append(people, person)
+	}
+
+	return people, total, contextError(ctx, cur.Err())
+}
+
+// fuzzySearch scores the name and email of the people the grams index finds, and only reads the whole people of the
+// page asked.
+func (p PersonRepository) fuzzySearch(ctx context.Context, query Query) ([]document.Person, int64, error) {
+
+	searched := searchTerms(query.Text)
+
+	if len(searched) == 0 {
+		return nil, 0, nil
+	}
+
+	score := scorer(query)
+	var found []scored
+
+	filter := bson.M{"grams": bson.M{"$in": grams(searched)}, "deletedAt": nil}
+	opts := options.Find().SetProjection(bson.M{"name": 1, "email": 1})
+
+	cur, err := p.Collection.Find(ctx, filter, opts)
+
+	if err != nil {
+		return nil, 0, contextError(ctx, err)
+	}
+
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var person document.Person
+		if err = cur.Decode(&person); err != nil {
+			return nil, 0, err
 		}
+		if s := score(person); s > 0 {
+			found = append(found, scored{person: person, score: s})
+		}
+	}
+
+	if err = contextError(ctx, cur.Err()); err != nil {
+		return nil, 0, err
 	}
 
-	return people, err
+	ranked, total := rank(query, found)
+
+	if len(ranked) == 0 {
+		return nil, total, nil
+	}
+
+	people, err := p.findByIds(ctx, ranked)
+	return people, total, err
 }
 
-func (p PersonRepository) FindById(id string) (document.Person, error) {
+// findByIds reads the whole people in the order they are given, leaving out the ones deleted meanwhile.
+func (p PersonRepository) findByIds(ctx context.Context, partial []document.Person) ([]document.Person, error) {
+
+	ids := make([]primitive.ObjectID, len(partial))
+	for i, person := range partial {
+		ids[i] = person.Id
+	}
+
+	cur, err := p.Collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}, "deletedAt": nil})
+
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	defer cur.Close(ctx)
+
+	byId := map[primitive.ObjectID]document.Person{}
+
+	for cur.Next(ctx) {
+		var person document.Person
+		if err = cur.Decode(&person); err != nil {
+			return nil, err
+		}
+		byId[person.Id] = person
+	}
+
+	if err = contextError(ctx, cur.Err()); err != nil {
+		return nil, err
+	}
+
+	var people []document.Person
+
+	for _, id := range ids {
+		if person, ok := byId[id]; ok {
+			people = append(people, person)
+		}
+	}
+
+	return people, nil
+}
+
+func (p PersonRepository) FindById(ctx context.Context, id string) (document.Person, error) {
 
 	var person document.Person
-	ctx := context.TODO()
+	ctx, cancel := withTimeout(ctx, p.Timeout.FindById)
+	defer cancel()
 
 	objectID, _ := primitive.ObjectIDFromHex(id)
-	result := p.Collection.FindOne(ctx, bson.M{"_id": objectID})
+	result := p.Collection.FindOne(ctx, bson.M{"_id": objectID, "deletedAt": nil})
 	err := result.Decode(&person)
 
-	return person, err
+	if err == mongo.ErrNoDocuments {
+		return person, ErrNotFound
+	}
+
+	return person, contextError(ctx, err)
 }
 
-func (p PersonRepository) Create(document document.Person) (document.Person, error) {
+func (p PersonRepository) Create(ctx context.Context, person document.Person) (document.Person, error) {
 
-	ctx := context.TODO()
+	ctx, cancel := withTimeout(ctx, p.Timeout.Create)
+	defer cancel()
 
-	document.Id = primitive.NewObjectID()
-	_, err := p.Collection.InsertOne(ctx, document)
+	person.Id = primitive.NewObjectID()
+	stampCreated(ctx, &person)
+	_, err := p.Collection.InsertOne(ctx, stored(person))
+
+	if isDuplicateKey(err) {
+		return person, p.duplicate(ctx, person.Email)
+	}
 
 	if err != nil {
-		return document, err
+		return person, contextError(ctx, err)
 	}
 
-	return document, err
+	return person, p.record(ctx, revision(ctx, document.Created, nil, person))
 }
 
-func (p PersonRepository) Update(document document.Person) (int64, error) {
+func (p PersonRepository) Update(ctx context.Context, person document.Person) (document.Person, error) {
 
-	ctx := context.TODO()
-	filter := bson.M{"_id": document.Id}
-	update := bson.M{"$set": bson.M{
-		"name":  document.Name,
-		"email": document.Email,
-		"age":   document.Age,
-	}}
+	ctx, cancel := withTimeout(ctx, p.Timeout.Update)
+	defer cancel()
+
+	update := bson.M{
+		"$set": audited(ctx, fieldValues(person)),
+		"$inc": bson.M{"version": 1},
+	}
+
+	return p.findOneAndUpdate(ctx, person.Id, person.Version, update, document.Updated)
+}
+
+func (p PersonRepository) Patch(ctx context.Context, person document.Person, fields []string) (document.Person, error) {
+
+	ctx, cancel := withTimeout(ctx, p.Timeout.Update)
+	defer cancel()
 
-	result, err := p.Collection.UpdateOne(ctx, filter, update)
+	update := bson.M{
+		"$set": audited(ctx, fieldSet(person, fields)),
+		"$inc": bson.M{"version": 1},
+	}
+
+	return p.findOneAndUpdate(ctx, person.Id, person.Version, update, document.Updated)
+}
+
+func (p PersonRepository) Delete(ctx context.Context, id primitive.ObjectID, version int64) (int64, error) {
+
+	ctx, cancel := withTimeout(ctx, p.Timeout.Delete)
+	defer cancel()
+	update := bson.M{
+		"$set": audited(ctx, bson.M{"deletedAt": now()}),
+		"$inc": bson.M{"version": 1},
+	}
+
+	_, err := p.findOneAndUpdate(ctx, id, version, update, document.Deleted)
+
+	if err == ErrNotFound && version == 0 {
+		return 0, nil
+	}
 
 	if err != nil {
 		return 0, err
 	}
 
-	return result.MatchedCount, err
+	return 1, nil
+}
+
+func (p PersonRepository) Restore(ctx context.Context, id primitive.ObjectID) (document.Person, error) {
+
+	ctx, cancel := withTimeout(ctx, p.Timeout.Update)
+	defer cancel()
+	filter := bson.M{"_id": id, "deletedAt": bson.M{"$ne": nil}}
+
+	var deleted document.Person
+	err := p.Collection.FindOne(ctx, filter).Decode(&deleted)
+
+	if err == mongo.ErrNoDocuments {
+		return deleted, ErrNotFound
+	}
+
+	if err != nil {
+		return deleted, contextError(ctx, err)
+	}
+
+	owner, err := p.owner(ctx, deleted.Email)
+
+	if err == nil {
+		return deleted, DuplicateEmailError{Id: owner.Id}
+	}
+
+	if err != mongo.ErrNoDocuments {
+		return deleted, contextError(ctx, err)
+	}
+
+	// email is set again so that a person taking it meanwhile is reported by modify as the duplicate.
+	update := bson.M{
+		"$set":   audited(ctx, bson.M{"email": deleted.Email}),
+		"$unset": bson.M{"deletedAt": ""},
+		"$inc":   bson.M{"version": 1},
+	}
+
+	person, err := p.modify(ctx, filter, update, document.Restored)
+
+	if err == mongo.ErrNoDocuments {
+		return person, ErrNotFound
+	}
+
+	return person, err
 }
 
-func (p PersonRepository) Delete(id primitive.ObjectID) (int64, error) {
+func (p PersonRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
 
-	ctx := context.TODO()
-	filter := bson.M{"_id": id}
+	ctx, cancel := withTimeout(ctx, p.Timeout.Delete)
+	defer cancel()
 
-	result, err := p.Collection.DeleteOne(ctx, filter)
+	filter := bson.M{"deletedAt": bson.M{"$lt": deletedBefore}}
+	cur, err := p.Collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
 
 	if err != nil {
+		return 0, contextError(ctx, err)
+	}
+
+	defer cur.Close(ctx)
+
+	var ids []primitive.ObjectID
+
+	for cur.Next(ctx) {
+		var person document.Person
+		if err = cur.Decode(&person); err != nil {
+			return 0, err
+		}
+		ids = append(ids, person.Id)
+	}
+
+	if err = contextError(ctx, cur.Err()); err != nil || len(ids) == 0 {
 		return 0, err
 	}
 
+	// the revisions go first, so a failure leaves the people to be purged again with what is left of them
+	if _, err = p.Revisions.DeleteMany(ctx, bson.M{"personId": bson.M{"$in": ids}}); err != nil {
+		return 0, contextError(ctx, err)
+	}
+
+	filter["_id"] = bson.M{"$in": ids}
+	result, err := p.Collection.DeleteMany(ctx, filter)
+
+	if err != nil {
+		return 0, contextError(ctx, err)
+	}
+
 	return result.DeletedCount, err
 }
+
+// Bulk reads the people it changes before a single BulkWrite, the writes are pinned to the versions read
+// so a concurrent change makes them match nothing and fail as a version mismatch.
+func (p PersonRepository) Bulk(ctx context.Context, operations []BulkOperation, ordered bool) ([]BulkResult, error) {
+
+	ctx, cancel := withTimeout(ctx, p.Timeout.Bulk)
+	defer cancel()
+
+	results := make([]BulkResult, len(operations))
+	current, err := p.current(ctx, operations)
+
+	if err != nil {
+		return results, err
+	}
+
+	var models []mongo.WriteModel
+	var planned []int
+	var revisions []document.Revision
+	positions := map[mongo.WriteModel]int{}
+
+	for i, operation := range operations {
+		model, revision, err := plan(ctx, operation, current)
+		results[i] = BulkResult{Person: operation.Person, Err: err}
+
+		if err != nil && ordered {
+			skipRemaining(results, i+1)
+			break
+		}
+
+		if err == nil {
+			results[i].Person = *revision.After
+			models = append(models, model)
+			planned = append(planned, i)
+			positions[model] = i
+			revisions = append(revisions, revision)
+		}
+	}
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	result, err := p.Collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(ordered))
+	exception, ok := err.(mongo.BulkWriteException)
+
+	if err != nil && (!ok || exception.WriteConcernError != nil) {
+		return results, contextError(ctx, err)
+	}
+
+	// an unordered bulk groups the writes by kind, so the index of a write error is not the one of its model
+	for _, writeError := range exception.WriteErrors {
+		i := positions[writeError.Request]
+		results[i].Err = errors.New(writeError.Message)
+
+		if writeError.Code == duplicateKeyCode {
+			results[i].Err = p.duplicate(ctx, operations[i].Person.Email)
+		}
+
+		if ordered {
+			skipRemaining(results, i+1)
+		}
+	}
+
+	var matched int64
+	expected := map[primitive.ObjectID]int64{}
+
+	for j, i := range planned {
+		if results[i].Err == nil && operations[i].Operation != document.Created {
+			matched++
+			expected[revisions[j].PersonId] = revisions[j].Version
+		}
+	}
+
+	if result != nil && result.MatchedCount < matched {
+		if err = p.raced(ctx, expected, planned, revisions, results); err != nil {
+			return results, err
+		}
+	}
+
+	var written []interface{}
+
+	for j, i := range planned {
+		if results[i].Err == nil {
+			written = append(written, revisions[j])
+		}
+	}
+
+	if len(written) == 0 {
+		return results, nil
+	}
+
+	_, err = p.Revisions.InsertMany(ctx, written)
+	return results, contextError(ctx, err)
+}
+
+// current reads the people that the updates and deletes of a bulk will change.
+func (p PersonRepository) current(ctx context.Context, operations []BulkOperation) (map[primitive.ObjectID]document.Person, error) {
+
+	people := map[primitive.ObjectID]document.Person{}
+	var ids []primitive.ObjectID
+
+	for _, operation := range operations {
+		if operation.Operation != document.Created {
+			ids = append(ids, operation.Person.Id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return people, nil
+	}
+
+	cur, err := p.Collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}, "deletedAt": nil})
+
+	if err != nil {
+		return people, contextError(ctx, err)
+	}
+
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var person document.Person
+		if err = cur.Decode(&person); err != nil {
+			return people, err
+		}
+		people[person.Id] = person
+	}
+
+	return people, contextError(ctx, cur.Err())
+}
+
+// raced fails with a version mismatch the writes on people that do not have the version the bulk left them with.
+func (p PersonRepository) raced(ctx context.Context, expected map[primitive.ObjectID]int64, planned []int, revisions []document.Revision, results []BulkResult) error {
+
+	var ids []primitive.ObjectID
+
+	for id := range expected {
+		ids = append(ids, id)
+	}
+
+	cur, err := p.Collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+
+	if err != nil {
+		return contextError(ctx, err)
+	}
+
+	defer cur.Close(ctx)
+
+	versions := map[primitive.ObjectID]int64{}
+
+	for cur.Next(ctx) {
+		var person document.Person
+		if err = cur.Decode(&person); err != nil {
+			return err
+		}
+		versions[person.Id] = person.Version
+	}
+
+	if err = cur.Err(); err != nil {
+		return contextError(ctx, err)
+	}
+
+	for j, i := range planned {
+		id := revisions[j].PersonId
+		if version, ok := expected[id]; ok && results[i].Err == nil && versions[id] != version {
+			results[i].Err = ErrVersionMismatch
+		}
+	}
+
+	return nil
+}
+
+func (p PersonRepository) History(ctx context.Context, id primitive.ObjectID, query Query) ([]document.Revision, int64, error) {
+
+	var revisions []document.Revision
+	ctx, cancel := withTimeout(ctx, p.Timeout.Find)
+	defer cancel()
+	filter := bson.M{"personId": id}
+
+	total, err := p.Revisions.CountDocuments(ctx, filter)
+
+	if err != nil {
+		return revisions, 0, contextError(ctx, err)
+	}
+
+	opts := options.Find().
+		SetSkip(query.Skip()).
+		SetLimit(query.Size).
+		SetSort(bson.D{{Key: "version", Value: -1}})
+
+	cur, err := p.Revisions.Find(ctx, filter, opts)
+
+	if err != nil {
+		return revisions, total, contextError(ctx, err)
+	}
+
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var result document.Revision
+		if err = cur.Decode(&result); err != nil {
+			return revisions, total, err
+		}
+		revisions = append(revisions, result)
+	}
+
+	return revisions, total, contextError(ctx, cur.Err())
+}
+
+func (p PersonRepository) FindAsOf(ctx context.Context, id primitive.ObjectID, asOf time.Time) (document.Person, error) {
+
+	var revision document.Revision
+	ctx, cancel := withTimeout(ctx, p.Timeout.FindById)
+	defer cancel()
+
+	filter := bson.M{"personId": id, "at": bson.M{"$lte": asOf}}
+	opts := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})
+
+	err := p.Revisions.FindOne(ctx, filter, opts).Decode(&revision)
+
+	if err == mongo.ErrNoDocuments {
+		return document.Person{}, ErrNotFound
+	}
+
+	if err != nil {
+		return document.Person{}, contextError(ctx, err)
+	}
+
+	return asOfRevision(revision)
+}
+
+func (p PersonRepository) FindRevision(ctx context.Context, id primitive.ObjectID, version int64) (document.Revision, error) {
+
+	var revision document.Revision
+	ctx, cancel := withTimeout(ctx, p.Timeout.FindById)
+	defer cancel()
+
+	err := p.Revisions.FindOne(ctx, bson.M{"personId": id, "version": version}).Decode(&revision)
+
+	if err == mongo.ErrNoDocuments {
+		return revision, ErrNotFound
+	}
+
+	return revision, contextError(ctx, err)
+}
+
+func (p PersonRepository) findOneAndUpdate(ctx context.Context, id primitive.ObjectID, version int64, update bson.M, operation string) (document.Person, error) {
+
+	person, err := p.modify(ctx, versionFilter(id, version), update, operation)
+
+	if err == mongo.ErrNoDocuments {
+		if version == 0 {
+			return person, ErrNotFound
+		}
+		return person, p.missing(ctx, id)
+	}
+
+	return person, err
+}
+
+// modify updates one person and records the revision, the previous value comes back in the same round trip.
+func (p PersonRepository) modify(ctx context.Context, filter bson.M, update bson.M, operation string) (document.Person, error) {
+
+	var before document.Person
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+
+	err := p.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&before)
+
+	if isDuplicateKey(err) {
+		set, _ := update["$set"].(bson.M)
+		return before, p.duplicate(ctx, set["email"])
+	}
+
+	if err == mongo.ErrNoDocuments {
+		return before, err
+	}
+
+	if err != nil {
+		return before, contextError(ctx, err)
+	}
+
+	after, err := applied(before, update)
+
+	if err != nil {
+		return after, err
+	}
+
+	return after, p.record(ctx, revision(ctx, operation, &before, after))
+}
+
+// record keeps the revision of a write that already happened, without a replica set it cannot be rolled back.
+func (p PersonRepository) record(ctx context.Context, revision document.Revision) error {
+
+	_, err := p.Revisions.InsertOne(ctx, revision)
+	return contextError(ctx, err)
+}
+
+// missing tells why a versioned write matched nothing, the person is gone or it has another version.
+func (p PersonRepository) missing(ctx context.Context, id primitive.ObjectID) error {
+
+	count, err := p.Collection.CountDocuments(ctx, bson.M{"_id": id, "deletedAt": nil})
+
+	if err != nil {
+		return contextError(ctx, err)
+	}
+
+	if count == 0 {
+		return ErrNotFound
+	}
+
+	return ErrVersionMismatch
+}
+
+func fieldValues(document document.Person) bson.M {
+	return bson.M{
+		"name":      document.Name,
+		"email":     document.Email,
+		"age":       document.Age,
+		"birthDate": document.BirthDate,
+		"phones":    document.Phones,
+		"addresses": document.Addresses,
+		"documents": document.Documents,
+		"grams":     personGrams(document),
+	}
+}
+
+// fieldSet is the $set of some fields of a person, with the grams when the name or the email change.
+func fieldSet(person document.Person, fields []string) bson.M {
+
+	values := fieldValues(person)
+	set := bson.M{}
+
+	for _, field := range fields {
+		set[field] = values[field]
+	}
+
+	if contains(fields, "name") || contains(fields, "email") {
+		set["grams"] = values["grams"]
+	}
+
+	return set
+}
+
+// duplicate finds who already has the email that broke the unique index, leaving the id out when nobody has it anymore.
+func (p PersonRepository) duplicate(ctx context.Context, email interface{}) error {
+
+	owner, err := p.owner(ctx, email)
+
+	// the owner was deleted or changed email after the write failed, the email was still taken then.
+	if err == mongo.ErrNoDocuments {
+		return DuplicateEmailError{}
+	}
+
+	if err != nil {
+		return contextError(ctx, err)
+	}
+
+	return DuplicateEmailError{Id: owner.Id}
+}
+
+// owner finds the active person having the email, ignoring case.
+func (p PersonRepository) owner(ctx context.Context, email interface{}) (document.Person, error) {
+
+	var person document.Person
+	opts := options.FindOne().SetCollation(emailCollation)
+	err := p.Collection.FindOne(ctx, bson.M{"email": email, "deletedAt": nil}, opts).Decode(&person)
+	return person, err
+}
+
+func versionFilter(id primitive.ObjectID, version int64) bson.M {
+
+	filter := bson.M{"_id": id, "deletedAt": nil}
+
+	if version != 0 {
+		filter["version"] = version
+	}
+
+	return filter
+}
+
+// plan builds the write of a bulk operation over the people read before, keeping them as the operation leaves them.
+func plan(ctx context.Context, operation BulkOperation, current map[primitive.ObjectID]document.Person) (mongo.WriteModel, document.Revision, error) {
+
+	person := operation.Person
+	var update bson.M
+
+	switch operation.Operation {
+	case document.Created:
+		person.Id = primitive.NewObjectID()
+		stampCreated(ctx, &person)
+		return mongo.NewInsertOneModel().SetDocument(stored(person)), revision(ctx, document.Created, nil, person), nil
+	case document.Updated:
+		set := fieldValues(person)
+		if len(operation.Fields) > 0 {
+			set = fieldSet(person, operation.Fields)
+		}
+		update = bson.M{"$set": audited(ctx, set), "$inc": bson.M{"version": 1}}
+	case document.Deleted:
+		update = bson.M{"$set": audited(ctx, bson.M{"deletedAt": now()}), "$inc": bson.M{"version": 1}}
+	default:
+		return nil, document.Revision{}, unknownOperation(operation)
+	}
+
+	before, ok := current[person.Id]
+
+	if !ok {
+		return nil, document.Revision{}, ErrNotFound
+	}
+
+	if person.Version != 0 && person.Version != before.Version {
+		return nil, document.Revision{}, ErrVersionMismatch
+	}
+
+	after, err := applied(before, update)
+
+	if err != nil {
+		return nil, document.Revision{}, err
+	}
+
+	if operation.Operation == document.Deleted {
+		delete(current, person.Id)
+	} else {
+		current[person.Id] = after
+	}
+
+	model := mongo.NewUpdateOneModel().SetFilter(versionFilter(person.Id, before.Version)).SetUpdate(update)
+	return model, revision(ctx, operation.Operation, &before, after), nil
+}
+
+func buildFilter(query Query) bson.M {
+
+	filter := bson.M{}
+
+	if !query.IncludeDeleted {
+		filter["deletedAt"] = nil
+	}
+
+	if query.Name != "" {
+		filter["name"] = primitive.Regex{Pattern: regexp.QuoteMeta(query.Name), Options: "i"}
+	}
+
+	if query.Email != "" {
+		filter["email"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(query.Email) + "$", Options: "i"}
+	}
+
+	if query.UpdatedSince != nil {
+		filter["updatedAt"] = bson.M{"$gte": query.UpdatedSince}
+	}
+
+	if query.MinAge != nil || query.MaxAge != nil {
+		filter["$and"] = bson.A{bson.M{"$or": ageFilter(query, *now())}}
+	}
+
+	if query.After != nil {
+		filter["$or"] = afterCursor(query)
+	}
+
+	return filter
+}
+
+// ageFilter matches the stored age of who has no birth date and the birth dates that give the ages for the others, it
+// goes under $and so it does not clash with the $or of afterCursor.
+func ageFilter(query Query, today time.Time) bson.A {
+
+	age := bson.M{}
+	birthDate := bson.M{}
+
+	if query.MinAge != nil {
+		age["$gte"] = *query.MinAge
+		birthDate["$lte"] = today.AddDate(-*query.MinAge, 0, 0)
+	}
+
+	if query.MaxAge != nil {
+		age["$lte"] = *query.MaxAge
+		birthDate["$gt"] = today.AddDate(-*query.MaxAge-1, 0, 0)
+	}
+
+	return bson.A{
+		bson.M{"birthDate": nil, "age": age},
+		bson.M{"birthDate": birthDate},
+	}
+}
+
+func afterCursor(query Query) bson.A {
+
+	operator := "$gt"
+
+	if len(query.Sort) == 1 && query.Sort[0].Descending {
+		operator = "$lt"
+	}
+
+	if len(query.Sort) == 1 && query.Sort[0].Field == "name" {
+		return bson.A{
+			bson.M{"name": bson.M{operator: query.After.Name}},
+			bson.M{"name": query.After.Name, "_id": bson.M{"$gt": query.After.Id}},
+		}
+	}
+
+	return bson.A{bson.M{"_id": bson.M{operator: query.After.Id}}}
+}
+
+// ageOrderField is added by find to the people sorted by age.
+const ageOrderField = "ageOrder"
+
+// ageOrder is the birth date of a person, or the one the stored age gives today for who has none, as birthOrder does.
+func ageOrder(today time.Time) bson.M {
+
+	today = today.UTC()
+	estimated := bson.M{"$dateFromParts": bson.M{
+		"year":  bson.M{"$subtract": bson.A{today.Year(), "$age"}},
+		"month": int(today.Month()),
+		"day":   today.Day(),
+	}}
+
+	return bson.M{"$ifNull": bson.A{"$birthDate", estimated}}
+}
+
+func sortedByAge(sort []Sort) bool {
+	for _, s := range sort {
+		if s.Field == "age" {
+			return true
+		}
+	}
+	return false
+}
+
+// buildSort orders by age through the birth order, the older the earlier it is.
+func buildSort(sort []Sort) bson.D {
+
+	fields := bson.D{}
+	hasId := false
+
+	for _, s := range sort {
+		direction := 1
+		if s.Descending != (s.Field == "age") {
+			direction = -1
+		}
+		key := sortField(s.Field)
+		hasId = hasId || key == "_id"
+		fields = append(fields, bson.E{Key: key, Value: direction})
+	}
+
+	if !hasId {
+		fields = append(fields, bson.E{Key: "_id", Value: 1})
+	}
+
+	return fields
+}
+
+func sortField(field string) string {
+	switch field {
+	case "id":
+		return "_id"
+	case "age":
+		return ageOrderField
+	}
+	return field
+}
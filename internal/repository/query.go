@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const DefaultPage int64 = 1
+const DefaultSize int64 = 20
+const MaxSize int64 = 100
+
+type Sort struct {
+	Field      string
+	Descending bool
+}
+
+// Cursor is the last person of a previous page, supported with the default, id or name sort.
+type Cursor struct {
+	Id   primitive.ObjectID
+	Name string
+}
+
+type Query struct {
+	Page   int64
+	Size   int64
+	Name   string
+	Email  string
+	MinAge *int
+	MaxAge *int
+	Sort   []Sort
+	After  *Cursor
+
+	IncludeDeleted bool
+	UpdatedSince   *time.Time
+
+	// Text is what Search looks for, by its terms or by similarity when Fuzzy. Search orders by relevance and only
+	// uses the page and size besides them.
+	Text  string
+	Fuzzy bool
+}
+
+func (q Query) Skip() int64 {
+	return (q.Page - 1) * q.Size
+}
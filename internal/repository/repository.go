@@ -1,14 +1,25 @@
 package repository
 
 import (
+	"context"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"person/internal/document"
+	"time"
 )
 
 type Repository interface {
-	Find() ([]document.Person, error)
-	FindById(id string) (document.Person, error)
-	Create(document document.Person) (document.Person, error)
-	Update(document document.Person) (int64, error)
-	Delete(id primitive.ObjectID) (int64, error)
+	Find(ctx context.Context, query Query) ([]document.Person, int64, error)
+	Stream(ctx context.Context, query Query, fn func(document.Person) error) error
+	Search(ctx context.Context, query Query) ([]document.Person, int64, error)
+	FindById(ctx context.Context, id string) (document.Person, error)
+	Create(ctx context.Context, document document.Person) (document.Person, error)
+	Update(ctx context.Context, document document.Person) (document.Person, error)
+	Patch(ctx context.Context, document document.Person, fields []string) (document.Person, error)
+	Delete(ctx context.Context, id primitive.ObjectID, version int64) (int64, error)
+	Restore(ctx context.Context, id primitive.ObjectID) (document.Person, error)
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+	History(ctx context.Context, id primitive.ObjectID, query Query) ([]document.Revision, int64, error)
+	FindAsOf(ctx context.Context, id primitive.ObjectID, asOf time.Time) (document.Person, error)
+	FindRevision(ctx context.Context, id primitive.ObjectID, version int64) (document.Revision, error)
+	Bulk(ctx context.Context, operations []BulkOperation, ordered bool) ([]BulkResult, error)
 }
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"bytes"
+	"golang.org/x/text/unicode/norm"
+	"person/internal/document"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// nameWeight and emailWeight are the weights of the text index, a term found in the name counts more than in the email.
+const nameWeight = 2
+const emailWeight = 1
+
+// fuzzyThreshold is the least similarity a person needs to be found by a fuzzy search, between 0 and 1.
+const fuzzyThreshold = 0.6
+
+// gramSize is the length of the pieces of words that choose the people a fuzzy search compares.
+const gramSize = 3
+
+// prefixDiscount keeps a word that only starts like the one searched behind a word equal to it.
+const prefixDiscount = 0.9
+
+type scored struct {
+	person document.Person
+	score  float64
+}
+
+// fold lowercases and drops the accents, so Joao and João are the same term as in a text index.
+func fold(value string) string {
+
+	var builder strings.Builder
+
+	for _, r := range norm.NFD.String(value) {
+		if !unicode.Is(unicode.Mn, r) {
+			builder.WriteRune(unicode.ToLower(r))
+		}
+	}
+
+	return builder.String()
+}
+
+// terms splits a value the way the text index does, on anything but letters, digits and underscores.
+func terms(value string) []string {
+	return strings.FieldsFunc(fold(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
+
+// searchTerms are the distinct terms of the text searched, without the quotes and minus signs a text index would take
+// as phrases and negations.
+func searchTerms(text string) []string {
+
+	var distinct []string
+	seen := map[string]bool{}
+
+	for _, term := range terms(text) {
+		if !seen[term] {
+			seen[term] = true
+			distinct = append(distinct, term)
+		}
+	}
+
+	return distinct
+}
+
+// scorer tells how relevant a person is to the query, 0 when the person is not found by it.
+func scorer(query Query) func(document.Person) float64 {
+
+	searched := searchTerms(query.Text)
+
+	if query.Fuzzy {
+		wanted := map[string]bool{}
+		for _, gram := range grams(searched) {
+			wanted[gram] = true
+		}
+		return func(person document.Person) float64 {
+			if !shares(wanted, personGrams(person)) {
+				return 0
+			}
+			return fuzzyScore(searched, person)
+		}
+	}
+
+	return func(person document.Person) float64 { return textScore(searched, person) }
+}
+
+// textScore scores the terms found the same way MongoDB does, each one by the weight of the field and by how much of
+// the field it takes.
+func textScore(searched []string, person document.Person) float64 {
+	return fieldScore(searched, terms(person.Name), nameWeight) + fieldScore(searched, terms(person.Email), emailWeight)
+}
+
+func fieldScore(searched []string, tokens []string, weight float64) float64 {
+
+	total := 0.0
+
+	for _, term := range searched {
+		frequency := 0
+		for _, token := range tokens {
+			if token == term {
+				frequency++
+			}
+		}
+		if frequency > 0 {
+			total += weight * (0.5*float64(frequency)/float64(len(tokens)) + 0.5)
+		}
+	}
+
+	return total
+}
+
+// fuzzyScore is the mean of how close each term searched is to the closest word of the name or of the email before the
+// at sign, so misspelled and partial names are still found.
+func fuzzyScore(searched []string, person document.Person) float64 {
+
+	if len(searched) == 0 {
+		return 0
+	}
+
+	words := fuzzyWords(person)
+	total := 0.0
+
+	for _, term := range searched {
+		best := 0.0
+		for _, word := range words {
+			if s := wordSimilarity([]rune(term), []rune(word)); s > best {
+				best = s
+			}
+		}
+		total += best
+	}
+
+	if mean := total / float64(len(searched)); mean >= fuzzyThreshold {
+		return mean
+	}
+
+	return 0
+}
+
+// fuzzyWords are the words of the name and of the email before the at sign, the ones a fuzzy search compares.
+func fuzzyWords(person document.Person) []string {
+
+	email := person.Email
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		email = email[:at]
+	}
+
+	return append(terms(person.Name), terms(email)...)
+}
+
+// grams are the distinct pieces of gramSize letters of the words padded with a space on both ends, so a word shorter
+// than gramSize and the first two letters of a word are pieces too.
+func grams(words []string) []string {
+
+	var distinct []string
+	seen := map[string]bool{}
+
+	for _, word := range words {
+		padded := []rune(" " + word + " ")
+		for i := 0; i+gramSize <= len(padded); i++ {
+			gram := string(padded[i : i+gramSize])
+			if !seen[gram] {
+				seen[gram] = true
+				distinct = append(distinct, gram)
+			}
+		}
+	}
+
+	return distinct
+}
+
+// personGrams are kept indexed with each person in MongoDB, a fuzzy search only compares the people sharing one of
+// them with the terms searched.
+func personGrams(person document.Person) []string {
+	return grams(fuzzyWords(person))
+}
+
+func shares(wanted map[string]bool, grams []string) bool {
+	for _, gram := range grams {
+		if wanted[gram] {
+			return true
+		}
+	}
+	return false
+}
+
+// wordSimilarity compares the term to the whole word and to as much of its start as the term is long.
+func wordSimilarity(term []rune, word []rune) float64 {
+
+	longest := len(word)
+	if len(term) > longest {
+		longest = len(term)
+	}
+
+	similarity := 1 - float64(distance(term, word))/float64(longest)
+
+	if len(word) > len(term) {
+		if prefix := prefixDiscount * (1 - float64(distance(term, word[:len(term)]))/float64(len(term))); prefix > similarity {
+			return prefix
+		}
+	}
+
+	return similarity
+}
+
+// distance is the Levenshtein distance counting the swap of two neighbouring letters as a single edit.
+func distance(a []rune, b []rune) int {
+
+	rows := make([][]int, len(a)+1)
+
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = rows[i-1][j-1] + cost
+			if rows[i-1][j]+1 < rows[i][j] {
+				rows[i][j] = rows[i-1][j] + 1
+			}
+			if rows[i][j-1]+1 < rows[i][j] {
+				rows[i][j] = rows[i][j-1] + 1
+			}
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && rows[i-2][j-2]+1 < rows[i][j] {
+				rows[i][j] = rows[i-2][j-2] + 1
+			}
+		}
+	}
+
+	return rows[len(a)][len(b)]
+}
+
+// rank orders the people found by relevance and then by id, as the sort of a text search, and keeps the page asked.
+func rank(query Query, found []scored) ([]document.Person, int64) {
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].score != found[j].score {
+			return found[i].score > found[j].score
+		}
+		return bytes.Compare(found[i].person.Id[:], found[j].person.Id[:]) < 0
+	})
+
+	var people []document.Person
+
+	for i := query.Skip(); i < int64(len(found)) && int64(len(people)) < query.Size; i++ {
+		people = append(people, found[i].person)
+	}
+
+	return people, int64(len(found))
+}
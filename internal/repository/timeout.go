@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+type Timeout struct {
+	Find     time.Duration
+	FindById time.Duration
+	Create   time.Duration
+	Update   time.Duration
+	Delete   time.Duration
+	Bulk     time.Duration
+	Stream   time.Duration
+	Search   time.Duration
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// contextError reports the context error instead of the driver one when the
+// operation failed because the caller went away or the timeout expired.
+func contextError(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
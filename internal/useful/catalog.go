@@ -0,0 +1,20 @@
+package useful
+
+import "person/internal/i18n"
+
+// Messages are written in English in message.go, the catalogs translate them to the other supported locales and keep
+// the status titles of problems, a message missing from a catalog is sent in English.
+var catalogs = map[string]map[string]string{
+	i18n.BrazilianPortuguese: brazilianPortuguese,
+	i18n.Spanish:             spanish,
+}
+
+// Localize translates a message to the locale.
+func Localize(locale i18n.Locale, message string) string {
+
+	if translated, ok := catalogs[locale.Tag][message]; ok {
+		return translated
+	}
+
+	return message
+}
@@ -0,0 +1,51 @@
+package useful
+
+var spanish = map[string]string{
+	ParserError:           "Error al convertir los datos.",
+	GetDataFromDbError:    "Error al obtener datos de la base de datos.",
+	CreateError:           "Error al crear una nueva persona.",
+	UpdateError:           "Error al actualizar una persona.",
+	DeleteError:           "Error al eliminar una persona.",
+	ImportError:           "Error al importar personas.",
+	BulkError:             "Error al ejecutar las operaciones en lote.",
+	RestoreError:          "Error al restaurar una persona.",
+	InternalErrorOccurred: "Ocurrió un error interno, por favor inténtelo más tarde.",
+	PersonNotFound:        "Persona no encontrada.",
+	RevisionNotFound:      "Revisión no encontrada.",
+	BrokenBody:            "El cuerpo enviado es incorrecto. Por favor envíe un cuerpo como el ejemplo de la documentación.",
+	InvalidFields:         "Algunos campos enviados no son válidos, vea los errores de cada uno.",
+	BodyTooLarge:          "El cuerpo enviado es demasiado grande. Por favor envíe un cuerpo más pequeño.",
+	MultipleValues:        "El cuerpo enviado contiene más de un valor. Por favor envíe una sola persona.",
+	UnknownFields:         "El cuerpo enviado tiene campos desconocidos. Por favor envíe solo los campos de la documentación.",
+	UnknownField:          "%s no es un campo conocido",
+	ReadOnlyField:         "%s es de solo lectura y no puede cambiarse",
+	BrokenId:              "El id enviado es incorrecto. Por favor envíe un id válido.",
+	BrokenRevision:        "La revisión enviada es incorrecta. Por favor envíe la versión de una revisión del historial.",
+	BrokenQuery:           "Los parámetros de consulta enviados son incorrectos. Por favor envíe parámetros como el ejemplo de la documentación.",
+	RequestCanceled:       "Solicitud cancelada antes de completarse.",
+	RequestTimeout:        "La base de datos tardó demasiado en responder, por favor inténtelo más tarde.",
+	BrokenPatch:           "El patch enviado es incorrecto. Por favor envíe un merge patch o json patch válido.",
+	UnsupportedPatch:      "El patch debe enviarse como application/merge-patch+json o application/json-patch+json.",
+	VersionMismatch:       "La persona cambió desde que fue leída. Por favor vuelva a obtenerla e inténtelo de nuevo.",
+	BrokenCsv:             "El CSV enviado es incorrecto. Por favor envíe una fila de encabezado con las columnas name, email y age.",
+	UnsupportedCsv:        "Las personas deben importarse como text/csv.",
+	NotAcceptable:         "Ninguno de los tipos de medio aceptados puede producirse, por favor acepte application/json, application/xml, application/yaml o application/msgpack.",
+	UnsupportedMediaType:  "El cuerpo debe enviarse como application/json, application/xml, application/yaml o application/msgpack.",
+	NdjsonOnly:            "La exportación solo está disponible como application/x-ndjson.",
+	BulkTooLarge:          "Demasiadas operaciones enviadas a la vez, por favor divídalas en lotes más pequeños.",
+	BulkSkipped:           "Operación no ejecutada porque una anterior falló en un lote ordenado.",
+	DuplicateEmail:        "El email ya es usado por otra persona.",
+
+	"Bad Request":              "Solicitud incorrecta",
+	"Not Found":                "No encontrado",
+	"Not Acceptable":           "No aceptable",
+	"Conflict":                 "Conflicto",
+	"Precondition Failed":      "Precondición fallida",
+	"Request Entity Too Large": "Entidad demasiado grande",
+	"Unsupported Media Type":   "Tipo de medio no soportado",
+	"Unprocessable Entity":     "Entidad no procesable",
+	"Failed Dependency":        "Dependencia fallida",
+	"Client Closed Request":    "El cliente cerró la solicitud",
+	"Internal Server Error":    "Error interno del servidor",
+	"Gateway Timeout":          "Tiempo de espera agotado",
+}
@@ -0,0 +1,51 @@
+package useful
+
+var brazilianPortuguese = map[string]string{
+	ParserError:           "Erro ao converter os dados.",
+	GetDataFromDbError:    "Erro ao buscar dados no banco de dados.",
+	CreateError:           "Erro ao criar uma nova pessoa.",
+	UpdateError:           "Erro ao atualizar uma pessoa.",
+	DeleteError:           "Erro ao excluir uma pessoa.",
+	ImportError:           "Erro ao importar pessoas.",
+	BulkError:             "Erro ao executar as operações em lote.",
+	RestoreError:          "Erro ao restaurar uma pessoa.",
+	InternalErrorOccurred: "Ocorreu um erro interno, por favor tente mais tarde.",
+	PersonNotFound:        "Pessoa não encontrada.",
+	RevisionNotFound:      "Revisão não encontrada.",
+	BrokenBody:            "O corpo enviado está errado. Por favor envie um corpo como o exemplo da documentação.",
+	InvalidFields:         "Alguns campos enviados são inválidos, veja os erros de cada um.",
+	BodyTooLarge:          "O corpo enviado é grande demais. Por favor envie um corpo menor.",
+	MultipleValues:        "O corpo enviado contém mais de um valor. Por favor envie uma única pessoa.",
+	UnknownFields:         "O corpo enviado tem campos desconhecidos. Por favor envie apenas os campos da documentação.",
+	UnknownField:          "%s não é um campo conhecido",
+	ReadOnlyField:         "%s é somente leitura e não pode ser alterado",
+	BrokenId:              "O id enviado está errado. Por favor envie um id válido.",
+	BrokenRevision:        "A revisão enviada está errada. Por favor envie a versão de uma revisão do histórico.",
+	BrokenQuery:           "Os parâmetros de consulta enviados estão errados. Por favor envie parâmetros como o exemplo da documentação.",
+	RequestCanceled:       "Requisição cancelada antes de ser concluída.",
+	RequestTimeout:        "O banco de dados demorou demais para responder, por favor tente mais tarde.",
+	BrokenPatch:           "O patch enviado está errado. Por favor envie um merge patch ou json patch válido.",
+	UnsupportedPatch:      "O patch deve ser enviado como application/merge-patch+json ou application/json-patch+json.",
+	VersionMismatch:       "A pessoa mudou desde que foi lida. Por favor busque-a novamente e tente de novo.",
+	BrokenCsv:             "O CSV enviado está errado. Por favor envie uma linha de cabeçalho com as colunas name, email e age.",
+	UnsupportedCsv:        "Pessoas devem ser importadas como text/csv.",
+	NotAcceptable:         "Nenhum dos tipos de mídia aceitos pode ser produzido, por favor aceite application/json, application/xml, application/yaml ou application/msgpack.",
+	UnsupportedMediaType:  "O corpo deve ser enviado como application/json, application/xml, application/yaml ou application/msgpack.",
+	NdjsonOnly:            "A exportação só está disponível como application/x-ndjson.",
+	BulkTooLarge:          "Operações demais enviadas de uma vez, por favor divida-as em lotes menores.",
+	BulkSkipped:           "Operação não executada porque uma anterior falhou em um lote ordenado.",
+	DuplicateEmail:        "O email já é usado por outra pessoa.",
+
+	"Bad Request":              "Requisição inválida",
+	"Not Found":                "Não encontrado",
+	"Not Acceptable":           "Não aceitável",
+	"Conflict":                 "Conflito",
+	"Precondition Failed":      "Pré-condição falhou",
+	"Request Entity Too Large": "Entidade muito grande",
+	"Unsupported Media Type":   "Tipo de mídia não suportado",
+	"Unprocessable Entity":     "Entidade não processável",
+	"Failed Dependency":        "Dependência falhou",
+	"Client Closed Request":    "Cliente encerrou a requisição",
+	"Internal Server Error":    "Erro interno do servidor",
+	"Gateway Timeout":          "Tempo de resposta esgotado",
+}
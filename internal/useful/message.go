@@ -1,18 +1,63 @@
 package useful
 
 const FindAll string = "Getting all people."
+const Search string = "Searching people with text and mode"
 const FindById string = "Getting person with id"
+const FindAsOf string = "Getting person as it was, with id and instant"
+const History string = "Getting history of person with id"
 const Create string = "Creating person with body"
 const Update string = "Updating person with id"
+const Patch string = "Patching person with id"
 const Delete string = "Deleting person with id"
+const Restore string = "Restoring person with id"
+const Import string = "Importing people from csv."
+const ExportCsv string = "Exporting people as csv with query"
+const Export string = "Exporting people as ndjson with query"
+const ExportStopped string = "Export stopped because the client went away."
+const Bulk string = "Running bulk operations, count and ordered"
+const Revert string = "Reverting person with id to revision"
+const Purge string = "Purging people deleted before"
+const Purged string = "People purged"
 const ConnectDbError string = "Error trying to connect to database."
+const CreateIndexError string = "Error trying to create database indexes, check for people sharing the same email."
 const GetDataFromDbError string = "Error trying to get data from the database."
 const ParserError string = "Error trying to parser data."
 const ValidateBodyError string = "Error validating body."
 const CreateError string = "Error creating new person."
 const UpdateError string = "Error updating a person."
 const DeleteError string = "Error deleting a person."
+const ImportError string = "Error importing people."
+const StreamError string = "Error streaming people, the response was cut short."
+const BulkError string = "Error running bulk operations."
+const RestoreError string = "Error restoring a person."
+const PurgeError string = "Error purging deleted people."
 const InternalErrorOccurred string = "An internal error occurred, please try later."
 const PersonNotFound string = "Person not found."
+const RevisionNotFound string = "Revision not found."
 const BrokenBody string = "Body sent is wrong. Please send a body like an example in documentation."
+const InvalidFields string = "Some fields sent are invalid, see the errors of each one."
+const BodyTooLarge string = "Body sent is too large. Please send a smaller body."
+const MultipleValues string = "Body sent holds more than one value. Please send a single person."
+const UnknownFields string = "Body sent has fields that are not known. Please send only the fields in the documentation."
+const UnknownField string = "%s is not a known field"
+const ReadOnlyField string = "%s is read only and cannot be changed"
 const BrokenId string = "Id sent is wrong. Please send a valid id."
+const BrokenRevision string = "Revision sent is wrong. Please send the version of a revision in the history."
+const BrokenQuery string = "Query parameters sent are wrong. Please send parameters like an example in documentation."
+const RequestCanceled string = "Request canceled before it was completed."
+const RequestTimeout string = "The database took too long to answer, please try later."
+const MemoryStorage string = "Using in-memory storage, data will be lost when the application stops."
+const UnknownStorage string = "Unknown storage driver, please use memory or mongo."
+const InvalidValidationRules string = "Validation rules are wrong, please check the validation properties."
+const UnknownNormalization string = "Unknown normalization rule, please use trim, collapse, lowercase, nfc or title."
+const BrokenPatch string = "Patch sent is wrong. Please send a valid merge patch or json patch."
+const UnsupportedPatch string = "Patch must be sent as application/merge-patch+json or application/json-patch+json."
+const VersionMismatch string = "Person was changed since it was read. Please get it again and retry."
+const BrokenCsv string = "CSV sent is wrong. Please send a header row with the name, email and age columns."
+const UnsupportedCsv string = "People must be imported as text/csv."
+const NotAcceptable string = "None of the accepted media types can be produced, please accept application/json, application/xml, application/yaml or application/msgpack."
+const UnsupportedMediaType string = "Body must be sent as application/json, application/xml, application/yaml or application/msgpack."
+const NdjsonOnly string = "Export is only available as application/x-ndjson."
+const BulkTooLarge string = "Too many operations sent at once, please split them in smaller bulks."
+const BulkSkipped string = "Operation not executed because a previous one failed in an ordered bulk."
+const DuplicateEmail string = "Email is already used by another person."
@@ -1,25 +1,90 @@
 package useful
 
 import (
-	"encoding/json"
 	"net/http"
+	"person/internal/codec"
 	"person/internal/dto"
+	"person/internal/i18n"
 )
 
+// ProblemBlank is the type of problems described by their status alone.
+const ProblemBlank = "about:blank"
+
+// ProblemInvalidFields is the type of problems listing the invalid fields in errors.
+const ProblemInvalidFields = "/problems/invalid-fields"
+
+// ProblemDuplicateEmail is the type of problems telling the id of the person who already has the email.
+const ProblemDuplicateEmail = "/problems/duplicate-email"
+
+var problemMediaTypes = map[string]string{
+	codec.JSON.MediaType(): codec.ProblemJSON,
+	codec.XML.MediaType():  codec.ProblemXML,
+}
+
 func BuildSuccess(w http.ResponseWriter, code int, payload interface{}) {
-	response, _ := json.Marshal(payload)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	_, _ = w.Write(response)
+	write(w, code, codec.Of(w).MediaType(), payload)
 }
 
-func BuildError(w http.ResponseWriter, code int, message string) {
-	response, _ := json.Marshal(buildError(message))
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	_, _ = w.Write(response)
+func BuildError(w http.ResponseWriter, r *http.Request, code int, message string) {
+	writeProblem(w, buildProblem(w, r, code, ProblemBlank, message))
+}
+
+func BuildErrorWithId(w http.ResponseWriter, r *http.Request, code int, message string, id string) {
+	problem := buildProblem(w, r, code, ProblemDuplicateEmail, message)
+	problem.Id = id
+	writeProblem(w, problem)
 }
 
-func buildError(message string) dto.Error {
-	return dto.Error{Message: message}
+func BuildFieldErrors(w http.ResponseWriter, r *http.Request, code int, message string, errors []dto.FieldError) {
+	problem := buildProblem(w, r, code, ProblemInvalidFields, message)
+	problem.Errors = errors
+	writeProblem(w, problem)
+}
+
+// buildProblem writes the title and detail in the locale the client accepts, telling it in Content-Language.
+func buildProblem(w http.ResponseWriter, r *http.Request, code int, problemType string, message string) dto.Problem {
+
+	locale := i18n.FromRequest(r)
+	w.Header().Set("Content-Language", locale.Tag)
+	title := http.StatusText(code)
+
+	if title == "" {
+		title = "Client Closed Request"
+	}
+
+	return dto.Problem{
+		Type:     problemType,
+		Title:    Localize(locale, title),
+		Status:   code,
+		Detail:   Localize(locale, message),
+		Instance: r.URL.Path,
+	}
+}
+
+// writeProblem uses the problem media type of the negotiated codec when there is one.
+func writeProblem(w http.ResponseWriter, problem dto.Problem) {
+
+	mediaType := codec.Of(w).MediaType()
+
+	if problemMediaType, ok := problemMediaTypes[mediaType]; ok {
+		mediaType = problemMediaType
+	}
+
+	write(w, problem.Status, mediaType, problem)
+}
+
+// write encodes the payload with the codec negotiated for the response, falling back to a json internal error when
+// that codec cannot encode it.
+func write(w http.ResponseWriter, code int, mediaType string, payload interface{}) {
+	response, err := codec.Of(w).Marshal(payload)
+
+	if err != nil {
+		code = http.StatusInternalServerError
+		mediaType = codec.ProblemJSON
+		response, _ = codec.JSON.Marshal(dto.Problem{Type: ProblemBlank, Title: http.StatusText(code), Status: code, Detail: InternalErrorOccurred})
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(code)
+	_, _ = w.Write(response)
 }
@@ -0,0 +1,170 @@
+package codec
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http/httptest"
+	"person/internal/codec"
+	"person/internal/dto"
+	"strings"
+	"testing"
+	"time"
+)
+
+func person() dto.Person {
+	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
+	createdAt := time.Date(2020, 7, 21, 10, 30, 0, 0, time.UTC)
+	birthDate := dto.NewDate(1998, time.March, 2)
+	return dto.Person{
+		Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: intPointer(22), BirthDate: &birthDate, CreatedBy: "lucas", CreatedAt: &createdAt,
+		Phones: []dto.Phone{{Type: "mobile", Number: "+5511987654321"}, {Type: "work", Number: "+551130000000"}},
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+
+	tests := []struct {
+		accept    string
+		others    []string
+		mediaType string
+		ok        bool
+	}{
+		{"", nil, "application/json", true},
+		{"*/*", nil, "application/json", true},
+		{"application/xml", nil, "application/xml", true},
+		{"text/xml", nil, "text/xml", true},
+		{"application/x-yaml", nil, "application/x-yaml", true},
+		{"application/msgpack;q=0.5, application/yaml", nil, "application/yaml", true},
+		{"application/xml;q=0.9, */*;q=0.1", nil, "application/xml", true},
+		{"application/*", nil, "application/json", true},
+		{"text/*", nil, "text/xml", true},
+		{"application/json;q=0, application/xml;q=0.2", nil, "application/xml", true},
+		{"text/html", nil, "", false},
+		{"text/csv", nil, "", false},
+		{"text/csv", []string{"text/csv"}, "text/csv", true},
+		{"text/csv;q=0.1, application/yaml", []string{"text/csv"}, "application/yaml", true},
+		{"broken/", nil, "", false},
+	}
+
+	for _, test := range tests {
+		chosen, mediaType, ok := codec.Default.Negotiate(test.accept, test.others...)
+
+		assert.Equal(t, test.ok, ok, test.accept)
+		assert.Equal(t, test.mediaType, mediaType, test.accept)
+		if ok && mediaType != "text/csv" {
+			assert.NotNil(t, chosen, test.accept)
+		}
+	}
+}
+
+func TestForContentType(t *testing.T) {
+
+	tests := []struct {
+		contentType string
+		codec       codec.Codec
+		ok          bool
+	}{
+		{"", codec.JSON, true},
+		{"application/json; charset=utf-8", codec.JSON, true},
+		{"text/xml", codec.XML, true},
+		{"application/yaml", codec.YAML, true},
+		{"application/vnd.msgpack", codec.MessagePack, true},
+		{"text/plain", nil, false},
+		{"broken/", nil, false},
+	}
+
+	for _, test := range tests {
+		found, ok := codec.Default.ForContentType(test.contentType)
+
+		assert.Equal(t, test.ok, ok, test.contentType)
+		assert.Equal(t, test.codec, found, test.contentType)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+
+	for _, c := range []codec.Codec{codec.JSON, codec.XML, codec.YAML, codec.MessagePack} {
+		encoded, err := c.Marshal(person())
+		assert.Nil(t, err, c.MediaType())
+
+		var decoded dto.Person
+		err = c.Unmarshal(encoded, &decoded)
+
+		assert.Nil(t, err, c.MediaType())
+		assert.Equal(t, person().Id, decoded.Id, c.MediaType())
+		assert.Equal(t, person().Name, decoded.Name, c.MediaType())
+		assert.Equal(t, person().Age, decoded.Age, c.MediaType())
+		assert.Equal(t, person().BirthDate, decoded.BirthDate, c.MediaType())
+		assert.Equal(t, person().Phones, decoded.Phones, c.MediaType())
+		assert.Equal(t, person().CreatedBy, decoded.CreatedBy, c.MediaType())
+		assert.True(t, person().CreatedAt.Equal(*decoded.CreatedAt), c.MediaType())
+	}
+}
+
+func TestXmlUsesJsonNames(t *testing.T) {
+
+	encoded, err := codec.XML.Marshal(dto.Page{Data: []dto.Person{person()}, Total: 1, Page: 1, Size: 10})
+
+	assert.Nil(t, err)
+	assert.Contains(t, string(encoded), "<page><data><item><id>5f165e2e4de9b442e60b3904</id><name>Lucas</name>")
+	assert.Contains(t, string(encoded), "<createdAt>2020-07-21T10:30:00Z</createdAt>")
+	assert.NotContains(t, string(encoded), "updatedAt")
+	assert.Contains(t, string(encoded), "<total>1</total>")
+}
+
+func TestXmlReadsText(t *testing.T) {
+
+	var decoded dto.Person
+	err := codec.XML.Unmarshal([]byte("<person><name>Lucas</name><email>lucas@gmail.com</email><age>22</age></person>"), &decoded)
+
+	assert.Nil(t, err)
+	assert.Equal(t, dto.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: intPointer(22)}, decoded)
+
+	err = codec.XML.Unmarshal([]byte("<person><age>old</age></person>"), &decoded)
+	assert.NotNil(t, err)
+}
+
+func TestYamlKeepsFieldOrder(t *testing.T) {
+
+	encoded, err := codec.YAML.Marshal(person())
+
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(encoded), "id: 5f165e2e4de9b442e60b3904\nname: Lucas\nemail: lucas@gmail.com\nage: 22\n"))
+}
+
+func TestOfDefaultsToJson(t *testing.T) {
+
+	w := httptest.NewRecorder()
+
+	assert.Equal(t, codec.JSON, codec.Of(w))
+	assert.Equal(t, codec.YAML, codec.Of(codec.With(w, codec.YAML)))
+}
+
+func TestUnmarshalIsStrict(t *testing.T) {
+
+	tests := []struct {
+		codec codec.Codec
+		data  string
+		err   error
+	}{
+		{codec.JSON, `{"name": "Lucas", "nickname": "lu"}`, codec.UnknownFieldError{Field: "nickname"}},
+		{codec.JSON, `{"name": "Lucas"} {"name": "Ana"}`, codec.ErrMultipleValues},
+		{codec.XML, "<person><name>Lucas</name><nickname>lu</nickname></person>", codec.UnknownFieldError{Field: "nickname"}},
+		{codec.XML, "<person><name>Lucas</name></person><person></person>", codec.ErrMultipleValues},
+		{codec.YAML, "name: Lucas\nnickname: lu\n", codec.UnknownFieldError{Field: "nickname"}},
+		{codec.YAML, "name: Lucas\n---\nname: Ana\n", codec.ErrMultipleValues},
+	}
+
+	for _, test := range tests {
+		var decoded dto.Person
+		assert.Equal(t, test.err, test.codec.Unmarshal([]byte(test.data), &decoded), test.data)
+	}
+
+	encoded, _ := codec.MessagePack.Marshal(person())
+	var decoded dto.Person
+	assert.Equal(t, codec.ErrMultipleValues, codec.MessagePack.Unmarshal(append(encoded, encoded...), &decoded))
+}
+
+func intPointer(value int) *int {
+	return &value
+}
@@ -0,0 +1,45 @@
+package configs
+
+import (
+	"github.com/stretchr/testify/assert"
+	"person/configs"
+	"person/internal/dto"
+	"person/internal/handler"
+	"testing"
+)
+
+func TestLoadPartialValidationSection(t *testing.T) {
+
+	base := []byte("port: 3000\nvalidation:\n  minage: 0\n  maxage: 150\n  minnamelength: 1\n  maxnamelength: 100\n")
+	env := []byte("validation:\n  emaildomains: [example.com]\n")
+
+	properties, err := configs.Load(base, env)
+	assert.Nil(t, err)
+
+	rules := properties.ValidationRules()
+	expected := dto.DefaultValidationRules()
+	expected.EmailDomains = []string{"example.com"}
+
+	assert.Equal(t, 3000, properties.Port)
+	assert.Equal(t, expected, rules, "the rules left out get their defaults")
+
+	_, err = handler.NewValidator(rules)
+	assert.Nil(t, err)
+}
+
+func TestLoadWithoutValidationSection(t *testing.T) {
+
+	properties, err := configs.Load([]byte("port: 3000\n"), []byte("storage:\n  driver: memory\n"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, dto.DefaultValidationRules(), properties.ValidationRules())
+}
+
+func TestLoadValidationSection(t *testing.T) {
+
+	properties, err := configs.Load([]byte("validation:\n  minage: 18\n  maxage: 65\n  minnamelength: 3\n  maxnamelength: 50\n"), nil)
+	rules := dto.ValidationRules{MinAge: 18, MaxAge: 65, MinNameLength: 3, MaxNameLength: 50, EmailDomains: []string{}}
+
+	assert.Nil(t, err)
+	assert.Equal(t, rules, properties.ValidationRules())
+}
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
@@ -14,9 +16,13 @@ import (
 	"person/internal/dto"
 	"person/internal/handler"
 	"person/internal/mapper"
+	"person/internal/normalize"
+	"person/internal/repository"
 	"person/internal/useful"
 	"person/test/mocks"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestFindSuccess(t *testing.T) {
@@ -34,334 +40,391 @@ func TestFindSuccess(t *testing.T) {
 		{Id: objID2, Email: "test@gmail.com", Age: 20},
 	}
 
-	repo.EXPECT().Find().Return(docs, nil)
+	query := repository.Query{Page: repository.DefaultPage, Size: repository.DefaultSize}
+	repo.EXPECT().Find(gomock.Any(), gomock.Eq(query)).Return(docs, int64(2), nil)
 
 	r, _ := http.NewRequest("GET", "/person", nil)
 	w := httptest.NewRecorder()
 
 	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Find(w, r)
 
-	var body []dto.Person
-	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	var page dto.Page
+	_ = json.Unmarshal(w.Body.Bytes(), &page)
+	body := page.Data
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(2), page.Total)
+	assert.Equal(t, repository.DefaultPage, page.Page)
+	assert.Equal(t, repository.DefaultSize, page.Size)
+	assert.Empty(t, page.Next)
+	assert.Empty(t, page.Prev)
 	assert.Equal(t, docs[0].Id, body[0].Id)
 	assert.Equal(t, docs[0].Name, body[0].Name)
 	assert.Equal(t, docs[0].Email, body[0].Email)
-	assert.Equal(t, docs[0].Age, body[0].Age)
+	assert.Equal(t, docs[0].Age, *body[0].Age)
 	assert.Equal(t, docs[1].Id, body[1].Id)
 	assert.Equal(t, docs[1].Name, body[1].Name)
 	assert.Equal(t, docs[1].Email, body[1].Email)
-	assert.Equal(t, docs[1].Age, body[1].Age)
+	assert.Equal(t, docs[1].Age, *body[1].Age)
 }
 
-func TestFindReturningErrorFromDatabaseWhenTryFind(t *testing.T) {
+func TestFindWithFiltersSortAndPagination(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockRepository(ctrl)
 
-	repo.EXPECT().Find().Return(nil, errors.New("database error"))
+	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
+	docs := []document.Person{{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}}
+
+	minAge, maxAge := 18, 30
+	query := repository.Query{
+		Page:   2,
+		Size:   1,
+		Name:   "luc",
+		Email:  "lucas@gmail.com",
+		MinAge: &minAge,
+		MaxAge: &maxAge,
+		Sort:   []repository.Sort{{Field: "name"}, {Field: "age", Descending: true}},
+	}
+	repo.EXPECT().Find(gomock.Any(), gomock.Eq(query)).Return(docs, int64(3), nil)
 
-	r, _ := http.NewRequest("GET", "/person", nil)
+	r, _ := http.NewRequest("GET", "/v1/person?page=2&size=1&name=luc&email=lucas@gmail.com&minAge=18&maxAge=30&sort=name,-age", nil)
 	w := httptest.NewRecorder()
 
 	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Find(w, r)
 
-	var body dto.Error
-	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	var page dto.Page
+	_ = json.Unmarshal(w.Body.Bytes(), &page)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.InternalErrorOccurred}, body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, int64(2), page.Page)
+	assert.Equal(t, int64(1), page.Size)
+	assert.Len(t, page.Data, 1)
+	assert.Contains(t, page.Next, "page=3")
+	assert.Contains(t, page.Prev, "page=1")
+	assert.Contains(t, page.Next, "sort=name%2C-age")
 }
 
-func TestFindReturningErrorWhenTryDoMapper(t *testing.T) {
+func TestFindWithInvalidQuery(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockRepository(ctrl)
-	mapp := mocks.NewMockMapper(ctrl)
 
-	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
-
-	docs := []document.Person{
-		{Id: objID, Name: "Lucas", Email: "lucas@@gmail.com", Age: 22},
-	}
-
-	repo.EXPECT().Find().Return(docs, nil)
-	mapp.EXPECT().ListDocumentToListDto(docs).Return(nil, errors.New("mapper error"))
-
-	r, _ := http.NewRequest("GET", "/person", nil)
-	w := httptest.NewRecorder()
+	for _, rawQuery := range []string{"page=0", "size=abc", "size=1000", "minAge=200", "minAge=30&maxAge=18", "sort=password", "updatedSince=yesterday"} {
+		r, _ := http.NewRequest("GET", "/v1/person?"+rawQuery, nil)
+		w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(mapp, repo).Find(w, r)
+		handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Find(w, r)
 
-	var body dto.Error
-	_ = json.Unmarshal([]byte(w.Body.String()), &body)
+		var body dto.Problem
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.InternalErrorOccurred}, body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, rawQuery)
+		assert.Equal(t, useful.BrokenQuery, body.Detail, rawQuery)
+	}
 }
 
-func TestFindSuccessReturningEmptyBody(t *testing.T) {
+func TestFindWithCursorWalksWithKeyset(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockRepository(ctrl)
 
-	repo.EXPECT().Find().Return(nil, nil)
+	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
+	objID2, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3905")
+	docs := []document.Person{
+		{Id: objID, Name: "Ana", Email: "ana@gmail.com", Age: 30},
+		{Id: objID2, Name: "Lucas", Email: "lucas@gmail.com", Age: 22},
+	}
+	sort := []repository.Sort{{Field: "name"}}
+
+	first := repository.Query{Page: repository.DefaultPage, Size: 2, Sort: sort}
+	second := repository.Query{Page: repository.DefaultPage, Size: 2, Sort: sort, After: &repository.Cursor{Id: objID2, Name: "Lucas"}}
 
-	r, _ := http.NewRequest("GET", "/person", nil)
+	gomock.InOrder(
+		repo.EXPECT().Find(gomock.Any(), gomock.Eq(first)).Return(docs, int64(3), nil),
+		repo.EXPECT().Find(gomock.Any(), gomock.Eq(second)).Return(docs[:1], int64(3), nil),
+	)
+
+	personHandler := handler.NewPersonHandler(&mapper.PersonMapper{}, repo)
+
+	r, _ := http.NewRequest("GET", "/v1/person?size=2&sort=name&cursor=", nil)
 	w := httptest.NewRecorder()
+	personHandler.Find(w, r)
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Find(w, r)
+	var page dto.Page
+	_ = json.Unmarshal(w.Body.Bytes(), &page)
 
-	var body []struct{}
-	_ = json.Unmarshal([]byte(w.Body.String()), &body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, page.Cursor)
+	assert.Contains(t, page.Next, "cursor="+page.Cursor)
+	assert.Empty(t, page.Prev)
+
+	r, _ = http.NewRequest("GET", page.Next, nil)
+	w = httptest.NewRecorder()
+	personHandler.Find(w, r)
+
+	page = dto.Page{}
+	_ = json.Unmarshal(w.Body.Bytes(), &page)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Len(t, body, 0)
-	assert.Empty(t, body)
+	assert.Len(t, page.Data, 1)
+	assert.Empty(t, page.Cursor)
+	assert.Empty(t, page.Next)
 }
 
-func TestFindByIdSuccess(t *testing.T) {
-
-	id := "5f165e2e4de9b442e60b3904"
-	objID, _ := primitive.ObjectIDFromHex(id)
-	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
+func TestFindWithInvalidCursor(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockRepository(ctrl)
 
-	repo.EXPECT().FindById(gomock.Eq(id)).Return(doc, nil)
+	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
+	repo.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]document.Person{{Id: objID, Name: "Lucas"}}, int64(2), nil)
 
-	r, _ := http.NewRequest("GET", "/person/{id}", nil)
-	r = mux.SetURLVars(r, map[string]string{"id": id})
+	r, _ := http.NewRequest("GET", "/v1/person?size=1&cursor=", nil)
 	w := httptest.NewRecorder()
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Find(w, r)
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).FindById(w, r)
+	var page dto.Page
+	_ = json.Unmarshal(w.Body.Bytes(), &page)
 
-	var body dto.Person
-	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	for _, rawQuery := range []string{"cursor=&page=2", "cursor=&sort=age", "cursor=&sort=name,id", "cursor=not-a-token", "sort=name&cursor=" + page.Cursor} {
+		r, _ := http.NewRequest("GET", "/v1/person?"+rawQuery, nil)
+		w := httptest.NewRecorder()
 
-	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Equal(t, doc.Id, body.Id)
-	assert.Equal(t, doc.Name, body.Name)
-	assert.Equal(t, doc.Email, body.Email)
-	assert.Equal(t, doc.Age, body.Age)
-}
+		handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Find(w, r)
 
-func TestFindByIdReturningErrorFromDatabaseWhenTryFind(t *testing.T) {
+		var body dto.Problem
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
 
-	id := "5f165e2e4de9b442e60b3904"
+		assert.Equal(t, http.StatusBadRequest, w.Code, rawQuery)
+		assert.Equal(t, useful.BrokenQuery, body.Detail, rawQuery)
+	}
+}
+
+func TestSearchSuccess(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockRepository(ctrl)
 
-	repo.EXPECT().FindById(gomock.Eq(id)).Return(document.Person{}, errors.New("Find error"))
+	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
+	docs := []document.Person{{Id: objID, Name: "João da Silva", Email: "joao@gmail.com", Age: 22}}
+
+	query := repository.Query{Page: 2, Size: 1, Text: "joao silva"}
+	repo.EXPECT().Search(gomock.Any(), gomock.Eq(query)).Return(docs, int64(3), nil)
 
-	r, _ := http.NewRequest("GET", "/person/{id}", nil)
-	r = mux.SetURLVars(r, map[string]string{"id": id})
+	r, _ := http.NewRequest("GET", "/v1/person/search?q=+joao+silva+&page=2&size=1", nil)
 	w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).FindById(w, r)
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Search(w, r)
 
-	var body dto.Error
-	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	var page dto.SearchPage
+	_ = json.Unmarshal(w.Body.Bytes(), &page)
 
-	assert.Equal(t, http.StatusNotFound, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.PersonNotFound}, body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text", page.Mode)
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, "João da Silva", page.Data[0].Name)
+	assert.Contains(t, page.Next, "page=3")
+	assert.Contains(t, page.Prev, "page=1")
+	assert.Contains(t, page.Next, "q=+joao+silva+")
 }
 
-func TestFindByIdReturningErrorWhenTryDoMapper(t *testing.T) {
-
-	id := "5f165e2e4de9b442e60b3904"
-	objID, _ := primitive.ObjectIDFromHex(id)
-	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
+func TestSearchFallingBackToFuzzy(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockRepository(ctrl)
-	repo.EXPECT().FindById(gomock.Eq(id)).Return(doc, nil)
 
-	mapp := mocks.NewMockMapper(ctrl)
-	mapp.EXPECT().DocumentToDto(gomock.Eq(doc)).Return(dto.Person{}, errors.New("Mapper Error"))
+	docs := []document.Person{{Name: "João da Silva", Email: "joao@gmail.com", Age: 22}}
+	query := repository.Query{Page: repository.DefaultPage, Size: repository.DefaultSize, Text: "jaoo"}
+	fuzzy := query
+	fuzzy.Fuzzy = true
 
-	r, _ := http.NewRequest("GET", "/person/{id}", nil)
-	r = mux.SetURLVars(r, map[string]string{"id": id})
+	gomock.InOrder(
+		repo.EXPECT().Search(gomock.Any(), gomock.Eq(query)).Return(nil, int64(0), nil),
+		repo.EXPECT().Search(gomock.Any(), gomock.Eq(fuzzy)).Return(docs, int64(1), nil),
+	)
+
+	r, _ := http.NewRequest("GET", "/v1/person/search?q=jaoo", nil)
 	w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(mapp, repo).FindById(w, r)
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Search(w, r)
 
-	var body dto.Error
-	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	var page dto.SearchPage
+	_ = json.Unmarshal(w.Body.Bytes(), &page)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.ParserError}, body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "fuzzy", page.Mode)
+	assert.Len(t, page.Data, 1)
 }
 
-func TestCreateSuccess(t *testing.T) {
+func TestSearchWithMode(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockRepository(ctrl)
 
-	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
-	docWithoutId := document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
-	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
-
-	repo.EXPECT().Create(gomock.Eq(docWithoutId)).Return(doc, nil)
+	for mode, fuzzy := range map[string]bool{"text": false, "fuzzy": true} {
+		query := repository.Query{Page: repository.DefaultPage, Size: repository.DefaultSize, Text: "jaoo", Fuzzy: fuzzy}
+		repo.EXPECT().Search(gomock.Any(), gomock.Eq(query)).Return(nil, int64(0), nil)
 
-	bodySent, _ := json.Marshal(docWithoutId)
-
-	r, _ := http.NewRequest("POST", "/person", bytes.NewBuffer(bodySent))
-	w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/v1/person/search?q=jaoo&mode="+mode, nil)
+		w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Create(w, r)
+		handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Search(w, r)
 
-	var body dto.Person
-	_ = json.Unmarshal(w.Body.Bytes(), &body)
+		var page dto.SearchPage
+		_ = json.Unmarshal(w.Body.Bytes(), &page)
 
-	assert.Equal(t, http.StatusCreated, w.Code)
-	assert.Equal(t, doc.Id, body.Id)
-	assert.Equal(t, doc.Name, body.Name)
-	assert.Equal(t, doc.Email, body.Email)
-	assert.Equal(t, doc.Age, body.Age)
+		assert.Equal(t, http.StatusOK, w.Code, mode)
+		assert.Equal(t, mode, page.Mode)
+		assert.Equal(t, int64(0), page.Total, mode)
+	}
 }
 
-func TestCreateBrokenBody(t *testing.T) {
+func TestSearchFailures(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockRepository(ctrl)
-	bodySent, _ := json.Marshal(struct {
-		Id   int
-		Text string
-	}{123, "Broked"})
 
-	r, _ := http.NewRequest("POST", "/person", bytes.NewBuffer(bodySent))
-	w := httptest.NewRecorder()
+	for _, rawQuery := range []string{"", "q=+", "q=joao&mode=exact", "q=joao&size=1000"} {
+		r, _ := http.NewRequest("GET", "/v1/person/search?"+rawQuery, nil)
+		w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Create(w, r)
+		handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Search(w, r)
 
-	var body dto.Error
-	_ = json.Unmarshal(w.Body.Bytes(), &body)
+		var body dto.Problem
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
 
-	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.BrokenBody}, body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, rawQuery)
+		assert.Equal(t, useful.BrokenQuery, body.Detail, rawQuery)
+	}
+
+	repo.EXPECT().Search(gomock.Any(), gomock.Any()).Return(nil, int64(0), errors.New("database down"))
+
+	r, _ := http.NewRequest("GET", "/v1/person/search?q=joao", nil)
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Search(w, r)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
-func TestCreateValidatingContentOnFields(t *testing.T) {
+func TestFindReturningErrorFromDatabaseWhenTryFind(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockRepository(ctrl)
-	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@@gmail.com", Age: 22})
 
-	r, _ := http.NewRequest("POST", "/person", bytes.NewBuffer(bodySent))
+	repo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, int64(0), errors.New("database error"))
+
+	r, _ := http.NewRequest("GET", "/person", nil)
 	w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Create(w, r)
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Find(w, r)
 
-	var body dto.Error
+	var body dto.Problem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
 
-	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.BrokenBody}, body)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, useful.InternalErrorOccurred, body.Detail)
 }
 
-func TestCreateReturningErrorWhenTryDoMapperToDocument(t *testing.T) {
+func TestFindSuccessReturningEmptyBody(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	doc := dto.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
-
 	repo := mocks.NewMockRepository(ctrl)
-	mapp := mocks.NewMockMapper(ctrl)
-	mapp.EXPECT().DtoToDocument(doc).Return(document.Person{}, errors.New("mapper error"))
 
-	bodySent, _ := json.Marshal(doc)
+	repo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, int64(0), nil)
 
-	r, _ := http.NewRequest("POST", "/person", bytes.NewBuffer(bodySent))
+	r, _ := http.NewRequest("GET", "/person", nil)
 	w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(mapp, repo).Create(w, r)
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Find(w, r)
 
-	var body dto.Error
-	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	var body struct {
+		Data []struct{} `json:"data"`
+	}
+	_ = json.Unmarshal([]byte(w.Body.String()), &body)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.ParserError}, body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotNil(t, body.Data)
+	assert.Len(t, body.Data, 0)
 }
 
-func TestCreateReturningErrorFromDatabaseWhenTryCreate(t *testing.T) {
+func TestFindByIdSuccess(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	doc := document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
-
 	repo := mocks.NewMockRepository(ctrl)
-	repo.EXPECT().Create(gomock.Eq(doc)).Return(document.Person{}, errors.New("Create error"))
 
-	bodySent, _ := json.Marshal(doc)
+	repo.EXPECT().FindById(gomock.Any(), gomock.Eq(id)).Return(doc, nil)
 
-	r, _ := http.NewRequest("POST", "/person", bytes.NewBuffer(bodySent))
+	r, _ := http.NewRequest("GET", "/person/{id}", nil)
+	r = mux.SetURLVars(r, map[string]string{"id": id})
 	w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Create(w, r)
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).FindById(w, r)
 
-	var body dto.Error
+	var body dto.Person
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.CreateError}, body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, doc.Id, body.Id)
+	assert.Equal(t, doc.Name, body.Name)
+	assert.Equal(t, doc.Email, body.Email)
+	assert.Equal(t, doc.Age, *body.Age)
 }
 
-func TestCreateReturningErrorWhenTryDoMapperToDTO(t *testing.T) {
+func TestFindByIdReturningErrorFromDatabaseWhenTryFind(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	pdto := dto.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
-	doc := document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
-
-	mapp := mocks.NewMockMapper(ctrl)
-	mapp.EXPECT().DtoToDocument(pdto).Return(doc, nil)
-	mapp.EXPECT().DocumentToDto(doc).Return(pdto, errors.New("Mapper error"))
-
 	repo := mocks.NewMockRepository(ctrl)
-	repo.EXPECT().Create(gomock.Eq(doc)).Return(doc, nil)
 
-	bodySent, _ := json.Marshal(doc)
+	repo.EXPECT().FindById(gomock.Any(), gomock.Eq(id)).Return(document.Person{}, errors.New("Find error"))
 
-	r, _ := http.NewRequest("POST", "/person", bytes.NewBuffer(bodySent))
+	r, _ := http.NewRequest("GET", "/person/{id}", nil)
+	r = mux.SetURLVars(r, map[string]string{"id": id})
 	w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(mapp, repo).Create(w, r)
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).FindById(w, r)
 
-	var body dto.Error
+	var body dto.Problem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.ParserError}, body)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, useful.PersonNotFound, body.Detail)
 }
 
-func TestUpdateSuccess(t *testing.T) {
+func TestCreateSuccess(t *testing.T) {
 
-	id := "5f165e2e4de9b442e60b3904"
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -371,29 +434,27 @@ func TestUpdateSuccess(t *testing.T) {
 	docWithoutId := document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
 	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
 
-	repo.EXPECT().Update(gomock.Eq(doc)).Return(int64(1), nil)
+	repo.EXPECT().Create(gomock.Any(), gomock.Eq(docWithoutId)).Return(doc, nil)
 
-	bodySent, _ := json.Marshal(docWithoutId)
+	bodySent, _ := json.Marshal(dto.Person{Name: docWithoutId.Name, Email: docWithoutId.Email, Age: intPointer(docWithoutId.Age)})
 
-	r, _ := http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
-	r = mux.SetURLVars(r, map[string]string{"id": id})
+	r, _ := http.NewRequest("POST", "/person", bytes.NewBuffer(bodySent))
 	w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Update(w, r)
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Create(w, r)
 
 	var body dto.Person
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
 
-	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusCreated, w.Code)
 	assert.Equal(t, doc.Id, body.Id)
 	assert.Equal(t, doc.Name, body.Name)
 	assert.Equal(t, doc.Email, body.Email)
-	assert.Equal(t, doc.Age, body.Age)
+	assert.Equal(t, doc.Age, *body.Age)
 }
 
-func TestUpdateBrokenBody(t *testing.T) {
+func TestCreateBrokenBody(t *testing.T) {
 
-	id := "5f165e2e4de9b442e60b3904"
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -403,269 +464,1846 @@ func TestUpdateBrokenBody(t *testing.T) {
 		Text string
 	}{123, "Broked"})
 
-	r, _ := http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
-	r = mux.SetURLVars(r, map[string]string{"id": id})
+	r, _ := http.NewRequest("POST", "/person", bytes.NewBuffer(bodySent))
 	w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Update(w, r)
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Create(w, r)
 
-	var body dto.Error
+	var body dto.Problem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
 
 	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.BrokenBody}, body)
+	assert.Equal(t, useful.BrokenBody, body.Detail)
 }
 
-func TestUpdateValidatingContentOnFields(t *testing.T) {
+func TestCreateDecodesBodyByContentType(t *testing.T) {
 
-	id := "5f165e2e4de9b442e60b3904"
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockRepository(ctrl)
-	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@@gmail.com", Age: 22})
 
-	r, _ := http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
-	r = mux.SetURLVars(r, map[string]string{"id": id})
-	w := httptest.NewRecorder()
+	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
+	docWithoutId := document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
+	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Update(w, r)
+	tests := []struct {
+		contentType string
+		body        string
+	}{
+		{"application/xml", "<person><name>Lucas</name><email>lucas@gmail.com</email><age>22</age></person>"},
+		{"application/yaml", "name: Lucas\nemail: lucas@gmail.com\nage: 22\n"},
+		{"application/json; charset=utf-8", `{"name": "Lucas", "email": "lucas@gmail.com", "age": 22}`},
+	}
 
-	var body dto.Error
-	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	for _, test := range tests {
+		repo.EXPECT().Create(gomock.Any(), gomock.Eq(docWithoutId)).Return(doc, nil)
 
-	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.BrokenBody}, body)
+		r, _ := http.NewRequest("POST", "/person", strings.NewReader(test.body))
+		r.Header.Set("Content-Type", test.contentType)
+		w := httptest.NewRecorder()
+
+		handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Create(w, r)
+
+		assert.Equal(t, http.StatusCreated, w.Code, test.contentType)
+	}
 }
 
-func TestUpdateValidatingID(t *testing.T) {
+func TestCreateUnsupportedMediaType(t *testing.T) {
 
-	id := "5f165e2e4de9b442e60b3"
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockRepository(ctrl)
-	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22})
 
-	r, _ := http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
-	r = mux.SetURLVars(r, map[string]string{"id": id})
+	r, _ := http.NewRequest("POST", "/person", strings.NewReader("name=Lucas"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Update(w, r)
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Create(w, r)
 
-	var body dto.Error
+	var body dto.Problem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
 
-	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.BrokenId}, body)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, useful.UnsupportedMediaType, body.Detail)
 }
 
-func TestUpdateReturningErrorWhenTryDoMapperToDocument(t *testing.T) {
+func TestUpdateUnsupportedMediaType(t *testing.T) {
 
-	id := "5f165e2e4de9b442e60b3904"
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	objID, _ := primitive.ObjectIDFromHex(id)
-	doc := dto.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
-
 	repo := mocks.NewMockRepository(ctrl)
-	mapp := mocks.NewMockMapper(ctrl)
-	mapp.EXPECT().DtoToDocument(doc).Return(document.Person{}, errors.New("mapper error"))
-
-	bodySent, _ := json.Marshal(doc)
 
-	r, _ := http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
-	r = mux.SetURLVars(r, map[string]string{"id": id})
+	r, _ := http.NewRequest("PUT", "/person/5f165e2e4de9b442e60b3904", strings.NewReader("Lucas"))
+	r.Header.Set("Content-Type", "text/plain")
+	r = mux.SetURLVars(r, map[string]string{"id": "5f165e2e4de9b442e60b3904"})
 	w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(mapp, repo).Update(w, r)
-
-	var body dto.Error
-	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Update(w, r)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.ParserError}, body)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
 }
 
-func TestUpdateReturningErrorFromDatabaseWhenTryCreate(t *testing.T) {
+func TestCreateRejectingBodies(t *testing.T) {
 
-	id := "5f165e2e4de9b442e60b3904"
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
-	docWithoutId := document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
-	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
-
 	repo := mocks.NewMockRepository(ctrl)
-	repo.EXPECT().Update(gomock.Eq(doc)).Return(int64(0), errors.New("Create error"))
 
-	bodySent, _ := json.Marshal(docWithoutId)
+	cases := []struct {
+		body    string
+		code    int
+		message string
+		field   string
+	}{
+		{`{"name": "Lucas", "email": "lucas@gmail.com", "age": 22, "nickname": "lu"}`, http.StatusBadRequest, useful.UnknownFields, "nickname"},
+		{`{"name": "Lucas", "email": "lucas@gmail.com", "age": 22} {"name": "Ana"}`, http.StatusBadRequest, useful.MultipleValues, ""},
+		{`{"name": "Lucas", "email": "lucas@gmail.com", "age": 22}]`, http.StatusBadRequest, useful.MultipleValues, ""},
+		{`{"id": "5f165e2e4de9b442e60b3904", "name": "Lucas", "email": "lucas@gmail.com", "age": 22}`, http.StatusBadRequest, useful.InvalidFields, "id"},
+		{`{"name": "` + strings.Repeat("a", 200) + `", "email": "lucas@gmail.com", "age": 22}`, http.StatusRequestEntityTooLarge, useful.BodyTooLarge, ""},
+	}
 
-	r, _ := http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
-	r = mux.SetURLVars(r, map[string]string{"id": id})
-	w := httptest.NewRecorder()
+	for _, c := range cases {
+		r, _ := http.NewRequest("POST", "/person", strings.NewReader(c.body))
+		w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Update(w, r)
+		personHandler := handler.NewPersonHandler(&mapper.PersonMapper{}, repo)
+		personHandler.MaxBodySize = 100
+		personHandler.Create(w, r)
 
-	var body dto.Error
-	_ = json.Unmarshal(w.Body.Bytes(), &body)
+		var body dto.Problem
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.UpdateError}, body)
+		assert.Equal(t, c.code, w.Code, c.body)
+		assert.Equal(t, c.message, body.Detail, c.body)
+		if c.field != "" {
+			assert.Equal(t, c.field, body.Errors[0].Field, c.body)
+		}
+	}
 }
 
-func TestUpdateReturningFromDatabaseZeroDocumentAffected(t *testing.T) {
+func TestCreateIgnoringReadOnlyFields(t *testing.T) {
 
-	id := "5f165e2e4de9b442e60b3904"
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	repo := mocks.NewMockRepository(ctrl)
+
 	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
 	docWithoutId := document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
 	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
 
-	repo := mocks.NewMockRepository(ctrl)
-	repo.EXPECT().Update(gomock.Eq(doc)).Return(int64(0), nil)
-
-	bodySent, _ := json.Marshal(docWithoutId)
+	repo.EXPECT().Create(gomock.Any(), gomock.Eq(docWithoutId)).Return(doc, nil)
 
-	r, _ := http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
-	r = mux.SetURLVars(r, map[string]string{"id": id})
+	body := `{"name": "Lucas", "email": "lucas@gmail.com", "age": 22, "createdAt": "2020-07-21T10:30:00Z", "createdBy": "someone"}`
+	r, _ := http.NewRequest("POST", "/person", strings.NewReader(body))
 	w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Update(w, r)
-
-	var body dto.Error
-	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Create(w, r)
 
-	assert.Equal(t, http.StatusNotFound, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.PersonNotFound}, body)
+	assert.Equal(t, http.StatusCreated, w.Code)
 }
 
-func TestUpdateReturningErrorWhenTryDoMapperToDTO(t *testing.T) {
+func TestUpdateRejectingAnotherId(t *testing.T) {
 
-	id := "5f165e2e4de9b442e60b3904"
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
-	pdto := dto.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
-	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
-
-	mapp := mocks.NewMockMapper(ctrl)
-	mapp.EXPECT().DtoToDocument(pdto).Return(doc, nil)
-	mapp.EXPECT().DocumentToDto(doc).Return(pdto, errors.New("Mapper error"))
-
 	repo := mocks.NewMockRepository(ctrl)
-	repo.EXPECT().Update(gomock.Eq(doc)).Return(int64(1), nil)
-
-	bodySent, _ := json.Marshal(doc)
 
-	r, _ := http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
+	id := "5f165e2e4de9b442e60b3904"
+	body := `{"id": "5f165e2e4de9b442e60b3905", "name": "Lucas", "email": "lucas@gmail.com", "age": 22}`
+	r, _ := http.NewRequest("PUT", "/person/"+id, strings.NewReader(body))
 	r = mux.SetURLVars(r, map[string]string{"id": id})
 	w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(mapp, repo).Update(w, r)
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Update(w, r)
 
-	var body dto.Error
-	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	var problem dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &problem)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.ParserError}, body)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, []dto.FieldError{{Field: "id", Rule: "readonly", Message: "id is read only and cannot be changed"}}, problem.Errors)
 }
 
-func TestDeletePersonByIdSuccess(t *testing.T) {
-
-	id := "5f165e2e4de9b442e60b3904"
-	objID, _ := primitive.ObjectIDFromHex(id)
+func TestCreateValidatingContentOnFields(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockRepository(ctrl)
+	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@@gmail.com", Age: intPointer(22)})
 
-	repo.EXPECT().Delete(gomock.Eq(objID)).Return(int64(1), nil)
-
-	r, _ := http.NewRequest("DELETE", "/person/{id}", nil)
-	r = mux.SetURLVars(r, map[string]string{"id": id})
+	r, _ := http.NewRequest("POST", "/person", bytes.NewBuffer(bodySent))
 	w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Delete(w, r)
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Create(w, r)
 
-	assert.Equal(t, http.StatusNoContent, w.Code)
-	assert.Equal(t, "\"\"", w.Body.String())
+	var body dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	expected := dto.Problem{
+		Type:     useful.ProblemInvalidFields,
+		Title:    "Bad Request",
+		Status:   http.StatusBadRequest,
+		Detail:   useful.InvalidFields,
+		Instance: "/person",
+		Errors:   []dto.FieldError{{Field: "email", Rule: "email", Message: "email must be a valid email address"}},
+	}
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, expected, body)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
 }
 
-func TestDeletePersonByIdWithBrokenId(t *testing.T) {
+func TestCreateValidatingContactsAndDocuments(t *testing.T) {
 
-	id := "5f165e2e4de9b442e60b39"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+
+	tests := []struct {
+		body     string
+		expected []dto.FieldError
+	}{
+		{`{"name": "Lucas", "email": "lucas@gmail.com"}`,
+			[]dto.FieldError{{Field: "age", Rule: "required", Message: "age is a required field"}}},
+		{`{"name": "Lucas", "email": "lucas@gmail.com", "age": 151}`,
+			[]dto.FieldError{{Field: "age", Rule: "age", Message: "age must be between 0 and 150"}}},
+		{`{"name": "Lucas", "email": "lucas@gmail.com", "birthDate": "2999-01-01"}`,
+			[]dto.FieldError{{Field: "birthDate", Rule: "past", Message: "birthDate must be a date in the past"}}},
+		{`{"name": "Lucas", "email": "lucas@gmail.com", "birthDate": "1700-01-01"}`,
+			[]dto.FieldError{{Field: "birthDate", Rule: "birthdate", Message: "birthDate must give an age between 0 and 150"}}},
+		{`{"name": "Lucas", "email": "lucas@gmail.com", "age": 22, "phones": [{"type": "mobile", "number": "+5511987654321"}, {"type": "fax", "number": "11 98765-4321"}]}`,
+			[]dto.FieldError{
+				{Field: "phones[1].type", Rule: "oneof", Message: "type must be one of [mobile home work other]"},
+				{Field: "phones[1].number", Rule: "e164", Message: "number must be a valid E.164 formatted phone number"},
+			}},
+		{`{"name": "Lucas", "email": "lucas@gmail.com", "age": 22, "addresses": [{"street": "Avenida Paulista", "country": "Brasil"}]}`,
+			[]dto.FieldError{
+				{Field: "addresses[0].city", Rule: "required", Message: "city is a required field"},
+				{Field: "addresses[0].country", Rule: "len", Message: "country must be 2 characters in length"},
+			}},
+		{`{"name": "Lucas", "email": "lucas@gmail.com", "age": 22, "documents": [{"type": "cpf", "number": "529.982.247-25"}, {"type": "cpf", "number": "111.111.111-11"}, {"type": "cpf", "number": "52998224724"}]}`,
+			[]dto.FieldError{
+				{Field: "documents[1].number", Rule: "cpf", Message: "number must be a valid CPF"},
+				{Field: "documents[2].number", Rule: "cpf", Message: "number must be a valid CPF"},
+			}},
+	}
+
+	for _, test := range tests {
+		r, _ := http.NewRequest("POST", "/person", bytes.NewBufferString(test.body))
+		w := httptest.NewRecorder()
+
+		handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Create(w, r)
+
+		var body dto.Problem
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, test.body)
+		assert.Equal(t, test.expected, body.Errors, test.body)
+	}
+}
+
+func TestCreateValidatingConfiguredRules(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockRepository(ctrl)
 
-	r, _ := http.NewRequest("DELETE", "/person/{id}", nil)
-	r = mux.SetURLVars(r, map[string]string{"id": id})
+	validator, err := handler.NewValidator(dto.ValidationRules{MinAge: 18, MaxAge: 65, MinNameLength: 3, MaxNameLength: 10, EmailDomains: []string{"example.com"}})
+	assert.NoError(t, err)
+
+	personHandler := handler.NewPersonHandler(&mapper.PersonMapper{}, repo)
+	personHandler.Validator = validator
+
+	tests := []struct {
+		body           string
+		acceptLanguage string
+		expected       []dto.FieldError
+	}{
+		{`{"name": "Lucas", "email": "lucas@example.com", "age": 17}`, "",
+			[]dto.FieldError{{Field: "age", Rule: "age", Message: "age must be between 18 and 65"}}},
+		{`{"name": "Lu", "email": "lucas@EXAMPLE.com", "age": 66}`, "pt-BR",
+			[]dto.FieldError{
+				{Field: "name", Rule: "name", Message: "name deve ter entre 3 e 10 caracteres"},
+				{Field: "age", Rule: "age", Message: "age deve estar entre 18 e 65"},
+			}},
+		{`{"name": "Lucas Ferreira", "email": "lucas@gmail.com", "age": 22}`, "es",
+			[]dto.FieldError{
+				{Field: "name", Rule: "name", Message: "name debe tener entre 3 y 10 caracteres"},
+				{Field: "email", Rule: "domain", Message: "email debe usar uno de los dominios example.com"},
+			}},
+	}
+
+	for _, test := range tests {
+		r, _ := http.NewRequest("POST", "/person", bytes.NewBufferString(test.body))
+		r.Header.Set("Accept-Language", test.acceptLanguage)
+		w := httptest.NewRecorder()
+
+		personHandler.Create(w, r)
+
+		var body dto.Problem
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, test.body)
+		assert.Equal(t, test.expected, body.Errors, test.body)
+	}
+}
+
+func TestCreateAcceptingAgeZero(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	doc := document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 0}
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Create(gomock.Any(), gomock.Eq(doc)).Return(doc, nil)
+
+	r, _ := http.NewRequest("POST", "/person", bytes.NewBufferString(`{"name": "Lucas", "email": "lucas@gmail.com", "age": 0}`))
 	w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Delete(w, r)
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Create(w, r)
 
-	var body dto.Error
+	var body dto.Person
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
 
-	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.BrokenId}, body)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, intPointer(0), body.Age)
 }
 
-func TestDeletePersonByIdWithErrorReturnedFromRepository(t *testing.T) {
+func TestNewValidatorRejectingInvalidRules(t *testing.T) {
+
+	tests := []dto.ValidationRules{
+		{MinAge: -1, MaxAge: 150, MinNameLength: 1, MaxNameLength: 100},
+		{MinAge: 30, MaxAge: 20, MinNameLength: 1, MaxNameLength: 100},
+		{MinAge: 0, MaxAge: 150, MinNameLength: 1, MaxNameLength: 0},
+		{MinAge: 0, MaxAge: 150, MinNameLength: 10, MaxNameLength: 5},
+	}
+
+	for _, rules := range tests {
+		_, err := handler.NewValidator(rules)
+		assert.Error(t, err, fmt.Sprint(rules))
+	}
+}
+
+func TestFindWithAgesOutOfConfiguredRules(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	validator, _ := handler.NewValidator(dto.ValidationRules{MinAge: 18, MaxAge: 65, MinNameLength: 1, MaxNameLength: 100})
+
+	personHandler := handler.NewPersonHandler(&mapper.PersonMapper{}, mocks.NewMockRepository(ctrl))
+	personHandler.Validator = validator
+
+	for _, rawQuery := range []string{"minAge=17", "maxAge=66"} {
+		r, _ := http.NewRequest("GET", "/v1/person?"+rawQuery, nil)
+		w := httptest.NewRecorder()
+
+		personHandler.Find(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, rawQuery)
+	}
+}
+
+func TestRulesSuccess(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rules := dto.ValidationRules{MinAge: 18, MaxAge: 65, MinNameLength: 3, MaxNameLength: 10, EmailDomains: []string{"example.com"}}
+	validator, _ := handler.NewValidator(rules)
+
+	personHandler := handler.NewPersonHandler(&mapper.PersonMapper{}, mocks.NewMockRepository(ctrl))
+	personHandler.Validator = validator
+
+	r, _ := http.NewRequest("GET", "/person/rules", nil)
+	w := httptest.NewRecorder()
+
+	personHandler.Rules(w, r)
+
+	var body dto.ValidationRules
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, rules, body)
+}
+
+func TestCreateComputingAgeFromBirthDate(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+
+	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
+	birthDate := time.Now().UTC().Truncate(24*time.Hour).AddDate(-30, 0, 0)
+	phones := []document.Phone{{Type: "mobile", Number: "+5511987654321"}}
+	docWithoutId := document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 30, BirthDate: &birthDate, Phones: phones}
+	doc := docWithoutId
+	doc.Id = objID
+	doc.Age = 18
+
+	repo.EXPECT().Create(gomock.Any(), gomock.Eq(docWithoutId)).Return(doc, nil)
+
+	bodySent := fmt.Sprintf(`{"name": "Lucas", "email": "lucas@gmail.com", "birthDate": %q, "phones": [{"type": "mobile", "number": "+5511987654321"}]}`,
+		birthDate.Format(dto.DateLayout))
+
+	r, _ := http.NewRequest("POST", "/person", bytes.NewBufferString(bodySent))
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Create(w, r)
+
+	var body map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, float64(30), body["age"])
+	assert.Equal(t, birthDate.Format(dto.DateLayout), body["birthDate"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"type": "mobile", "number": "+5511987654321"}}, body["phones"])
+}
+
+func TestCreateNormalizingBodyBeforeValidating(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	doc := document.Person{Name: "João da Silva", Email: "john@example.com", Age: 22}
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Create(gomock.Any(), gomock.Eq(doc)).Return(doc, nil)
+
+	normalizer, _ := normalize.New(map[string][]string{"name": {"trim", "collapse", "nfc", "title"}, "email": {"trim", "lowercase"}})
+	personHandler := handler.NewPersonHandler(&mapper.PersonMapper{}, repo)
+	personHandler.Normalizer = normalizer
+
+	bodySent, _ := json.Marshal(dto.Person{Name: "  joa\u0303o   DA silva ", Email: "  JOHN@Example.COM ", Age: intPointer(22)})
+
+	r, _ := http.NewRequest("POST", "/person", bytes.NewBuffer(bodySent))
+	w := httptest.NewRecorder()
+
+	personHandler.Create(w, r)
+
+	var body dto.Person
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "João da Silva", body.Name)
+	assert.Equal(t, "john@example.com", body.Email)
+}
+
+func TestCreateReturningErrorFromDatabaseWhenTryCreate(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	doc := document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Create(gomock.Any(), gomock.Eq(doc)).Return(document.Person{}, errors.New("Create error"))
+
+	bodySent, _ := json.Marshal(dto.Person{Name: doc.Name, Email: doc.Email, Age: intPointer(doc.Age)})
+
+	r, _ := http.NewRequest("POST", "/person", bytes.NewBuffer(bodySent))
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Create(w, r)
+
+	var body dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, useful.CreateError, body.Detail)
+}
+
+func TestUpdateSuccess(t *testing.T) {
 
 	id := "5f165e2e4de9b442e60b3904"
-	objID, _ := primitive.ObjectIDFromHex(id)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+
+	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
+	docWithoutId := document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
+	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
+
+	repo.EXPECT().Patch(gomock.Any(), gomock.Eq(doc), gomock.Eq(updatedFields)).Return(doc, nil)
+
+	bodySent, _ := json.Marshal(dto.Person{Name: docWithoutId.Name, Email: docWithoutId.Email, Age: intPointer(docWithoutId.Age)})
+
+	r, _ := http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Update(w, r)
+
+	var body dto.Person
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, doc.Id, body.Id)
+	assert.Equal(t, doc.Name, body.Name)
+	assert.Equal(t, doc.Email, body.Email)
+	assert.Equal(t, doc.Age, *body.Age)
+}
+
+func TestUpdateBrokenBody(t *testing.T) {
 
+	id := "5f165e2e4de9b442e60b3904"
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockRepository(ctrl)
+	bodySent, _ := json.Marshal(struct {
+		Id   int
+		Text string
+	}{123, "Broked"})
+
+	r, _ := http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
 
-	repo.EXPECT().Delete(gomock.Eq(objID)).Return(int64(0), errors.New("Error"))
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Update(w, r)
 
-	r, _ := http.NewRequest("DELETE", "/person/{id}", nil)
+	var body dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, useful.BrokenBody, body.Detail)
+}
+
+func TestUpdateValidatingContentOnFields(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@@gmail.com", Age: intPointer(22)})
+
+	r, _ := http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
 	r = mux.SetURLVars(r, map[string]string{"id": id})
 	w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Delete(w, r)
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Update(w, r)
 
-	var body dto.Error
+	var body dto.Problem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.DeleteError}, body)
+	assert.Equal(t, useful.InvalidFields, body.Detail)
+	assert.Equal(t, []dto.FieldError{{Field: "email", Rule: "email", Message: "email must be a valid email address"}}, body.Errors)
 }
 
-func TestDeletePersonByIdReturningZeroAffected(t *testing.T) {
+func TestUpdateValidatingID(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: intPointer(22)})
+
+	r, _ := http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Update(w, r)
+
+	var body dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, useful.BrokenId, body.Detail)
+}
+
+func TestUpdateReturningErrorFromDatabaseWhenTryCreate(t *testing.T) {
 
 	id := "5f165e2e4de9b442e60b3904"
-	objID, _ := primitive.ObjectIDFromHex(id)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
+	docWithoutId := document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
+	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Patch(gomock.Any(), gomock.Eq(doc), gomock.Eq(updatedFields)).Return(document.Person{}, errors.New("Create error"))
+
+	bodySent, _ := json.Marshal(dto.Person{Name: docWithoutId.Name, Email: docWithoutId.Email, Age: intPointer(docWithoutId.Age)})
+
+	r, _ := http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Update(w, r)
+
+	var body dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, useful.UpdateError, body.Detail)
+}
+
+func TestUpdateReturningFromDatabaseZeroDocumentAffected(t *testing.T) {
 
+	id := "5f165e2e4de9b442e60b3904"
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
+	docWithoutId := document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
+	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
+
 	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Patch(gomock.Any(), gomock.Eq(doc), gomock.Eq(updatedFields)).Return(document.Person{}, repository.ErrNotFound)
 
-	repo.EXPECT().Delete(gomock.Eq(objID)).Return(int64(0), nil)
+	bodySent, _ := json.Marshal(dto.Person{Name: docWithoutId.Name, Email: docWithoutId.Email, Age: intPointer(docWithoutId.Age)})
 
-	r, _ := http.NewRequest("DELETE", "/person/{id}", nil)
+	r, _ := http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
 	r = mux.SetURLVars(r, map[string]string{"id": id})
 	w := httptest.NewRecorder()
 
-	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Delete(w, r)
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Update(w, r)
 
-	var body dto.Error
+	var body dto.Problem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
-	assert.Equal(t, dto.Error{Message: useful.PersonNotFound}, body)
+	assert.Equal(t, useful.PersonNotFound, body.Detail)
+}
+
+func TestPatchWithMergePatchSetsOnlyChangedFields(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
+	patched := document.Person{Id: objID, Name: "Lucas", Email: "new@gmail.com", Age: 22}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().FindById(gomock.Any(), gomock.Eq(id)).Return(doc, nil)
+	repo.EXPECT().Patch(gomock.Any(), gomock.Eq(patched), gomock.Eq([]string{"email"})).Return(patched, nil)
+
+	r, _ := http.NewRequest("PATCH", "/person/{id}", bytes.NewBufferString(`{"email": "new@gmail.com"}`))
+	r.Header.Set("Content-Type", handler.MergePatch)
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Patch(w, r)
+
+	var body dto.Person
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, patched.Id, body.Id)
+	assert.Equal(t, patched.Email, body.Email)
+	assert.Equal(t, patched.Name, body.Name)
+	assert.Equal(t, patched.Age, *body.Age)
+}
+
+func TestPatchWithJsonPatch(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
+	patched := document.Person{Id: objID, Name: "Lucas Silva", Email: "lucas@gmail.com", Age: 23}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().FindById(gomock.Any(), gomock.Eq(id)).Return(doc, nil)
+	repo.EXPECT().Patch(gomock.Any(), gomock.Eq(patched), gomock.Eq([]string{"name", "age"})).Return(patched, nil)
+
+	patch := `[{"op": "test", "path": "/age", "value": 22}, {"op": "replace", "path": "/age", "value": 23}, {"op": "add", "path": "/name", "value": "Lucas Silva"}]`
+	r, _ := http.NewRequest("PATCH", "/person/{id}", bytes.NewBufferString(patch))
+	r.Header.Set("Content-Type", handler.JsonPatch)
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Patch(w, r)
+
+	var body dto.Person
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, patched.Name, body.Name)
+	assert.Equal(t, patched.Age, *body.Age)
+}
+
+func TestPatchRejectingInvalidRequests(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22}
+
+	cases := []struct {
+		contentType string
+		patch       string
+		code        int
+		message     string
+	}{
+		{"application/json", `{"email": "new@gmail.com"}`, http.StatusUnsupportedMediaType, useful.UnsupportedPatch},
+		{handler.MergePatch, `{"email": `, http.StatusUnprocessableEntity, useful.BrokenPatch},
+		{handler.MergePatch, `{"age": "old"}`, http.StatusUnprocessableEntity, useful.BrokenPatch},
+		{handler.MergePatch, `{"unknownField": 1}`, http.StatusBadRequest, useful.UnknownFields},
+		{handler.JsonPatch, `[{"op": "add", "path": "/unknownField", "value": 1}]`, http.StatusBadRequest, useful.UnknownFields},
+		{handler.JsonPatch, `[{"op": "test", "path": "/age", "value": 30}]`, http.StatusUnprocessableEntity, useful.BrokenPatch},
+		{handler.MergePatch, `{"email": "lucas@@gmail.com"}`, http.StatusBadRequest, useful.InvalidFields},
+		{handler.JsonPatch, `[{"op": "remove", "path": "/name"}]`, http.StatusBadRequest, useful.InvalidFields},
+		{handler.MergePatch, `{"id": "5f165e2e4de9b442e60b3905"}`, http.StatusBadRequest, useful.InvalidFields},
+		{handler.JsonPatch, `[{"op": "replace", "path": "/id", "value": "5f165e2e4de9b442e60b3905"}]`, http.StatusBadRequest, useful.InvalidFields},
+	}
+
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+
+		repo := mocks.NewMockRepository(ctrl)
+		repo.EXPECT().FindById(gomock.Any(), gomock.Eq(id)).Return(doc, nil)
+
+		r, _ := http.NewRequest("PATCH", "/person/{id}", bytes.NewBufferString(c.patch))
+		r.Header.Set("Content-Type", c.contentType)
+		r = mux.SetURLVars(r, map[string]string{"id": id})
+		w := httptest.NewRecorder()
+
+		handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Patch(w, r)
+
+		var body dto.Problem
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+		assert.Equal(t, c.code, w.Code, c.patch)
+		assert.Equal(t, c.message, body.Detail, c.patch)
+		ctrl.Finish()
+	}
+}
+
+func TestPatchReturningNotFound(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().FindById(gomock.Any(), gomock.Eq(id)).Return(document.Person{}, errors.New("Find error"))
+
+	r, _ := http.NewRequest("PATCH", "/person/{id}", bytes.NewBufferString(`{"age": 30}`))
+	r.Header.Set("Content-Type", handler.MergePatch)
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Patch(w, r)
+
+	var body dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, useful.PersonNotFound, body.Detail)
+}
+
+func TestDeletePersonByIdSuccess(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+
+	repo.EXPECT().Delete(gomock.Any(), gomock.Eq(objID), gomock.Eq(int64(0))).Return(int64(1), nil)
+
+	r, _ := http.NewRequest("DELETE", "/person/{id}", nil)
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Delete(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "\"\"", w.Body.String())
+}
+
+func TestDeletePersonByIdWithBrokenId(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b39"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+
+	r, _ := http.NewRequest("DELETE", "/person/{id}", nil)
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Delete(w, r)
+
+	var body dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, useful.BrokenId, body.Detail)
+}
+
+func TestDeletePersonByIdWithErrorReturnedFromRepository(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+
+	repo.EXPECT().Delete(gomock.Any(), gomock.Eq(objID), gomock.Eq(int64(0))).Return(int64(0), errors.New("Error"))
+
+	r, _ := http.NewRequest("DELETE", "/person/{id}", nil)
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Delete(w, r)
+
+	var body dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, useful.DeleteError, body.Detail)
+}
+
+func TestDeletePersonByIdReturningZeroAffected(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+
+	repo.EXPECT().Delete(gomock.Any(), gomock.Eq(objID), gomock.Eq(int64(0))).Return(int64(0), nil)
+
+	r, _ := http.NewRequest("DELETE", "/person/{id}", nil)
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Delete(w, r)
+
+	var body dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, useful.PersonNotFound, body.Detail)
+}
+
+func TestFindReturningClientClosedRequestWhenContextIsCanceled(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	repo.EXPECT().Find(gomock.Eq(ctx), gomock.Any()).Return(nil, int64(0), context.Canceled)
+
+	r, _ := http.NewRequestWithContext(ctx, "GET", "/person", nil)
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Find(w, r)
+
+	var body dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, handler.StatusClientClosedRequest, w.Code)
+	assert.Equal(t, useful.RequestCanceled, body.Detail)
+}
+
+func TestFindByIdReturningGatewayTimeoutWhenDeadlineExceeded(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+
+	repo.EXPECT().FindById(gomock.Any(), gomock.Eq(id)).Return(document.Person{}, context.DeadlineExceeded)
+
+	r, _ := http.NewRequest("GET", "/person/{id}", nil)
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).FindById(w, r)
+
+	var body dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Equal(t, useful.RequestTimeout, body.Detail)
+}
+
+func TestUpdateReturningGatewayTimeoutWhenDeadlineExceeded(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(document.Person{}, context.DeadlineExceeded)
+
+	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: intPointer(22)})
+
+	r, _ := http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Update(w, r)
+
+	var body dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Equal(t, useful.RequestTimeout, body.Detail)
+}
+
+func TestFindByIdSettingETagAndAnsweringIfNoneMatch(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22, Version: 3}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().FindById(gomock.Any(), gomock.Eq(id)).Return(doc, nil).Times(3)
+
+	personHandler := handler.NewPersonHandler(&mapper.PersonMapper{}, repo)
+
+	r, _ := http.NewRequest("GET", "/person/{id}", nil)
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+	personHandler.FindById(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	r.Header.Set("If-None-Match", `"3"`)
+	w = httptest.NewRecorder()
+	personHandler.FindById(w, r)
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.Empty(t, w.Body.String())
+
+	r.Header.Set("If-None-Match", `"2"`)
+	w = httptest.NewRecorder()
+	personHandler.FindById(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCreateSettingETag(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22, Version: 1}, nil)
+
+	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: intPointer(22)})
+	r, _ := http.NewRequest("POST", "/person", bytes.NewBuffer(bodySent))
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Create(w, r)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+}
+
+func TestUpdateWithIfMatch(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+	expected := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22, Version: 3}
+	updated := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22, Version: 4}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().Patch(gomock.Any(), gomock.Eq(expected), gomock.Eq(updatedFields)).Return(updated, nil),
+		repo.EXPECT().Patch(gomock.Any(), gomock.Eq(expected), gomock.Eq(updatedFields)).Return(document.Person{}, repository.ErrVersionMismatch),
+	)
+
+	personHandler := handler.NewPersonHandler(&mapper.PersonMapper{}, repo)
+	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: intPointer(22)})
+
+	for _, c := range []struct {
+		ifMatch string
+		code    int
+		etag    string
+	}{
+		{`"3"`, http.StatusOK, `"4"`},
+		{`W/"3"`, http.StatusPreconditionFailed, ""},
+		{`"abc"`, http.StatusPreconditionFailed, ""},
+	} {
+		r, _ := http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
+		r.Header.Set("If-Match", c.ifMatch)
+		r = mux.SetURLVars(r, map[string]string{"id": id})
+		w := httptest.NewRecorder()
+
+		personHandler.Update(w, r)
+
+		assert.Equal(t, c.code, w.Code, c.ifMatch)
+		assert.Equal(t, c.etag, w.Header().Get("ETag"), c.ifMatch)
+
+		if c.code == http.StatusPreconditionFailed {
+			var body dto.Problem
+			_ = json.Unmarshal(w.Body.Bytes(), &body)
+			assert.Equal(t, useful.VersionMismatch, body.Detail)
+		}
+	}
+}
+
+func TestPatchWithIfMatch(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22, Version: 3}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().FindById(gomock.Any(), gomock.Eq(id)).Return(doc, nil).Times(2)
+	repo.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(document.Person{}, repository.ErrVersionMismatch)
+
+	personHandler := handler.NewPersonHandler(&mapper.PersonMapper{}, repo)
+
+	for _, ifMatch := range []string{`"2"`, `"3"`} {
+		r, _ := http.NewRequest("PATCH", "/person/{id}", bytes.NewBufferString(`{"age": 30}`))
+		r.Header.Set("Content-Type", handler.MergePatch)
+		r.Header.Set("If-Match", ifMatch)
+		r = mux.SetURLVars(r, map[string]string{"id": id})
+		w := httptest.NewRecorder()
+
+		personHandler.Patch(w, r)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code, ifMatch)
+	}
+}
+
+func TestDeleteWithIfMatch(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Delete(gomock.Any(), gomock.Eq(objID), gomock.Eq(int64(2))).Return(int64(0), repository.ErrVersionMismatch)
+
+	r, _ := http.NewRequest("DELETE", "/person/{id}", nil)
+	r.Header.Set("If-Match", `"2"`)
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Delete(w, r)
+
+	var body dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, useful.VersionMismatch, body.Detail)
+}
+
+func TestCreateAndUpdateReturningConflictForDuplicateEmail(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	existing := primitive.NewObjectID()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(document.Person{}, repository.DuplicateEmailError{Id: existing})
+	repo.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(document.Person{}, repository.DuplicateEmailError{Id: existing})
+
+	personHandler := handler.NewPersonHandler(&mapper.PersonMapper{}, repo)
+	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: intPointer(22)})
+
+	r, _ := http.NewRequest("POST", "/person", bytes.NewBuffer(bodySent))
+	w := httptest.NewRecorder()
+	personHandler.Create(w, r)
+
+	var body dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, useful.DuplicateEmail, body.Detail)
+	assert.Equal(t, existing.Hex(), body.Id)
+
+	r, _ = http.NewRequest("PUT", "/person", bytes.NewBuffer(bodySent))
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w = httptest.NewRecorder()
+	personHandler.Update(w, r)
+
+	body = dto.Problem{}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, useful.DuplicateEmail, body.Detail)
+	assert.Equal(t, existing.Hex(), body.Id)
+}
+
+func TestCreateReturningConflictWhenTheEmailOwnerIsGone(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(document.Person{}, repository.DuplicateEmailError{})
+
+	personHandler := handler.NewPersonHandler(&mapper.PersonMapper{}, repo)
+	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: intPointer(22)})
+
+	r, _ := http.NewRequest("POST", "/person", bytes.NewBuffer(bodySent))
+	w := httptest.NewRecorder()
+	personHandler.Create(w, r)
+
+	var body dto.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, useful.DuplicateEmail, body.Detail)
+	assert.Empty(t, body.Id)
+}
+
+func TestFindIncludingDeleted(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+
+	query := repository.Query{Page: repository.DefaultPage, Size: repository.DefaultSize, IncludeDeleted: true}
+	repo.EXPECT().Find(gomock.Any(), gomock.Eq(query)).Return(nil, int64(0), nil)
+
+	r, _ := http.NewRequest("GET", "/v1/person?includeDeleted=true", nil)
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Find(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	r, _ = http.NewRequest("GET", "/v1/person?includeDeleted=maybe", nil)
+	w = httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Find(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFindUpdatedSince(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+
+	since := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	query := repository.Query{Page: repository.DefaultPage, Size: repository.DefaultSize, UpdatedSince: &since}
+	repo.EXPECT().Find(gomock.Any(), gomock.Eq(query)).Return(nil, int64(0), nil)
+
+	r, _ := http.NewRequest("GET", "/v1/person?updatedSince=2020-06-01T12:00:00Z", nil)
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Find(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRestoreSuccess(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22, Version: 3}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Restore(gomock.Any(), gomock.Eq(objID)).Return(doc, nil)
+
+	r, _ := http.NewRequest("POST", "/person/{id}/restore", nil)
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Restore(w, r)
+
+	var body dto.Person
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.Equal(t, doc.Id, body.Id)
+	assert.Equal(t, doc.Name, body.Name)
+}
+
+func TestRestoreFailures(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().Restore(gomock.Any(), gomock.Eq(objID)).Return(document.Person{}, repository.ErrNotFound),
+		repo.EXPECT().Restore(gomock.Any(), gomock.Eq(objID)).Return(document.Person{}, errors.New("Restore error")),
+	)
+
+	for _, c := range []struct {
+		id      string
+		code    int
+		message string
+	}{
+		{"5f165e2e4de9b442e60b39", http.StatusBadRequest, useful.BrokenId},
+		{id, http.StatusNotFound, useful.PersonNotFound},
+		{id, http.StatusInternalServerError, useful.RestoreError},
+	} {
+		r, _ := http.NewRequest("POST", "/person/{id}/restore", nil)
+		r = mux.SetURLVars(r, map[string]string{"id": c.id})
+		w := httptest.NewRecorder()
+
+		handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Restore(w, r)
+
+		var body dto.Problem
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+		assert.Equal(t, c.code, w.Code)
+		assert.Equal(t, c.message, body.Detail)
+	}
+}
+
+func TestHistorySuccess(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+	revisions := []document.Revision{
+		{PersonId: objID, Version: 2, Operation: document.Updated, Actor: "ana",
+			Before: &document.Person{Id: objID, Name: "Lucas", Version: 1},
+			After:  &document.Person{Id: objID, Name: "Lucas Silva", Version: 2}},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	query := repository.Query{Page: 2, Size: 1}
+	repo.EXPECT().History(gomock.Any(), gomock.Eq(objID), gomock.Eq(query)).Return(revisions, int64(3), nil)
+
+	r, _ := http.NewRequest("GET", "/v1/person/"+id+"/history?page=2&size=1", nil)
+	r = mux.SetURLVars(r, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).History(w, r)
+
+	var page dto.RevisionPage
+	_ = json.Unmarshal(w.Body.Bytes(), &page)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(3), page.Total)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, "Lucas", page.Data[0].Before.Name)
+	assert.Equal(t, "Lucas Silva", page.Data[0].After.Name)
+	assert.Equal(t, "ana", page.Data[0].Actor)
+	assert.Contains(t, page.Next, "page=3")
+	assert.Contains(t, page.Prev, "page=1")
+}
+
+func TestHistoryFailures(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().History(gomock.Any(), gomock.Eq(objID), gomock.Any()).Return(nil, int64(0), nil),
+		repo.EXPECT().History(gomock.Any(), gomock.Eq(objID), gomock.Any()).Return(nil, int64(0), errors.New("History error")),
+	)
+
+	for _, c := range []struct {
+		id      string
+		query   string
+		code    int
+		message string
+	}{
+		{"5f165e2e4de9b442e60b39", "", http.StatusBadRequest, useful.BrokenId},
+		{id, "?size=1000", http.StatusBadRequest, useful.BrokenQuery},
+		{id, "", http.StatusNotFound, useful.PersonNotFound},
+		{id, "", http.StatusInternalServerError, useful.InternalErrorOccurred},
+	} {
+		r, _ := http.NewRequest("GET", "/v1/person/"+c.id+"/history"+c.query, nil)
+		r = mux.SetURLVars(r, map[string]string{"id": c.id})
+		w := httptest.NewRecorder()
+
+		handler.NewPersonHandler(&mapper.PersonMapper{}, repo).History(w, r)
+
+		var body dto.Problem
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+		assert.Equal(t, c.code, w.Code)
+		assert.Equal(t, c.message, body.Detail)
+	}
+}
+
+func TestFindByIdAsOf(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+	asOf := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	doc := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22, Version: 1}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().FindAsOf(gomock.Any(), gomock.Eq(objID), gomock.Eq(asOf)).Return(doc, nil),
+		repo.EXPECT().FindAsOf(gomock.Any(), gomock.Eq(objID), gomock.Eq(asOf)).Return(document.Person{}, repository.ErrNotFound),
+	)
+
+	for _, c := range []struct {
+		query string
+		code  int
+	}{
+		{"asOf=2020-06-01T12:00:00Z", http.StatusOK},
+		{"asOf=2020-06-01T12:00:00Z", http.StatusNotFound},
+		{"asOf=yesterday", http.StatusBadRequest},
+		{"asOf=", http.StatusBadRequest},
+	} {
+		r, _ := http.NewRequest("GET", "/v1/person/"+id+"?"+c.query, nil)
+		r = mux.SetURLVars(r, map[string]string{"id": id})
+		w := httptest.NewRecorder()
+
+		handler.NewPersonHandler(&mapper.PersonMapper{}, repo).FindById(w, r)
+
+		assert.Equal(t, c.code, w.Code, c.query)
+
+		if c.code == http.StatusOK {
+			var body dto.Person
+			_ = json.Unmarshal(w.Body.Bytes(), &body)
+			assert.Equal(t, doc.Name, body.Name)
+		}
+	}
+}
+
+func TestRevertSuccess(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+	snapshot := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22, Version: 1}
+	reverted := document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22, Version: 4}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().FindRevision(gomock.Any(), gomock.Eq(objID), gomock.Eq(int64(1))).
+		Return(document.Revision{PersonId: objID, Version: 1, Operation: document.Created, After: &snapshot}, nil)
+	repo.EXPECT().Update(gomock.Any(), gomock.Eq(document.Person{Id: objID, Name: "Lucas", Email: "lucas@gmail.com", Age: 22, Version: 3})).
+		Return(reverted, nil)
+
+	r, _ := http.NewRequest("POST", "/v1/person/"+id+"/revisions/1/revert", nil)
+	r.Header.Set("If-Match", `"3"`)
+	r = mux.SetURLVars(r, map[string]string{"id": id, "rev": "1"})
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Revert(w, r)
+
+	var body dto.Person
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	assert.Equal(t, reverted.Id, body.Id)
+}
+
+func TestRevertFailures(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+	invalid := document.Person{Id: objID, Email: "lucas@gmail.com", Age: 22, Version: 1}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().FindRevision(gomock.Any(), gomock.Eq(objID), gomock.Eq(int64(9))).Return(document.Revision{}, repository.ErrNotFound),
+		repo.EXPECT().FindRevision(gomock.Any(), gomock.Eq(objID), gomock.Eq(int64(9))).Return(document.Revision{}, errors.New("Find error")),
+		repo.EXPECT().FindRevision(gomock.Any(), gomock.Eq(objID), gomock.Eq(int64(1))).Return(document.Revision{After: &invalid}, nil),
+	)
+
+	for _, c := range []struct {
+		id      string
+		rev     string
+		code    int
+		message string
+	}{
+		{"5f165e2e4de9b442e60b39", "1", http.StatusUnprocessableEntity, useful.BrokenId},
+		{id, "0", http.StatusBadRequest, useful.BrokenRevision},
+		{id, "one", http.StatusBadRequest, useful.BrokenRevision},
+		{id, "9", http.StatusNotFound, useful.RevisionNotFound},
+		{id, "9", http.StatusInternalServerError, useful.InternalErrorOccurred},
+		{id, "1", http.StatusBadRequest, useful.InvalidFields},
+	} {
+		r, _ := http.NewRequest("POST", "/v1/person/"+c.id+"/revisions/"+c.rev+"/revert", nil)
+		r = mux.SetURLVars(r, map[string]string{"id": c.id, "rev": c.rev})
+		w := httptest.NewRecorder()
+
+		handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Revert(w, r)
+
+		var body dto.Problem
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+		assert.Equal(t, c.code, w.Code, c.rev)
+		assert.Equal(t, c.message, body.Detail, c.rev)
+	}
+}
+
+func TestBulkSuccess(t *testing.T) {
+
+	id := "5f165e2e4de9b442e60b3904"
+	objID, _ := primitive.ObjectIDFromHex(id)
+	createdID := primitive.NewObjectID()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Bulk(gomock.Any(), gomock.Eq([]repository.BulkOperation{
+		{Operation: document.Created, Person: document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22}},
+		{Operation: document.Updated, Person: document.Person{Id: objID, Name: "Ana", Email: "ana@gmail.com", Age: 30, Version: 2}, Fields: updatedFields},
+		{Operation: document.Deleted, Person: document.Person{Id: objID}},
+	}), gomock.Eq(false)).Return([]repository.BulkResult{
+		{Person: document.Person{Id: createdID, Version: 1}},
+		{Person: document.Person{Id: objID}, Err: repository.ErrVersionMismatch},
+		{Person: document.Person{Id: objID}},
+	}, nil)
+
+	body := `[
+		{"op": "create", "person": {"name": "Lucas", "email": "lucas@gmail.com", "age": 22}},
+		{"op": "create", "person": {"name": "Lucas", "email": "lucas", "age": 22}},
+		{"op": "update", "id": "` + id + `", "version": 2, "person": {"name": "Ana", "email": "ana@gmail.com", "age": 30}},
+		{"op": "delete", "id": "` + id + `"},
+		{"op": "upsert", "id": "` + id + `"}
+	]`
+	r, _ := http.NewRequest("POST", "/v1/person/_bulk?ordered=false", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Bulk(w, r)
+
+	var results []dto.BulkResult
+	_ = json.Unmarshal(w.Body.Bytes(), &results)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, results, 5)
+	assert.Equal(t, dto.BulkResult{Status: http.StatusCreated, Id: createdID.Hex(), Version: 1}, results[0])
+	assert.Equal(t, http.StatusBadRequest, results[1].Status)
+	assert.Equal(t, useful.InvalidFields, results[1].Message)
+	assert.Equal(t, "email", results[1].Fields[0].Field)
+	assert.Equal(t, dto.BulkResult{Status: http.StatusPreconditionFailed, Id: id, Message: useful.VersionMismatch}, results[2])
+	assert.Equal(t, dto.BulkResult{Status: http.StatusNoContent, Id: id}, results[3])
+	assert.Equal(t, http.StatusBadRequest, results[4].Status)
+	assert.Equal(t, id, results[4].Id)
+}
+
+func TestBulkWithV2Mapper(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Bulk(gomock.Any(), gomock.Eq([]repository.BulkOperation{
+		{Operation: document.Created, Person: document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22}},
+	}), gomock.Eq(true)).Return([]repository.BulkResult{{Person: document.Person{Id: primitive.NewObjectID(), Version: 1}}}, nil)
+	repo.EXPECT().Bulk(gomock.Any(), gomock.Len(1), gomock.Eq(false)).Return([]repository.BulkResult{{}}, nil)
+
+	personHandler := handler.NewPersonHandler(&mapper.PersonMapperV2{}, repo)
+
+	r, _ := http.NewRequest("POST", "/v1/person/_bulk", bytes.NewBufferString(`[{"op": "create", "person": {"name": "Lucas", "email": "lucas@gmail.com", "age": 22}}]`))
+	w := httptest.NewRecorder()
+
+	personHandler.Bulk(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code, "bulk takes v1 people whatever the mapper")
+
+	r, _ = http.NewRequest("POST", "/v1/person/import", bytes.NewBufferString("name,email,age\nLucas,lucas@gmail.com,22\n"))
+	r.Header.Set("Content-Type", "text/csv")
+	w = httptest.NewRecorder()
+
+	personHandler.Import(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code, "import takes v1 people whatever the mapper")
+}
+
+func TestBulkOrderedStopsAtInvalidOperation(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Bulk(gomock.Any(), gomock.Len(1), gomock.Eq(true)).Return([]repository.BulkResult{
+		{Err: repository.DuplicateEmailError{Id: primitive.NewObjectID()}},
+	}, nil)
+
+	body := `[
+		{"op": "create", "person": {"name": "Lucas", "email": "lucas@gmail.com", "age": 22}},
+		{"op": "update", "person": {"name": "Lucas", "email": "lucas@gmail.com", "age": 22}},
+		{"op": "create", "person": {"name": "Ana", "email": "ana@gmail.com", "age": 30}}
+	]`
+	r, _ := http.NewRequest("POST", "/v1/person/_bulk", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Bulk(w, r)
+
+	var results []dto.BulkResult
+	_ = json.Unmarshal(w.Body.Bytes(), &results)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusConflict, results[0].Status)
+	assert.Equal(t, useful.DuplicateEmail, results[0].Message)
+	assert.Equal(t, http.StatusBadRequest, results[1].Status)
+	assert.Equal(t, "id is required", results[1].Error)
+	assert.Equal(t, dto.BulkResult{Status: http.StatusFailedDependency, Message: useful.BulkSkipped}, results[2])
+}
+
+func TestBulkFailures(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Bulk(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("Bulk error"))
+
+	tooMany, _ := json.Marshal(make([]dto.BulkOperation, repository.MaxBulkSize+1))
+	create := `[{"op": "create", "person": {"name": "Lucas", "email": "lucas@gmail.com", "age": 22}}]`
+
+	for _, c := range []struct {
+		query   string
+		body    string
+		code    int
+		message string
+	}{
+		{"", `{"op": "create"}`, http.StatusUnprocessableEntity, useful.BrokenBody},
+		{"", `[{"op": "create", "person": {"name": "Lucas", "email": "lucas@gmail.com", "age": 22, "unknownField": 1}}]`, http.StatusBadRequest, useful.UnknownFields},
+		{"", `[{"op": "create", "unknownField": 1}]`, http.StatusBadRequest, useful.UnknownFields},
+		{"?ordered=sometimes", `[]`, http.StatusBadRequest, useful.BrokenQuery},
+		{"", string(tooMany), http.StatusRequestEntityTooLarge, useful.BulkTooLarge},
+		{"", create, http.StatusInternalServerError, useful.BulkError},
+	} {
+		r, _ := http.NewRequest("POST", "/v1/person/_bulk"+c.query, bytes.NewBufferString(c.body))
+		w := httptest.NewRecorder()
+
+		handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Bulk(w, r)
+
+		var body dto.Problem
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+		assert.Equal(t, c.code, w.Code)
+		assert.Equal(t, c.message, body.Detail)
+	}
+}
+
+func TestFindAsCsv(t *testing.T) {
+
+	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
+	createdAt := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	people := []document.Person{
+		{Id: objID, Name: "Lucas, Silva", Email: "lucas@gmail.com", Age: 22, CreatedAt: &createdAt, CreatedBy: "ana"},
+		{Id: objID, Name: "Ana", Email: "ana@gmail.com", Age: 30},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	query := repository.Query{Page: repository.DefaultPage, Size: repository.DefaultSize, Name: "a"}
+	repo.EXPECT().Stream(gomock.Any(), gomock.Eq(query), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, query repository.Query, fn func(document.Person) error) error {
+			for _, person := range people {
+				if err := fn(person); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	r, _ := http.NewRequest("GET", "/v1/person?name=a", nil)
+	r.Header.Set("Accept", "text/csv, application/json;q=0.5")
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Find(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "id,name,email,age,createdAt,updatedAt,createdBy,updatedBy,deletedAt,birthDate\n"+
+		"5f165e2e4de9b442e60b3904,\"Lucas, Silva\",lucas@gmail.com,22,2020-06-01T12:00:00Z,,ana,,,\n"+
+		"5f165e2e4de9b442e60b3904,Ana,ana@gmail.com,30,,,,,,\n", w.Body.String())
+}
+
+func TestFindAsCsvFailures(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().Stream(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
+		repo.EXPECT().Stream(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("Stream error")),
+	)
+
+	for _, c := range []struct {
+		code int
+		body string
+	}{
+		{http.StatusOK, "id,name,email,age,createdAt,updatedAt,createdBy,updatedBy,deletedAt,birthDate\n"},
+		{http.StatusInternalServerError, `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"` + useful.InternalErrorOccurred + `","instance":"/v1/person"}`},
+	} {
+		r, _ := http.NewRequest("GET", "/v1/person", nil)
+		r.Header.Set("Accept", "text/csv")
+		w := httptest.NewRecorder()
+
+		handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Find(w, r)
+
+		assert.Equal(t, c.code, w.Code)
+		assert.Equal(t, c.body, w.Body.String())
+	}
+}
+
+func TestImportSuccess(t *testing.T) {
+
+	existing := primitive.NewObjectID()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Bulk(gomock.Any(), gomock.Eq([]repository.BulkOperation{
+		{Operation: document.Created, Person: document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22}},
+		{Operation: document.Created, Person: document.Person{Name: "Ana", Email: "ana@gmail.com", Age: 30}},
+	}), gomock.Eq(false)).Return([]repository.BulkResult{
+		{Person: document.Person{Id: primitive.NewObjectID()}},
+		{Err: repository.DuplicateEmailError{Id: existing}},
+	}, nil)
+
+	body := "\ufeffAge,Name,Email,Phone\n" +
+		"22, Lucas ,lucas@gmail.com,555\n" +
+		"old,Bruno,bruno@gmail.com,555\n" +
+		"30,Ana,ana@gmail.com,555\n" +
+		"40,Carla,carla,555\n" +
+		"50,Diego\n"
+	r, _ := http.NewRequest("POST", "/v1/person/import", bytes.NewBufferString(body))
+	r.Header.Set("Content-Type", "text/csv; charset=utf-8")
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Import(w, r)
+
+	var report dto.ImportReport
+	_ = json.Unmarshal(w.Body.Bytes(), &report)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, report.Imported)
+	assert.Equal(t, 4, report.Failed)
+
+	var lines []int
+	for _, e := range report.Errors {
+		lines = append(lines, e.Line)
+	}
+	assert.Equal(t, []int{3, 4, 5, 6}, lines)
+	assert.Equal(t, http.StatusBadRequest, report.Errors[0].Status)
+	assert.Contains(t, report.Errors[0].Error, "age")
+	assert.Equal(t, http.StatusConflict, report.Errors[1].Status)
+	assert.Contains(t, report.Errors[1].Error, existing.Hex())
+	assert.Equal(t, useful.InvalidFields, report.Errors[2].Message)
+	assert.Equal(t, []dto.FieldError{{Field: "email", Rule: "email", Message: "email must be a valid email address"}}, report.Errors[2].Fields)
+	assert.Contains(t, report.Errors[3].Error, "number of fields")
+}
+
+func TestImportFailures(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Bulk(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("Bulk error"))
+
+	for _, c := range []struct {
+		contentType string
+		body        string
+		code        int
+		message     string
+	}{
+		{"application/json", "name,email,age\n", http.StatusUnsupportedMediaType, useful.UnsupportedCsv},
+		{"text/csv", "", http.StatusBadRequest, useful.BrokenCsv},
+		{"text/csv", "name,email\nLucas,lucas@gmail.com\n", http.StatusBadRequest, useful.BrokenCsv},
+		{"text/csv", "name,email,age\nLucas,lucas@gmail.com,22\n", http.StatusInternalServerError, useful.ImportError},
+		{"text/csv", "name,email,age," + strings.Repeat("a", 100) + "\n", http.StatusRequestEntityTooLarge, useful.BodyTooLarge},
+		{"text/csv", "name,email,age\n" + strings.Repeat("Lucas,lucas@gmail.com,22\n", 5), http.StatusRequestEntityTooLarge, useful.BodyTooLarge},
+	} {
+		r, _ := http.NewRequest("POST", "/v1/person/import", bytes.NewBufferString(c.body))
+		r.Header.Set("Content-Type", c.contentType)
+		w := httptest.NewRecorder()
+
+		personHandler := handler.NewPersonHandler(&mapper.PersonMapper{}, repo)
+		personHandler.MaxBodySize = 100
+		personHandler.Import(w, r)
+
+		var body dto.Problem
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+		assert.Equal(t, c.code, w.Code)
+		assert.Equal(t, c.message, body.Detail)
+	}
+}
+
+func TestExportSuccess(t *testing.T) {
+
+	var people []document.Person
+	for i := 0; i < 150; i++ {
+		people = append(people, document.Person{Id: primitive.NewObjectID(), Name: "Lucas", Email: "lucas@gmail.com", Age: 22})
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	minAge := 18
+	query := repository.Query{Page: repository.DefaultPage, Size: repository.DefaultSize, MinAge: &minAge}
+	repo.EXPECT().Stream(gomock.Any(), gomock.Eq(query), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, query repository.Query, fn func(document.Person) error) error {
+			for _, person := range people {
+				if err := fn(person); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	r, _ := http.NewRequest("GET", "/v1/person/export?minAge=18", nil)
+	r.Header.Set("Accept", "application/x-ndjson")
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Export(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.True(t, w.Flushed)
+
+	decoder := json.NewDecoder(w.Body)
+	for _, person := range people {
+		var line dto.Person
+		assert.Nil(t, decoder.Decode(&line))
+		assert.Equal(t, person.Id, line.Id)
+	}
+	assert.False(t, decoder.More())
+}
+
+func TestExportStopsWhenClientGoesAway(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sent := 0
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().Stream(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, query repository.Query, fn func(document.Person) error) error {
+			for {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if err := fn(document.Person{Id: primitive.NewObjectID()}); err != nil {
+					return err
+				}
+				if sent++; sent == 3 {
+					cancel()
+				}
+			}
+		})
+
+	r, _ := http.NewRequest("GET", "/v1/person/export", nil)
+	w := httptest.NewRecorder()
+
+	handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Export(w, r.WithContext(ctx))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 3, sent)
+	assert.Equal(t, 3, strings.Count(w.Body.String(), "\n"))
+}
+
+func TestExportFailures(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().Stream(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("Stream error")),
+		repo.EXPECT().Stream(gomock.Any(), gomock.Any(), gomock.Any()).Return(context.DeadlineExceeded),
+	)
+
+	for _, c := range []struct {
+		accept  string
+		query   string
+		code    int
+		message string
+	}{
+		{"text/csv", "", http.StatusNotAcceptable, useful.NdjsonOnly},
+		{"*/*", "?minAge=old", http.StatusBadRequest, useful.BrokenQuery},
+		{"", "", http.StatusInternalServerError, useful.InternalErrorOccurred},
+		{"", "", http.StatusGatewayTimeout, useful.RequestTimeout},
+	} {
+		r, _ := http.NewRequest("GET", "/v1/person/export"+c.query, nil)
+		r.Header.Set("Accept", c.accept)
+		w := httptest.NewRecorder()
+
+		handler.NewPersonHandler(&mapper.PersonMapper{}, repo).Export(w, r)
+
+		var body dto.Problem
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+		assert.Equal(t, c.code, w.Code)
+		assert.Equal(t, c.message, body.Detail)
+	}
+}
+
+// updatedFields are the fields a v1 update replaces when no birth date, contacts nor documents are sent.
+var updatedFields = []string{"name", "email", "age"}
+
+func intPointer(value int) *int {
+	return &value
 }
@@ -0,0 +1,64 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/go-playground/validator.v9"
+	"person/internal/i18n"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+
+	tests := []struct {
+		acceptLanguage string
+		tag            string
+	}{
+		{"", i18n.English},
+		{"pt-BR", i18n.BrazilianPortuguese},
+		{"pt-br", i18n.BrazilianPortuguese},
+		{"pt-PT", i18n.BrazilianPortuguese},
+		{"es-AR,es;q=0.9", i18n.Spanish},
+		{"de, es;q=0.4, pt;q=0.6", i18n.BrazilianPortuguese},
+		{"en-US, pt-BR;q=0.9", i18n.English},
+		{"pt-BR;q=0, es", i18n.Spanish},
+		{"*", i18n.English},
+		{"ja", i18n.English},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.tag, i18n.Negotiate(test.acceptLanguage).Tag, test.acceptLanguage)
+	}
+}
+
+func TestFieldMessage(t *testing.T) {
+
+	v := validator.New()
+	assert.Nil(t, i18n.RegisterTranslations(v))
+
+	err := v.Struct(struct {
+		Email string `validate:"required"`
+		Code  string `validate:"hexadecimal"`
+	}{Code: "xyz"})
+	errs := err.(validator.ValidationErrors)
+
+	assert.Equal(t, "Email is a required field", i18n.Negotiate("en").FieldMessage(errs[0]))
+	assert.Equal(t, "Email é um campo requerido", i18n.Negotiate("pt-BR").FieldMessage(errs[0]))
+	assert.Equal(t, "Email es un campo obligatorio", i18n.Negotiate("es").FieldMessage(errs[0]))
+	assert.Equal(t, "Code must be a valid hexadecimal", i18n.Negotiate("es").FieldMessage(errs[1]))
+}
+
+func TestRuleMessage(t *testing.T) {
+
+	v := validator.New()
+	assert.Nil(t, v.RegisterValidation("age", func(fl validator.FieldLevel) bool { return false }))
+	assert.Nil(t, i18n.RegisterTranslations(v))
+	assert.Nil(t, i18n.RegisterRuleTranslations(v, map[string][]string{"age": {"18", "65"}}))
+
+	errs := v.Struct(struct {
+		Age int `validate:"age"`
+	}{Age: 12}).(validator.ValidationErrors)
+
+	assert.Equal(t, "Age must be between 18 and 65", i18n.Negotiate("en").FieldMessage(errs[0]))
+	assert.Equal(t, "Age deve estar entre 18 e 65", i18n.Negotiate("pt-BR").FieldMessage(errs[0]))
+	assert.Equal(t, "Age debe estar entre 18 y 65", i18n.Negotiate("es").FieldMessage(errs[0]))
+}
@@ -0,0 +1,789 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"person/configs"
+	"person/internal/codec"
+	"person/internal/dto"
+	v2 "person/internal/dto/v2"
+	"person/internal/handler"
+	"person/internal/handlerv2"
+	"person/internal/mapper"
+	"person/internal/repository"
+	"testing"
+	"time"
+)
+
+func newServer() *httptest.Server {
+	repo := repository.NewMemoryRepository()
+	personHandler := handler.NewPersonHandler(&mapper.PersonMapper{}, repo)
+	return httptest.NewServer(configs.Router(personHandler, handlerv2.NewPersonHandler(repo)))
+}
+
+func TestPersonLifecycleWithMemoryStorage(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: intPointer(22)})
+	res, err := http.Post(server.URL+"/v1/person", "application/json", bytes.NewBuffer(bodySent))
+	assert.Nil(t, err)
+
+	var created dto.Person
+	_ = json.NewDecoder(res.Body).Decode(&created)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.False(t, created.Id.IsZero())
+
+	bodySent, _ = json.Marshal(dto.Person{Name: "Lucas Silva", Email: "lucas@gmail.com", Age: intPointer(23)})
+	req, _ := http.NewRequest(http.MethodPut, server.URL+"/v1/person/"+created.Id.Hex(), bytes.NewBuffer(bodySent))
+	res, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = http.Get(server.URL + "/v1/person/" + created.Id.Hex())
+	assert.Nil(t, err)
+
+	var found dto.Person
+	_ = json.NewDecoder(res.Body).Decode(&found)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "Lucas Silva", found.Name)
+	assert.Equal(t, 23, *found.Age)
+
+	res, err = http.Get(server.URL + "/v1/person?name=silva")
+	assert.Nil(t, err)
+
+	var page dto.Page
+	_ = json.NewDecoder(res.Body).Decode(&page)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, created.Id, page.Data[0].Id)
+
+	req, _ = http.NewRequest(http.MethodDelete, server.URL+"/v1/person/"+created.Id.Hex(), nil)
+	res, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+
+	res, err = http.Get(server.URL + "/v1/person/" + created.Id.Hex())
+	assert.Nil(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestListingWithMemoryStorage(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	for _, person := range []dto.Person{
+		{Name: "Carla", Email: "carla@gmail.com", Age: intPointer(40)},
+		{Name: "Ana", Email: "ana@gmail.com", Age: intPointer(30)},
+		{Name: "Bruno", Email: "bruno@gmail.com", Age: intPointer(30)},
+	} {
+		bodySent, _ := json.Marshal(person)
+		res, err := http.Post(server.URL+"/v1/person", "application/json", bytes.NewBuffer(bodySent))
+		assert.Nil(t, err)
+		_ = res.Body.Close()
+	}
+
+	res, err := http.Get(server.URL + "/v1/person?sort=-age,name&size=2")
+	assert.Nil(t, err)
+
+	var page dto.Page
+	_ = json.NewDecoder(res.Body).Decode(&page)
+	_ = res.Body.Close()
+
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, []string{"Carla", "Ana"}, []string{page.Data[0].Name, page.Data[1].Name})
+	assert.NotEmpty(t, page.Next)
+
+	var names []string
+	next := "/v1/person?sort=name&size=1&cursor="
+
+	for next != "" {
+		res, err = http.Get(server.URL + next)
+		assert.Nil(t, err)
+
+		page = dto.Page{}
+		_ = json.NewDecoder(res.Body).Decode(&page)
+		_ = res.Body.Close()
+
+		for _, person := range page.Data {
+			names = append(names, person.Name)
+		}
+		next = page.Next
+	}
+
+	assert.Equal(t, []string{"Ana", "Bruno", "Carla"}, names)
+}
+
+func TestSearchWithMemoryStorage(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	for _, person := range []dto.Person{
+		{Name: "João da Silva", Email: "joao@gmail.com", Age: intPointer(40)},
+		{Name: "Maria Silva", Email: "maria@gmail.com", Age: intPointer(30)},
+		{Name: "Bruno", Email: "bruno@gmail.com", Age: intPointer(30)},
+	} {
+		bodySent, _ := json.Marshal(person)
+		res, err := http.Post(server.URL+"/v1/person", "application/json", bytes.NewBuffer(bodySent))
+		assert.Nil(t, err)
+		_ = res.Body.Close()
+	}
+
+	tests := []struct {
+		query    string
+		mode     string
+		expected []string
+	}{
+		{"q=Joao+Silva", "text", []string{"João da Silva", "Maria Silva"}},
+		{"q=Slva", "fuzzy", []string{"João da Silva", "Maria Silva"}},
+		{"q=Slva&mode=text", "text", nil},
+		{"q=brunno&mode=fuzzy", "fuzzy", []string{"Bruno"}},
+	}
+
+	for _, test := range tests {
+		res, err := http.Get(server.URL + "/v1/person/search?" + test.query)
+		assert.Nil(t, err)
+
+		var page dto.SearchPage
+		_ = json.NewDecoder(res.Body).Decode(&page)
+		_ = res.Body.Close()
+
+		var names []string
+		for _, person := range page.Data {
+			names = append(names, person.Name)
+		}
+
+		assert.Equal(t, http.StatusOK, res.StatusCode, test.query)
+		assert.Equal(t, test.mode, page.Mode, test.query)
+		assert.Equal(t, test.expected, names, test.query)
+	}
+}
+
+func TestAuditWithActorHeader(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: intPointer(22)})
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/person", bytes.NewBuffer(bodySent))
+	req.Header.Set(handler.ActorHeader, "sync-job")
+	res, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+
+	var created dto.Person
+	_ = json.NewDecoder(res.Body).Decode(&created)
+	_ = res.Body.Close()
+
+	assert.Equal(t, "sync-job", created.CreatedBy)
+	assert.Equal(t, "sync-job", created.UpdatedBy)
+	assert.NotNil(t, created.CreatedAt)
+
+	bodySent, _ = json.Marshal(dto.Person{Name: "Lucas Silva", Email: "lucas@gmail.com", Age: intPointer(22)})
+	req, _ = http.NewRequest(http.MethodPut, server.URL+"/v1/person/"+created.Id.Hex(), bytes.NewBuffer(bodySent))
+	res, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+
+	var updated dto.Person
+	_ = json.NewDecoder(res.Body).Decode(&updated)
+	_ = res.Body.Close()
+
+	assert.Equal(t, "sync-job", updated.CreatedBy)
+	assert.Equal(t, repository.Anonymous, updated.UpdatedBy)
+
+	res, err = http.Get(server.URL + "/v1/person?updatedSince=" + url.QueryEscape(updated.UpdatedAt.Format(time.RFC3339Nano)))
+	assert.Nil(t, err)
+
+	var page dto.Page
+	_ = json.NewDecoder(res.Body).Decode(&page)
+	_ = res.Body.Close()
+
+	assert.Equal(t, int64(1), page.Total)
+
+	res, err = http.Get(server.URL + "/v1/person?updatedSince=" + url.QueryEscape(updated.UpdatedAt.Add(time.Second).Format(time.RFC3339)))
+	assert.Nil(t, err)
+
+	page = dto.Page{}
+	_ = json.NewDecoder(res.Body).Decode(&page)
+	_ = res.Body.Close()
+
+	assert.Equal(t, int64(0), page.Total)
+}
+
+func TestHistoryWithMemoryStorage(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: intPointer(22)})
+	res, err := http.Post(server.URL+"/v1/person", "application/json", bytes.NewBuffer(bodySent))
+	assert.Nil(t, err)
+
+	var created dto.Person
+	_ = json.NewDecoder(res.Body).Decode(&created)
+	_ = res.Body.Close()
+
+	time.Sleep(5 * time.Millisecond)
+
+	bodySent, _ = json.Marshal(dto.Person{Name: "Lucas Silva", Email: "lucas@gmail.com", Age: intPointer(23)})
+	req, _ := http.NewRequest(http.MethodPut, server.URL+"/v1/person/"+created.Id.Hex(), bytes.NewBuffer(bodySent))
+	res, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	_ = res.Body.Close()
+
+	res, err = http.Get(server.URL + "/v1/person/" + created.Id.Hex() + "/history")
+	assert.Nil(t, err)
+
+	var page dto.RevisionPage
+	_ = json.NewDecoder(res.Body).Decode(&page)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, int64(2), page.Total)
+	assert.Equal(t, "update", page.Data[0].Operation)
+	assert.Equal(t, "Lucas", page.Data[0].Before.Name)
+	assert.Equal(t, "Lucas Silva", page.Data[0].After.Name)
+
+	res, err = http.Get(server.URL + "/v1/person/" + created.Id.Hex() + "?asOf=" + url.QueryEscape(created.CreatedAt.Format(time.RFC3339Nano)))
+	assert.Nil(t, err)
+
+	var found dto.Person
+	_ = json.NewDecoder(res.Body).Decode(&found)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "Lucas", found.Name)
+	assert.Equal(t, 22, *found.Age)
+}
+
+func TestRevertWithMemoryStorage(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: intPointer(22)})
+	res, err := http.Post(server.URL+"/v1/person", "application/json", bytes.NewBuffer(bodySent))
+	assert.Nil(t, err)
+
+	var created dto.Person
+	_ = json.NewDecoder(res.Body).Decode(&created)
+	_ = res.Body.Close()
+
+	bodySent, _ = json.Marshal(dto.Person{Name: "Wrong", Email: "wrong@gmail.com", Age: intPointer(99)})
+	req, _ := http.NewRequest(http.MethodPut, server.URL+"/v1/person/"+created.Id.Hex(), bytes.NewBuffer(bodySent))
+	res, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	_ = res.Body.Close()
+
+	res, err = http.Post(server.URL+"/v1/person/"+created.Id.Hex()+"/revisions/1/revert", "application/json", nil)
+	assert.Nil(t, err)
+
+	var reverted dto.Person
+	_ = json.NewDecoder(res.Body).Decode(&reverted)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, `"3"`, res.Header.Get("ETag"))
+	assert.Equal(t, "Lucas", reverted.Name)
+	assert.Equal(t, "lucas@gmail.com", reverted.Email)
+	assert.Equal(t, 22, *reverted.Age)
+
+	res, err = http.Get(server.URL + "/v1/person/" + created.Id.Hex() + "/history")
+	assert.Nil(t, err)
+
+	var page dto.RevisionPage
+	_ = json.NewDecoder(res.Body).Decode(&page)
+	_ = res.Body.Close()
+
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, "revert", page.Data[0].Operation)
+	assert.Equal(t, int64(1), page.Data[0].Reverts)
+	assert.Equal(t, "Wrong", page.Data[0].Before.Name)
+
+	res, err = http.Post(server.URL+"/v1/person/"+created.Id.Hex()+"/revisions/7/revert", "application/json", nil)
+	assert.Nil(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestBulkWithMemoryStorage(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	body := `[
+		{"op": "create", "person": {"name": "Lucas", "email": "lucas@gmail.com", "age": 22}},
+		{"op": "create", "person": {"name": "Ana", "email": "ana@gmail.com", "age": 30}},
+		{"op": "create", "person": {"name": "Again", "email": "lucas@gmail.com", "age": 22}}
+	]`
+	res, err := http.Post(server.URL+"/v1/person/_bulk?ordered=false", "application/json", bytes.NewBufferString(body))
+	assert.Nil(t, err)
+
+	var results []dto.BulkResult
+	_ = json.NewDecoder(res.Body).Decode(&results)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, http.StatusCreated, results[0].Status)
+	assert.Equal(t, http.StatusCreated, results[1].Status)
+	assert.Equal(t, http.StatusConflict, results[2].Status)
+
+	body = `[
+		{"op": "update", "id": "` + results[0].Id + `", "version": 1, "person": {"name": "Lucas Silva", "email": "lucas@gmail.com", "age": 23}},
+		{"op": "delete", "id": "` + results[1].Id + `"}
+	]`
+	res, err = http.Post(server.URL+"/v1/person/_bulk", "application/json", bytes.NewBufferString(body))
+	assert.Nil(t, err)
+
+	results = nil
+	_ = json.NewDecoder(res.Body).Decode(&results)
+	_ = res.Body.Close()
+
+	assert.Equal(t, dto.BulkResult{Status: http.StatusOK, Id: results[0].Id, Version: 2}, results[0])
+	assert.Equal(t, http.StatusNoContent, results[1].Status)
+
+	res, err = http.Get(server.URL + "/v1/person")
+	assert.Nil(t, err)
+
+	var page dto.Page
+	_ = json.NewDecoder(res.Body).Decode(&page)
+	_ = res.Body.Close()
+
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, "Lucas Silva", page.Data[0].Name)
+}
+
+func TestCsvImportAndExportWithMemoryStorage(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	body := "name,email,age\nLucas,lucas@gmail.com,22\nAna,ana@gmail.com,30\nAna Again,ana@gmail.com,31\n"
+	res, err := http.Post(server.URL+"/v1/person/import", "text/csv", bytes.NewBufferString(body))
+	assert.Nil(t, err)
+
+	var report dto.ImportReport
+	_ = json.NewDecoder(res.Body).Decode(&report)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 4, report.Errors[0].Line)
+	assert.Equal(t, http.StatusConflict, report.Errors[0].Status)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/person?sort=name", nil)
+	req.Header.Set("Accept", "text/csv")
+	res, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+
+	records, err := csv.NewReader(res.Body).ReadAll()
+	_ = res.Body.Close()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "text/csv; charset=utf-8", res.Header.Get("Content-Type"))
+	assert.Len(t, records, 3)
+	assert.Equal(t, []string{"Ana", "ana@gmail.com", "30"}, records[1][1:4])
+	assert.Equal(t, []string{"Lucas", "lucas@gmail.com", "22"}, records[2][1:4])
+}
+
+func TestNdjsonExportWithMemoryStorage(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	for _, person := range []dto.Person{
+		{Name: "Lucas", Email: "lucas@gmail.com", Age: intPointer(22)},
+		{Name: "Ana", Email: "ana@gmail.com", Age: intPointer(30)},
+		{Name: "Bruno", Email: "bruno@gmail.com", Age: intPointer(17)},
+	} {
+		bodySent, _ := json.Marshal(person)
+		res, err := http.Post(server.URL+"/v1/person", "application/json", bytes.NewBuffer(bodySent))
+		assert.Nil(t, err)
+		_ = res.Body.Close()
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/person/export?minAge=18&sort=name", nil)
+	req.Header.Set("Accept", "application/x-ndjson")
+	res, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+
+	var exported []string
+	decoder := json.NewDecoder(res.Body)
+	for decoder.More() {
+		var person dto.Person
+		assert.Nil(t, decoder.Decode(&person))
+		exported = append(exported, person.Name)
+	}
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/x-ndjson", res.Header.Get("Content-Type"))
+	assert.Equal(t, []string{"Ana", "Lucas"}, exported)
+}
+
+func TestContentNegotiationWithMemoryStorage(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/person", bytes.NewBufferString("name: Lucas\nemail: lucas@gmail.com\nage: 22\n"))
+	req.Header.Set("Content-Type", "application/yaml")
+	req.Header.Set("Accept", "application/xml")
+	res, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+
+	var created dto.Person
+	raw, _ := ioutil.ReadAll(res.Body)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Equal(t, "application/xml", res.Header.Get("Content-Type"))
+	assert.Nil(t, codec.XML.Unmarshal(raw, &created))
+	assert.Equal(t, "Lucas", created.Name)
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/v1/person/"+created.Id.Hex(), nil)
+	req.Header.Set("Accept", "application/msgpack")
+	res, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+
+	var found dto.Person
+	raw, _ = ioutil.ReadAll(res.Body)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/msgpack", res.Header.Get("Content-Type"))
+	assert.Nil(t, codec.MessagePack.Unmarshal(raw, &found))
+	assert.Equal(t, created.Id, found.Id)
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/v1/person/"+created.Id.Hex(), nil)
+	req.Header.Set("Accept", "text/html")
+	res, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusNotAcceptable, res.StatusCode)
+
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/v1/person", bytes.NewBufferString(`{"name": "Ana", "email": "ana@gmail.com", "age": 30}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/csv")
+	res, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusNotAcceptable, res.StatusCode)
+
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/v1/person", bytes.NewBufferString("name,email"))
+	req.Header.Set("Content-Type", "text/plain")
+	res, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/v1/person", nil)
+	req.Header.Set("Accept", "text/csv")
+	res, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", res.Header.Get("Content-Type"))
+}
+
+func TestProblemDetailsWithMemoryStorage(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@@gmail.com"})
+	res, err := http.Post(server.URL+"/v1/person", "application/json", bytes.NewBuffer(bodySent))
+	assert.Nil(t, err)
+
+	var problem dto.Problem
+	_ = json.NewDecoder(res.Body).Decode(&problem)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+	assert.Equal(t, "/v1/person", problem.Instance)
+	assert.Equal(t, []dto.FieldError{
+		{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+		{Field: "age", Rule: "required", Message: "age is a required field"},
+	}, problem.Errors)
+}
+
+func TestLocalizedProblemDetailsWithMemoryStorage(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	bodySent, _ := json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@@gmail.com", Age: intPointer(22)})
+
+	for _, c := range []struct {
+		acceptLanguage string
+		language       string
+		title          string
+		field          string
+	}{
+		{"pt-BR,pt;q=0.9,en;q=0.8", "pt-BR", "Requisição inválida", "email deve ser um endereço de e-mail válido"},
+		{"es-MX", "es", "Solicitud incorrecta", "email debe ser una dirección de correo electrónico válida"},
+		{"fr, en;q=0.5", "en", "Bad Request", "email must be a valid email address"},
+		{"", "en", "Bad Request", "email must be a valid email address"},
+	} {
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/person", bytes.NewBuffer(bodySent))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", c.acceptLanguage)
+		res, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+
+		var problem dto.Problem
+		_ = json.NewDecoder(res.Body).Decode(&problem)
+		_ = res.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, c.acceptLanguage)
+		assert.Equal(t, c.language, res.Header.Get("Content-Language"), c.acceptLanguage)
+		assert.Equal(t, c.title, problem.Title, c.acceptLanguage)
+		assert.Equal(t, c.field, problem.Errors[0].Message, c.acceptLanguage)
+	}
+}
+
+func TestVersionsShareStorageWithMemoryStorage(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	birthDate := dto.NewDate(1990, time.May, 17)
+	bodySent, _ := json.Marshal(v2.Person{
+		Name:      v2.Name{First: "João", Last: "da Silva"},
+		BirthDate: &birthDate,
+		Contact:   v2.Contact{Email: "joao@gmail.com", Phones: []dto.Phone{{Type: "mobile", Number: "+5511987654321"}}},
+	})
+	res, err := http.Post(server.URL+"/v2/person", "application/json", bytes.NewBuffer(bodySent))
+	assert.Nil(t, err)
+
+	var created v2.Person
+	_ = json.NewDecoder(res.Body).Decode(&created)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.False(t, created.Id.IsZero())
+	assert.Equal(t, v2.Name{First: "João", Last: "da Silva"}, created.Name)
+
+	res, err = http.Get(server.URL + "/v1/person/" + created.Id.Hex())
+	assert.Nil(t, err)
+
+	var v1 dto.Person
+	_ = json.NewDecoder(res.Body).Decode(&v1)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "João da Silva", v1.Name)
+	assert.Equal(t, "joao@gmail.com", v1.Email)
+	assert.Equal(t, birthDate, *v1.BirthDate)
+	assert.Equal(t, created.Contact.Phones, v1.Phones)
+
+	res, err = http.Get(server.URL + "/v2/person?name=silva")
+	assert.Nil(t, err)
+
+	var page struct {
+		Data  []v2.Person `json:"data"`
+		Total int64       `json:"total"`
+	}
+	_ = json.NewDecoder(res.Body).Decode(&page)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, created.Id, page.Data[0].Id)
+	assert.Equal(t, birthDate, *page.Data[0].BirthDate)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/v2/person", nil)
+	req.Header.Set("Accept", "text/csv, application/json;q=0.5")
+	res, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+}
+
+func TestV1UpdateKeepsV2DataWithMemoryStorage(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	birthDate := dto.NewDate(1990, time.May, 17)
+	phones := []dto.Phone{{Type: "mobile", Number: "+5511987654321"}}
+	addresses := []dto.Address{{Street: "Avenida Paulista", City: "São Paulo", Country: "BR"}}
+	bodySent, _ := json.Marshal(v2.Person{
+		Name:      v2.Name{First: "João", Last: "da Silva"},
+		BirthDate: &birthDate,
+		Contact:   v2.Contact{Email: "joao@gmail.com", Phones: phones},
+		Addresses: addresses,
+	})
+	res, err := http.Post(server.URL+"/v2/person", "application/json", bytes.NewBuffer(bodySent))
+	assert.Nil(t, err)
+
+	var created v2.Person
+	_ = json.NewDecoder(res.Body).Decode(&created)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	bodySent = []byte(`{"name": "João Silva", "email": "joao.silva@gmail.com", "age": 30}`)
+	req, _ := http.NewRequest(http.MethodPut, server.URL+"/v1/person/"+created.Id.Hex(), bytes.NewBuffer(bodySent))
+	res, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = http.Get(server.URL + "/v2/person/" + created.Id.Hex())
+	assert.Nil(t, err)
+
+	var found v2.Person
+	_ = json.NewDecoder(res.Body).Decode(&found)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, v2.Name{First: "João", Last: "Silva"}, found.Name)
+	assert.Equal(t, "joao.silva@gmail.com", found.Contact.Email)
+	assert.Equal(t, birthDate, *found.BirthDate, "a v1 update leaves what it does not send")
+	assert.Equal(t, phones, found.Contact.Phones)
+	assert.Equal(t, addresses, found.Addresses)
+}
+
+func TestV2ValidationWithMemoryStorage(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	bodySent, _ := json.Marshal(v2.Person{Name: v2.Name{First: "João"}, Contact: v2.Contact{Email: "joao@@gmail.com"}})
+	res, err := http.Post(server.URL+"/v2/person", "application/json", bytes.NewBuffer(bodySent))
+	assert.Nil(t, err)
+
+	var problem dto.Problem
+	_ = json.NewDecoder(res.Body).Decode(&problem)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, "/v2/person", problem.Instance)
+	assert.Equal(t, []dto.FieldError{
+		{Field: "birthDate", Rule: "required", Message: "birthDate is a required field"},
+		{Field: "contact.email", Rule: "email", Message: "email must be a valid email address"},
+	}, problem.Errors)
+}
+
+func TestV2BirthDateAgeWithMemoryStorage(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	born := time.Date(1600, 1, 1, 0, 0, 0, 0, time.UTC)
+	bodySent, _ := json.Marshal(v2.Person{Name: v2.Name{First: "João"}, BirthDate: &dto.Date{Time: born}, Contact: v2.Contact{Email: "joao@gmail.com"}})
+	res, err := http.Post(server.URL+"/v2/person", "application/json", bytes.NewBuffer(bodySent))
+	assert.Nil(t, err)
+
+	var problem dto.Problem
+	_ = json.NewDecoder(res.Body).Decode(&problem)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, []dto.FieldError{
+		{Field: "birthDate", Rule: "birthdate", Message: "birthDate must give an age between 0 and 150"},
+	}, problem.Errors)
+}
+
+func TestConfiguredRulesWithMemoryStorage(t *testing.T) {
+
+	rules := dto.ValidationRules{MinAge: 18, MaxAge: 65, MinNameLength: 3, MaxNameLength: 10, EmailDomains: []string{"example.com"}}
+	validator, err := handler.NewValidator(rules)
+	assert.Nil(t, err)
+
+	repo := repository.NewMemoryRepository()
+	personHandler := handler.NewPersonHandler(&mapper.PersonMapper{}, repo)
+	personHandler.Validator = validator
+	personHandlerV2 := handlerv2.NewPersonHandler(repo)
+	personHandlerV2.Validator = validator
+
+	server := httptest.NewServer(configs.Router(personHandler, personHandlerV2))
+	defer server.Close()
+
+	for _, version := range []string{"/v1", "/v2"} {
+		res, err := http.Get(server.URL + version + "/person/rules")
+		assert.Nil(t, err)
+
+		var found dto.ValidationRules
+		_ = json.NewDecoder(res.Body).Decode(&found)
+		_ = res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode, version)
+		assert.Equal(t, rules, found, version)
+	}
+
+	bodySent, _ := json.Marshal(v2.Person{Name: v2.Name{First: "João", Last: "Ferreira"}, BirthDate: &dto.Date{Time: time.Now().AddDate(-30, 0, 0)},
+		Contact: v2.Contact{Email: "joao@gmail.com"}})
+	res, err := http.Post(server.URL+"/v2/person", "application/json", bytes.NewBuffer(bodySent))
+	assert.Nil(t, err)
+
+	var problem dto.Problem
+	_ = json.NewDecoder(res.Body).Decode(&problem)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, []dto.FieldError{
+		{Field: "contact.email", Rule: "domain", Message: "email must use one of the domains example.com"},
+		{Field: "name", Rule: "name", Message: "name must be between 3 and 10 characters long"},
+	}, problem.Errors)
+
+	bodySent, _ = json.Marshal(dto.Person{Name: "Lucas", Email: "lucas@example.com", Age: intPointer(18)})
+	res, err = http.Post(server.URL+"/v1/person", "application/json", bytes.NewBuffer(bodySent))
+	assert.Nil(t, err)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+}
+
+func TestSwaggerDocumentPerVersion(t *testing.T) {
+
+	server := newServer()
+	defer server.Close()
+
+	for version, path := range map[string]string{"1.0": "/swagger/doc.json", "2.0": "/swagger/v2/doc.json"} {
+		res, err := http.Get(server.URL + path)
+		assert.Nil(t, err)
+
+		var doc struct {
+			Info struct {
+				Version string `json:"version"`
+			} `json:"info"`
+		}
+		_ = json.NewDecoder(res.Body).Decode(&doc)
+		_ = res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode, path)
+		assert.Equal(t, version, doc.Info.Version, path)
+	}
+}
+
+func intPointer(value int) *int {
+	return &value
+}
@@ -0,0 +1,63 @@
+package job
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"person/internal/job"
+	"person/test/mocks"
+	"testing"
+	"time"
+)
+
+func TestPurgeRunRemovesPeopleDeletedBeforeRetention(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+
+	var deletedBefore time.Time
+	repo.EXPECT().Purge(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, before time.Time) (int64, error) {
+		deletedBefore = before
+		return 2, nil
+	})
+
+	start := time.Now()
+	job.Purge{Repository: repo, Retention: 48 * time.Hour, Interval: time.Hour}.Run(context.Background())
+
+	assert.False(t, deletedBefore.Before(start.Add(-48*time.Hour)))
+	assert.False(t, deletedBefore.After(time.Now().Add(-48*time.Hour)))
+}
+
+func TestPurgeStartRunsUntilCanceled(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+
+	ran := make(chan struct{}, 10)
+	repo.EXPECT().Purge(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ time.Time) (int64, error) {
+		ran <- struct{}{}
+		return 0, errors.New("purge error")
+	}).MinTimes(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job.Purge{Repository: repo, Retention: time.Hour, Interval: time.Millisecond}.Start(ctx)
+
+	<-ran
+	<-ran
+	cancel()
+}
+
+func TestPurgeStartDisabledWithoutInterval(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+
+	job.Purge{Repository: repo, Retention: time.Hour}.Start(context.Background())
+}
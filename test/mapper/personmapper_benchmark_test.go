@@ -0,0 +1,82 @@
+package mapper
+
+import (
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"person/internal/document"
+	dto2 "person/internal/dto"
+	"person/internal/mapper"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var sizes = []int{100, 1000, 10000}
+
+func BenchmarkListDocumentToListDto(b *testing.B) {
+
+	personMapper := &mapper.PersonMapper{}
+
+	for _, size := range sizes {
+		people := manyPeople(size)
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				personMapper.ListDocumentToListDto(people)
+			}
+		})
+	}
+}
+
+// BenchmarkListDocumentToListDtoWithMapstructure is the reflection based decoding PersonMapper did before mappergen,
+// kept as the baseline of BenchmarkListDocumentToListDto.
+func BenchmarkListDocumentToListDtoWithMapstructure(b *testing.B) {
+
+	for _, size := range sizes {
+		people := manyPeople(size)
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var result []dto2.Person
+				decoder, _ := mapstructure.NewDecoder(&mapstructure.DecoderConfig{DecodeHook: dateHook, Result: &result})
+				if err := decoder.Decode(people); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func manyPeople(size int) []document.Person {
+
+	birthDate := time.Date(1990, time.May, 17, 0, 0, 0, 0, time.UTC)
+	people := make([]document.Person, size)
+
+	for i := range people {
+		people[i] = document.Person{
+			Id:        primitive.NewObjectID(),
+			Name:      fmt.Sprintf("Person %d", i),
+			Email:     fmt.Sprintf("person%d@gmail.com", i),
+			Age:       30,
+			BirthDate: &birthDate,
+			Phones:    []document.Phone{{Type: "mobile", Number: "+5511987654321"}},
+			Addresses: []document.Address{{Street: "Avenida Paulista", City: "São Paulo", Country: "BR"}},
+			Documents: []document.Identifier{{Type: "cpf", Number: "52998224725"}},
+			CreatedAt: &birthDate,
+		}
+	}
+
+	return people
+}
+
+func dateHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+
+	value := reflect.Indirect(reflect.ValueOf(data))
+
+	if value.IsValid() && value.Type() == reflect.TypeOf(time.Time{}) && to == reflect.TypeOf(dto2.Date{}) {
+		return dto2.Date{Time: value.Interface().(time.Time)}, nil
+	}
+
+	return data, nil
+}
@@ -5,8 +5,10 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"person/internal/document"
 	dto2 "person/internal/dto"
+	v2 "person/internal/dto/v2"
 	"person/internal/mapper"
 	"testing"
+	"time"
 )
 
 func TestShouldReturnDTOFilled(t *testing.T) {
@@ -20,13 +22,12 @@ func TestShouldReturnDTOFilled(t *testing.T) {
 	}
 
 	personMapper := &mapper.PersonMapper{}
-	dto, err := personMapper.DocumentToDto(doc)
+	dto := personMapper.DocumentToDto(doc).(dto2.Person)
 
-	assert.Nil(t, err)
 	assert.Equal(t, dto.Id, doc.Id)
 	assert.Equal(t, dto.Name, doc.Name)
 	assert.Equal(t, dto.Email, doc.Email)
-	assert.Equal(t, dto.Age, doc.Age)
+	assert.Equal(t, *dto.Age, doc.Age)
 }
 
 func TestShouldReturnListDTOFilled(t *testing.T) {
@@ -38,18 +39,17 @@ func TestShouldReturnListDTOFilled(t *testing.T) {
 	}
 
 	personMapper := &mapper.PersonMapper{}
-	dtos, err := personMapper.ListDocumentToListDto(docs)
+	dtos := personMapper.ListDocumentToListDto(docs).([]dto2.Person)
 
-	assert.Nil(t, err)
 	assert.Len(t, dtos, 2)
 	assert.Equal(t, dtos[0].Id, docs[0].Id)
 	assert.Equal(t, dtos[0].Name, docs[0].Name)
 	assert.Equal(t, dtos[0].Email, docs[0].Email)
-	assert.Equal(t, dtos[0].Age, docs[0].Age)
+	assert.Equal(t, *dtos[0].Age, docs[0].Age)
 	assert.Equal(t, dtos[1].Id, docs[1].Id)
 	assert.Equal(t, dtos[1].Name, "")
 	assert.Equal(t, dtos[1].Email, docs[1].Email)
-	assert.Equal(t, dtos[1].Age, docs[1].Age)
+	assert.Equal(t, *dtos[1].Age, docs[1].Age)
 }
 
 func TestShouldReturnDocumentFilled(t *testing.T) {
@@ -59,15 +59,121 @@ func TestShouldReturnDocumentFilled(t *testing.T) {
 		Id:    objID,
 		Name:  "Lucas",
 		Email: "lucas@gmail.com",
-		Age:   22,
+		Age:   intPointer(22),
 	}
 
 	personMapper := &mapper.PersonMapper{}
-	doc, err := personMapper.DtoToDocument(dto)
+	doc := personMapper.DtoToDocument(&dto)
 
-	assert.Nil(t, err)
 	assert.Equal(t, doc.Id, dto.Id)
 	assert.Equal(t, doc.Name, dto.Name)
 	assert.Equal(t, doc.Email, dto.Email)
-	assert.Equal(t, doc.Age, dto.Age)
+	assert.Equal(t, doc.Age, *dto.Age)
+}
+
+func TestShouldReturnListRevisionDTOFilled(t *testing.T) {
+
+	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
+	at := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	revisions := []document.Revision{
+		{
+			PersonId:  objID,
+			Version:   2,
+			Operation: document.Updated,
+			Before:    &document.Person{Id: objID, Name: "Lucas", Version: 1},
+			After:     &document.Person{Id: objID, Name: "Lucas Silva", Version: 2, UpdatedAt: &at},
+			Actor:     "ana",
+			At:        &at,
+		},
+		{PersonId: objID, Version: 1, Operation: document.Created, After: &document.Person{Id: objID, Name: "Lucas"}},
+	}
+
+	personMapper := &mapper.PersonMapper{}
+	dtos := personMapper.ListRevisionToListDto(revisions).([]dto2.Revision)
+
+	assert.Len(t, dtos, 2)
+	assert.Equal(t, objID, dtos[0].PersonId)
+	assert.Equal(t, int64(2), dtos[0].Version)
+	assert.Equal(t, document.Updated, dtos[0].Operation)
+	assert.Equal(t, "Lucas", dtos[0].Before.Name)
+	assert.Equal(t, "Lucas Silva", dtos[0].After.Name)
+	assert.Equal(t, &at, dtos[0].After.UpdatedAt)
+	assert.Equal(t, "ana", dtos[0].Actor)
+	assert.Equal(t, &at, dtos[0].At)
+	assert.Nil(t, dtos[1].Before)
+	assert.Equal(t, "Lucas", dtos[1].After.Name)
+}
+
+func TestShouldComputeAgeFromBirthDate(t *testing.T) {
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	turnsThirtyToday := today.AddDate(-30, 0, 0)
+	turnsThirtyTomorrow := today.AddDate(-30, 0, 1)
+	docs := []document.Person{
+		{Name: "Lucas", Age: 12, BirthDate: &turnsThirtyToday},
+		{Name: "Ana", Age: 12, BirthDate: &turnsThirtyTomorrow},
+		{Name: "Bruno", Age: 12},
+	}
+
+	personMapper := &mapper.PersonMapper{}
+	dtos := personMapper.ListDocumentToListDto(docs).([]dto2.Person)
+
+	assert.Equal(t, 30, *dtos[0].Age)
+	assert.Equal(t, 29, *dtos[1].Age)
+	assert.Equal(t, 12, *dtos[2].Age)
+	assert.Equal(t, dto2.Date{Time: turnsThirtyToday}, *dtos[0].BirthDate)
+	assert.Nil(t, dtos[2].BirthDate)
+
+	assert.Equal(t, 30, *personMapper.DocumentToDto(docs[0]).(dto2.Person).Age)
+}
+
+func TestShouldMapContactsAndDocuments(t *testing.T) {
+
+	birthDate := dto2.NewDate(1990, time.May, 17)
+	dto := dto2.Person{
+		Name:      "Lucas",
+		Age:       intPointer(1),
+		BirthDate: &birthDate,
+		Phones:    []dto2.Phone{{Type: "mobile", Number: "+5511987654321"}},
+		Addresses: []dto2.Address{{Street: "Avenida Paulista", City: "São Paulo", Country: "BR"}},
+		Documents: []dto2.Identifier{{Type: "cpf", Number: "52998224725"}},
+	}
+
+	personMapper := &mapper.PersonMapper{}
+	doc := personMapper.DtoToDocument(&dto)
+
+	assert.Equal(t, time.Date(1990, time.May, 17, 0, 0, 0, 0, time.UTC), *doc.BirthDate)
+	assert.Equal(t, doc.AgeAt(time.Now()), doc.Age)
+	assert.Equal(t, []document.Phone{{Type: "mobile", Number: "+5511987654321"}}, doc.Phones)
+	assert.Equal(t, []document.Address{{Street: "Avenida Paulista", City: "São Paulo", Country: "BR"}}, doc.Addresses)
+	assert.Equal(t, []document.Identifier{{Type: "cpf", Number: "52998224725"}}, doc.Documents)
+
+	back := personMapper.DocumentToDto(doc).(dto2.Person)
+
+	assert.Equal(t, dto.BirthDate, back.BirthDate)
+	assert.Equal(t, dto.Phones, back.Phones)
+	assert.Equal(t, dto.Addresses, back.Addresses)
+	assert.Equal(t, dto.Documents, back.Documents)
+}
+
+func TestShouldUpdateOnlyFieldsSent(t *testing.T) {
+
+	personMapper := &mapper.PersonMapper{}
+
+	assert.Equal(t, []string{"name", "email", "age"}, personMapper.UpdatedFields(&dto2.Person{Name: "Lucas"}))
+	assert.Equal(t, []string{"name", "email", "age", "phones", "documents"},
+		personMapper.UpdatedFields(&dto2.Person{Phones: []dto2.Phone{}, Documents: []dto2.Identifier{}}))
+	assert.Equal(t, []string{"name", "email", "age", "birthDate", "phones", "addresses", "documents"},
+		(&mapper.PersonMapperV2{}).UpdatedFields(&v2.Person{}))
+}
+
+func TestShouldRejectDtoOfAnotherVersion(t *testing.T) {
+
+	personMapper := &mapper.PersonMapper{}
+
+	assert.Panics(t, func() { personMapper.DtoToDocument(&v2.Person{}) })
+}
+
+func intPointer(value int) *int {
+	return &value
 }
@@ -0,0 +1,89 @@
+package mapper
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"person/internal/document"
+	dto2 "person/internal/dto"
+	v2 "person/internal/dto/v2"
+	"person/internal/mapper"
+	"testing"
+	"time"
+)
+
+func TestShouldSplitNameAndGroupContact(t *testing.T) {
+
+	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
+	birthDate := time.Date(1990, time.May, 17, 0, 0, 0, 0, time.UTC)
+	docs := []document.Person{
+		{
+			Id:        objID,
+			Name:      "João  da Silva",
+			Email:     "joao@gmail.com",
+			Age:       30,
+			BirthDate: &birthDate,
+			Phones:    []document.Phone{{Type: "mobile", Number: "+5511987654321"}},
+		},
+		{Id: objID, Name: "Ana", Email: "ana@gmail.com", Age: 20},
+	}
+
+	personMapper := &mapper.PersonMapperV2{}
+	dtos := personMapper.ListDocumentToListDto(docs).([]v2.Person)
+
+	assert.Len(t, dtos, 2)
+	assert.Equal(t, objID, dtos[0].Id)
+	assert.Equal(t, v2.Name{First: "João", Last: "da Silva"}, dtos[0].Name)
+	assert.Equal(t, dto2.NewDate(1990, time.May, 17), *dtos[0].BirthDate)
+	assert.Equal(t, v2.Contact{Email: "joao@gmail.com", Phones: []dto2.Phone{{Type: "mobile", Number: "+5511987654321"}}}, dtos[0].Contact)
+	assert.Equal(t, v2.Name{First: "Ana"}, dtos[1].Name)
+	assert.Nil(t, dtos[1].BirthDate)
+}
+
+func TestShouldJoinNameAndComputeAge(t *testing.T) {
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	birthDate := dto2.Date{Time: today.AddDate(-30, 0, 0)}
+	dto := v2.Person{
+		Name:      v2.Name{First: "João", Last: "da Silva"},
+		BirthDate: &birthDate,
+		Contact:   v2.Contact{Email: "joao@gmail.com"},
+		Addresses: []dto2.Address{{Street: "Avenida Paulista", City: "São Paulo", Country: "BR"}},
+	}
+
+	personMapper := &mapper.PersonMapperV2{}
+	doc := personMapper.DtoToDocument(&dto)
+
+	assert.Equal(t, "João da Silva", doc.Name)
+	assert.Equal(t, "joao@gmail.com", doc.Email)
+	assert.Equal(t, 30, doc.Age)
+	assert.Equal(t, birthDate.Time, *doc.BirthDate)
+	assert.Equal(t, []document.Address{{Street: "Avenida Paulista", City: "São Paulo", Country: "BR"}}, doc.Addresses)
+
+	doc = personMapper.DtoToDocument(&v2.Person{Name: v2.Name{First: "Ana"}})
+
+	assert.Equal(t, "Ana", doc.Name)
+
+	assert.Panics(t, func() { personMapper.DtoToDocument(&dto2.Person{}) })
+}
+
+func TestShouldReturnListRevisionV2DTOFilled(t *testing.T) {
+
+	objID, _ := primitive.ObjectIDFromHex("5f165e2e4de9b442e60b3904")
+	revisions := []document.Revision{
+		{
+			PersonId:  objID,
+			Version:   2,
+			Operation: document.Updated,
+			Before:    &document.Person{Id: objID, Name: "Lucas", Version: 1},
+			After:     &document.Person{Id: objID, Name: "Lucas Silva", Version: 2},
+		},
+	}
+
+	personMapper := &mapper.PersonMapperV2{}
+	dtos := personMapper.ListRevisionToListDto(revisions).([]v2.Revision)
+
+	assert.Len(t, dtos, 1)
+	assert.Equal(t, int64(2), dtos[0].Version)
+	assert.Equal(t, v2.Name{First: "Lucas"}, dtos[0].Before.Name)
+	assert.Equal(t, v2.Name{First: "Lucas", Last: "Silva"}, dtos[0].After.Name)
+}
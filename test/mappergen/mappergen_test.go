@@ -0,0 +1,64 @@
+package mappergen
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"person/internal/mappergen"
+	"testing"
+)
+
+func TestGeneratedMapperIsUpToDate(t *testing.T) {
+
+	source, err := mappergen.Generate(mappergen.Config{
+		From:   "person/internal/document",
+		To:     "person/internal/dto",
+		Types:  []string{"Person", "Phone", "Address", "Identifier", "Revision"},
+		Dir:    "../../internal/mapper",
+		Output: "personmapper_gen.go",
+	})
+	generated, _ := ioutil.ReadFile("../../internal/mapper/personmapper_gen.go")
+
+	assert.Nil(t, err)
+	assert.Equal(t, string(generated), string(source), "run go generate ./internal/mapper")
+}
+
+func TestGenerateUsingConvertersAndSkippingTaggedFields(t *testing.T) {
+
+	source, err := generate("Person")
+
+	assert.Nil(t, err)
+	assert.Contains(t, string(source), "BirthDate: timeToDate(person.BirthDate),")
+	assert.Contains(t, string(source), "BirthDate: dateToTime(person.BirthDate),")
+	assert.NotContains(t, string(source), "Version")
+}
+
+func TestGenerateFailingWhenFieldHasNoCounterpart(t *testing.T) {
+
+	_, err := generate("Missing")
+
+	assert.EqualError(t, err, `from.Missing.Nickname has no counterpart in to.Missing, tag it mapper:"-" to leave it out`)
+}
+
+func TestGenerateFailingWhenFieldCannotBeConverted(t *testing.T) {
+
+	_, err := generate("Unconvertible")
+
+	assert.EqualError(t, err, "cannot convert from.Unconvertible.Age from int to string")
+}
+
+func TestGenerateFailingWhenTypeIsNotAStruct(t *testing.T) {
+
+	_, err := generate("Date")
+
+	assert.EqualError(t, err, "from.Date is not a struct")
+}
+
+func generate(types ...string) ([]byte, error) {
+	return mappergen.Generate(mappergen.Config{
+		From:   "person/test/mappergen/testdata/from",
+		To:     "person/test/mappergen/testdata/to",
+		Types:  types,
+		Dir:    "testdata/out",
+		Output: "mapper_gen.go",
+	})
+}
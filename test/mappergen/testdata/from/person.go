@@ -0,0 +1,18 @@
+package from
+
+import "time"
+
+type Person struct {
+	Name      string
+	BirthDate time.Time
+	Version   int64 `mapper:"-"`
+}
+
+type Missing struct {
+	Name     string
+	Nickname string
+}
+
+type Unconvertible struct {
+	Age int
+}
@@ -0,0 +1,15 @@
+package out
+
+import (
+	"person/test/mappergen/testdata/to"
+	"time"
+)
+
+func timeToDate(t time.Time) to.Date {
+	return to.Date{Day: t.Format("2006-01-02")}
+}
+
+func dateToTime(d to.Date) time.Time {
+	t, _ := time.Parse("2006-01-02", d.Day)
+	return t
+}
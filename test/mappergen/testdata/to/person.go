@@ -0,0 +1,18 @@
+package to
+
+type Person struct {
+	Name      string
+	BirthDate Date
+}
+
+type Date struct {
+	Day string
+}
+
+type Missing struct {
+	Name string
+}
+
+type Unconvertible struct {
+	Age string
+}
@@ -7,7 +7,6 @@ package mocks
 import (
 	gomock "github.com/golang/mock/gomock"
 	document "person/internal/document"
-	dto "person/internal/dto"
 	reflect "reflect"
 )
 
@@ -34,13 +33,26 @@ func (m *MockMapper) EXPECT() *MockMapperMockRecorder {
 	return m.recorder
 }
 
+// NewDto mocks base method
+func (m *MockMapper) NewDto() interface{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewDto")
+	ret0, _ := ret[0].(interface{})
+	return ret0
+}
+
+// NewDto indicates an expected call of NewDto
+func (mr *MockMapperMockRecorder) NewDto() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewDto", reflect.TypeOf((*MockMapper)(nil).NewDto))
+}
+
 // DocumentToDto mocks base method
-func (m *MockMapper) DocumentToDto(document document.Person) (dto.Person, error) {
+func (m *MockMapper) DocumentToDto(document document.Person) interface{} {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DocumentToDto", document)
-	ret0, _ := ret[0].(dto.Person)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret0, _ := ret[0].(interface{})
+	return ret0
 }
 
 // DocumentToDto indicates an expected call of DocumentToDto
@@ -50,12 +62,11 @@ func (mr *MockMapperMockRecorder) DocumentToDto(document interface{}) *gomock.Ca
 }
 
 // ListDocumentToListDto mocks base method
-func (m *MockMapper) ListDocumentToListDto(document []document.Person) ([]dto.Person, error) {
+func (m *MockMapper) ListDocumentToListDto(document []document.Person) interface{} {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListDocumentToListDto", document)
-	ret0, _ := ret[0].([]dto.Person)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret0, _ := ret[0].(interface{})
+	return ret0
 }
 
 // ListDocumentToListDto indicates an expected call of ListDocumentToListDto
@@ -65,12 +76,11 @@ func (mr *MockMapperMockRecorder) ListDocumentToListDto(document interface{}) *g
 }
 
 // DtoToDocument mocks base method
-func (m *MockMapper) DtoToDocument(dto dto.Person) (document.Person, error) {
+func (m *MockMapper) DtoToDocument(dto interface{}) document.Person {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DtoToDocument", dto)
 	ret0, _ := ret[0].(document.Person)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	return ret0
 }
 
 // DtoToDocument indicates an expected call of DtoToDocument
@@ -78,3 +88,31 @@ func (mr *MockMapperMockRecorder) DtoToDocument(dto interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DtoToDocument", reflect.TypeOf((*MockMapper)(nil).DtoToDocument), dto)
 }
+
+// UpdatedFields mocks base method
+func (m *MockMapper) UpdatedFields(dto interface{}) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatedFields", dto)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// UpdatedFields indicates an expected call of UpdatedFields
+func (mr *MockMapperMockRecorder) UpdatedFields(dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatedFields", reflect.TypeOf((*MockMapper)(nil).UpdatedFields), dto)
+}
+
+// ListRevisionToListDto mocks base method
+func (m *MockMapper) ListRevisionToListDto(revisions []document.Revision) interface{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevisionToListDto", revisions)
+	ret0, _ := ret[0].(interface{})
+	return ret0
+}
+
+// ListRevisionToListDto indicates an expected call of ListRevisionToListDto
+func (mr *MockMapperMockRecorder) ListRevisionToListDto(revisions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevisionToListDto", reflect.TypeOf((*MockMapper)(nil).ListRevisionToListDto), revisions)
+}
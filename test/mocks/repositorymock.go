@@ -5,10 +5,13 @@
 package mocks
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	primitive "go.mongodb.org/mongo-driver/bson/primitive"
 	document "person/internal/document"
+	repository "person/internal/repository"
 	reflect "reflect"
+	time "time"
 )
 
 // MockRepository is a mock of Repository interface
@@ -35,76 +38,213 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 }
 
 // Find mocks base method
-func (m *MockRepository) Find() ([]document.Person, error) {
+func (m *MockRepository) Find(ctx context.Context, query repository.Query) ([]document.Person, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Find")
+	ret := m.ctrl.Call(m, "Find", ctx, query)
 	ret0, _ := ret[0].([]document.Person)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // Find indicates an expected call of Find
-func (mr *MockRepositoryMockRecorder) Find() *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Find(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockRepository)(nil).Find), ctx, query)
+}
+
+// Stream mocks base method
+func (m *MockRepository) Stream(ctx context.Context, query repository.Query, fn func(document.Person) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stream", ctx, query, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stream indicates an expected call of Stream
+func (mr *MockRepositoryMockRecorder) Stream(ctx, query, fn interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockRepository)(nil).Find))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stream", reflect.TypeOf((*MockRepository)(nil).Stream), ctx, query, fn)
+}
+
+// Search mocks base method
+func (m *MockRepository) Search(ctx context.Context, query repository.Query) ([]document.Person, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query)
+	ret0, _ := ret[0].([]document.Person)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Search indicates an expected call of Search
+func (mr *MockRepositoryMockRecorder) Search(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockRepository)(nil).Search), ctx, query)
 }
 
 // FindById mocks base method
-func (m *MockRepository) FindById(id string) (document.Person, error) {
+func (m *MockRepository) FindById(ctx context.Context, id string) (document.Person, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindById", id)
+	ret := m.ctrl.Call(m, "FindById", ctx, id)
 	ret0, _ := ret[0].(document.Person)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindById indicates an expected call of FindById
-func (mr *MockRepositoryMockRecorder) FindById(id interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) FindById(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockRepository)(nil).FindById), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockRepository)(nil).FindById), ctx, id)
 }
 
 // Create mocks base method
-func (m *MockRepository) Create(document2 document.Person) (document.Person, error) {
+func (m *MockRepository) Create(ctx context.Context, document2 document.Person) (document.Person, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", document2)
+	ret := m.ctrl.Call(m, "Create", ctx, document2)
 	ret0, _ := ret[0].(document.Person)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create
-func (mr *MockRepositoryMockRecorder) Create(document interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Create(ctx, document interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), document)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), ctx, document)
 }
 
 // Update mocks base method
-func (m *MockRepository) Update(document document.Person) (int64, error) {
+func (m *MockRepository) Update(ctx context.Context, document2 document.Person) (document.Person, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", document)
-	ret0, _ := ret[0].(int64)
+	ret := m.ctrl.Call(m, "Update", ctx, document2)
+	ret0, _ := ret[0].(document.Person)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Update indicates an expected call of Update
-func (mr *MockRepositoryMockRecorder) Update(document interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Update(ctx, document interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), ctx, document)
+}
+
+// Patch mocks base method
+func (m *MockRepository) Patch(ctx context.Context, document2 document.Person, fields []string) (document.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", ctx, document2, fields)
+	ret0, _ := ret[0].(document.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Patch indicates an expected call of Patch
+func (mr *MockRepositoryMockRecorder) Patch(ctx, document, fields interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), document)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockRepository)(nil).Patch), ctx, document, fields)
 }
 
 // Delete mocks base method
-func (m *MockRepository) Delete(id primitive.ObjectID) (int64, error) {
+func (m *MockRepository) Delete(ctx context.Context, id primitive.ObjectID, version int64) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Delete indicates an expected call of Delete
-func (mr *MockRepositoryMockRecorder) Delete(id interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), ctx, id, version)
+}
+
+// Restore mocks base method
+func (m *MockRepository) Restore(ctx context.Context, id primitive.ObjectID) (document.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(document.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore
+func (mr *MockRepositoryMockRecorder) Restore(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockRepository)(nil).Restore), ctx, id)
+}
+
+// Purge mocks base method
+func (m *MockRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, deletedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge
+func (mr *MockRepositoryMockRecorder) Purge(ctx, deletedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockRepository)(nil).Purge), ctx, deletedBefore)
+}
+
+// History mocks base method
+func (m *MockRepository) History(ctx context.Context, id primitive.ObjectID, query repository.Query) ([]document.Revision, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, id, query)
+	ret0, _ := ret[0].([]document.Revision)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// History indicates an expected call of History
+func (mr *MockRepositoryMockRecorder) History(ctx, id, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockRepository)(nil).History), ctx, id, query)
+}
+
+// FindAsOf mocks base method
+func (m *MockRepository) FindAsOf(ctx context.Context, id primitive.ObjectID, asOf time.Time) (document.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAsOf", ctx, id, asOf)
+	ret0, _ := ret[0].(document.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAsOf indicates an expected call of FindAsOf
+func (mr *MockRepositoryMockRecorder) FindAsOf(ctx, id, asOf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAsOf", reflect.TypeOf((*MockRepository)(nil).FindAsOf), ctx, id, asOf)
+}
+
+// FindRevision mocks base method
+func (m *MockRepository) FindRevision(ctx context.Context, id primitive.ObjectID, version int64) (document.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRevision", ctx, id, version)
+	ret0, _ := ret[0].(document.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRevision indicates an expected call of FindRevision
+func (mr *MockRepositoryMockRecorder) FindRevision(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRevision", reflect.TypeOf((*MockRepository)(nil).FindRevision), ctx, id, version)
+}
+
+// Bulk mocks base method
+func (m *MockRepository) Bulk(ctx context.Context, operations []repository.BulkOperation, ordered bool) ([]repository.BulkResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Bulk", ctx, operations, ordered)
+	ret0, _ := ret[0].([]repository.BulkResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Bulk indicates an expected call of Bulk
+func (mr *MockRepositoryMockRecorder) Bulk(ctx, operations, ordered interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bulk", reflect.TypeOf((*MockRepository)(nil).Bulk), ctx, operations, ordered)
 }
@@ -0,0 +1,101 @@
+package normalize
+
+import (
+	"github.com/stretchr/testify/assert"
+	"person/internal/dto"
+	v2 "person/internal/dto/v2"
+	"person/internal/normalize"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+
+	tests := []struct {
+		rule   string
+		value  string
+		result string
+	}{
+		{"trim", "  JOHN@Example.COM \n", "JOHN@Example.COM"},
+		{"trim", "joão", "joão"},
+		{"collapse", "joão  \t silva", "joão silva"},
+		{"collapse", "  joão  ", " joão "},
+		{"collapse", "joão  silva", "joão silva"},
+		{"lowercase", "JOHN@Example.COM", "john@example.com"},
+		{"lowercase", "ÉLODIE@EXEMPLO.COM", "élodie@exemplo.com"},
+		{"nfc", "joa\u0303o", "jo\u00e3o"},
+		{"nfc", "jo\u00e3o", "jo\u00e3o"},
+		{"title", "joão da silva", "João da Silva"},
+		{"title", "JOÃO DOS SANTOS E SILVA", "João dos Santos e Silva"},
+		{"title", "de la cruz", "de la Cruz"},
+		{"title", "DA", "Da"},
+		{"title", "maria-josé o'neil", "Maria-José O'neil"},
+		{"title", "", ""},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.result, normalize.Rules[test.rule](test.value), "%s %q", test.rule, test.value)
+	}
+}
+
+func TestStruct(t *testing.T) {
+
+	normalizer, err := normalize.New(map[string][]string{
+		"name":   {"trim", "collapse", "nfc", "title"},
+		"email":  {"trim", "lowercase"},
+		"street": {"collapse"},
+	})
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name   string
+		body   interface{}
+		result interface{}
+	}{
+		{
+			"v1",
+			&dto.Person{
+				Name:      "  joão   silva ",
+				Email:     "  JOHN@Example.COM ",
+				Addresses: []dto.Address{{Street: "Avenida  Paulista", City: "  São Paulo"}},
+			},
+			&dto.Person{
+				Name:      "João Silva",
+				Email:     "john@example.com",
+				Addresses: []dto.Address{{Street: "Avenida Paulista", City: "  São Paulo"}},
+			},
+		},
+		{
+			"v2",
+			&v2.Person{
+				Name:    v2.Name{First: " joão ", Last: "DA  SILVA"},
+				Contact: v2.Contact{Email: "JOHN@Example.COM", Phones: []dto.Phone{{Number: " +5511987654321"}}},
+			},
+			&v2.Person{
+				Name:    v2.Name{First: "João", Last: "da Silva"},
+				Contact: v2.Contact{Email: "john@example.com", Phones: []dto.Phone{{Number: " +5511987654321"}}},
+			},
+		},
+		{"nil", (*dto.Person)(nil), (*dto.Person)(nil)},
+	}
+
+	for _, test := range tests {
+		normalizer.Struct(test.body)
+		assert.Equal(t, test.result, test.body, test.name)
+	}
+}
+
+func TestZeroNormalizerChangesNothing(t *testing.T) {
+
+	body := &dto.Person{Name: "  joão  silva ", Email: " JOHN@Example.COM"}
+
+	normalize.Normalizer{}.Struct(body)
+
+	assert.Equal(t, &dto.Person{Name: "  joão  silva ", Email: " JOHN@Example.COM"}, body)
+}
+
+func TestNewFailingOnUnknownRule(t *testing.T) {
+
+	_, err := normalize.New(map[string][]string{"name": {"trim", "upper"}})
+
+	assert.EqualError(t, err, `unknown rule "upper" for field name`)
+}
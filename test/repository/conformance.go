@@ -0,0 +1,1004 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"person/internal/document"
+	"person/internal/repository"
+	"testing"
+	"time"
+)
+
+// Factory returns an empty repository, registering on t any cleanup it needs.
+type Factory func(t *testing.T) repository.Repository
+
+// Conformance checks the behaviour PersonHandler expects from every repository.Repository.
+func Conformance(t *testing.T, factory Factory) {
+	t.Run("CreateAndFindById", func(t *testing.T) { testCreateAndFindById(t, factory(t)) })
+	t.Run("FindByIdNotFound", func(t *testing.T) { testFindByIdNotFound(t, factory(t)) })
+	t.Run("FindByIdInvalidId", func(t *testing.T) { testFindByIdInvalidId(t, factory(t)) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, factory(t)) })
+	t.Run("UpdateNotFound", func(t *testing.T) { testUpdateNotFound(t, factory(t)) })
+	t.Run("Patch", func(t *testing.T) { testPatch(t, factory(t)) })
+	t.Run("ContactsAndDocuments", func(t *testing.T) { testContactsAndDocuments(t, factory(t)) })
+	t.Run("DuplicateEmail", func(t *testing.T) { testDuplicateEmail(t, factory(t)) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, factory(t)) })
+	t.Run("SoftDeleteAndRestore", func(t *testing.T) { testSoftDeleteAndRestore(t, factory(t)) })
+	t.Run("Purge", func(t *testing.T) { testPurge(t, factory(t)) })
+	t.Run("Audit", func(t *testing.T) { testAudit(t, factory(t)) })
+	t.Run("FindEmpty", func(t *testing.T) { testFindEmpty(t, factory(t)) })
+	t.Run("FindFiltered", func(t *testing.T) { testFindFiltered(t, factory(t)) })
+	t.Run("FindByAgeFromBirthDate", func(t *testing.T) { testFindByAgeFromBirthDate(t, factory(t)) })
+	t.Run("FindSortedAndPaginated", func(t *testing.T) { testFindSortedAndPaginated(t, factory(t)) })
+	t.Run("FindWithCursor", func(t *testing.T) { testFindWithCursor(t, factory(t)) })
+	t.Run("History", func(t *testing.T) { testHistory(t, factory(t)) })
+	t.Run("FindAsOf", func(t *testing.T) { testFindAsOf(t, factory(t)) })
+	t.Run("FindRevisionAndRevert", func(t *testing.T) { testFindRevisionAndRevert(t, factory(t)) })
+	t.Run("Bulk", func(t *testing.T) { testBulk(t, factory(t)) })
+	t.Run("BulkOrdered", func(t *testing.T) { testBulkOrdered(t, factory(t)) })
+	t.Run("Stream", func(t *testing.T) { testStream(t, factory(t)) })
+	t.Run("Search", func(t *testing.T) { testSearch(t, factory(t)) })
+	t.Run("SearchFuzzy", func(t *testing.T) { testSearchFuzzy(t, factory(t)) })
+	t.Run("SearchFuzzyAmongMany", func(t *testing.T) { testSearchFuzzyAmongMany(t, factory(t)) })
+	t.Run("CanceledContext", func(t *testing.T) { testCanceledContext(t, factory(t)) })
+}
+
+func query() repository.Query {
+	return repository.Query{Page: repository.DefaultPage, Size: repository.DefaultSize}
+}
+
+func create(t *testing.T, repo repository.Repository, people ...document.Person) []document.Person {
+
+	var created []document.Person
+
+	for _, person := range people {
+		person, err := repo.Create(context.Background(), person)
+		assert.Nil(t, err)
+		created = append(created, person)
+	}
+
+	return created
+}
+
+func ids(people []document.Person) []primitive.ObjectID {
+
+	var result []primitive.ObjectID
+
+	for _, person := range people {
+		result = append(result, person.Id)
+	}
+
+	return result
+}
+
+// assertSameData compares what a client can change plus the identity and version.
+func assertSameData(t *testing.T, expected document.Person, actual document.Person) {
+	assert.Equal(t, expected.Id, actual.Id)
+	assert.Equal(t, expected.Name, actual.Name)
+	assert.Equal(t, expected.Email, actual.Email)
+	assert.Equal(t, expected.Age, actual.Age)
+	assertSameTime(t, expected.BirthDate, actual.BirthDate)
+	assert.ElementsMatch(t, expected.Phones, actual.Phones)
+	assert.ElementsMatch(t, expected.Addresses, actual.Addresses)
+	assert.ElementsMatch(t, expected.Documents, actual.Documents)
+	assert.Equal(t, expected.Version, actual.Version)
+}
+
+// assertSamePerson compares every field, times by instant since drivers may decode them in another location.
+func assertSamePerson(t *testing.T, expected document.Person, actual document.Person) {
+	assertSameData(t, expected, actual)
+	assert.Equal(t, expected.CreatedBy, actual.CreatedBy)
+	assert.Equal(t, expected.UpdatedBy, actual.UpdatedBy)
+	assertSameTime(t, expected.CreatedAt, actual.CreatedAt)
+	assertSameTime(t, expected.UpdatedAt, actual.UpdatedAt)
+	assertSameTime(t, expected.DeletedAt, actual.DeletedAt)
+}
+
+func assertSameTime(t *testing.T, expected *time.Time, actual *time.Time) {
+	if expected == nil || actual == nil {
+		assert.Equal(t, expected, actual)
+		return
+	}
+	assert.True(t, expected.Equal(*actual), "expected %s, actual %s", expected, actual)
+}
+
+func names(people []document.Person) []string {
+
+	var result []string
+
+	for _, person := range people {
+		result = append(result, person.Name)
+	}
+
+	return result
+}
+
+func testCreateAndFindById(t *testing.T, repo repository.Repository) {
+
+	deletedAt := time.Now()
+	created := create(t, repo, document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22, DeletedAt: &deletedAt})[0]
+
+	assert.False(t, created.Id.IsZero())
+	assert.Nil(t, created.DeletedAt, "a person is never created deleted")
+
+	found, err := repo.FindById(context.Background(), created.Id.Hex())
+
+	assert.Nil(t, err)
+	assertSamePerson(t, created, found)
+}
+
+func testFindByIdNotFound(t *testing.T, repo repository.Repository) {
+
+	_, err := repo.FindById(context.Background(), primitive.NewObjectID().Hex())
+
+	assert.Equal(t, repository.ErrNotFound, err)
+}
+
+func testFindByIdInvalidId(t *testing.T, repo repository.Repository) {
+
+	_, err := repo.FindById(context.Background(), "5f165e2e4de9b442e60b3")
+
+	assert.NotNil(t, err)
+}
+
+func testUpdate(t *testing.T, repo repository.Repository) {
+
+	created := create(t, repo, document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22})[0]
+	changed := document.Person{Id: created.Id, Name: "Lucas Silva", Email: "silva@gmail.com", Age: 23}
+
+	updated, err := repo.Update(context.Background(), changed)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), created.Version)
+	changed.Version = 2
+	assertSameData(t, changed, updated)
+
+	found, err := repo.FindById(context.Background(), created.Id.Hex())
+
+	assert.Nil(t, err)
+	assertSamePerson(t, updated, found)
+
+	updated, err = repo.Update(context.Background(), changed)
+
+	assert.Nil(t, err, "an update with the current version")
+	assert.Equal(t, int64(3), updated.Version)
+
+	_, err = repo.Update(context.Background(), changed)
+
+	assert.Equal(t, repository.ErrVersionMismatch, err)
+}
+
+func testUpdateNotFound(t *testing.T, repo repository.Repository) {
+
+	_, err := repo.Update(context.Background(), document.Person{Id: primitive.NewObjectID(), Name: "Lucas"})
+
+	assert.Equal(t, repository.ErrNotFound, err)
+
+	_, err = repo.Update(context.Background(), document.Person{Id: primitive.NewObjectID(), Name: "Lucas", Version: 1})
+
+	assert.Equal(t, repository.ErrNotFound, err)
+}
+
+func testPatch(t *testing.T, repo repository.Repository) {
+
+	created := create(t, repo, document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22})[0]
+	patch := document.Person{Id: created.Id, Name: "Ignored", Email: "new@gmail.com", Age: 99}
+
+	patched, err := repo.Patch(context.Background(), patch, []string{"email"})
+
+	assert.Nil(t, err)
+	assertSameData(t, document.Person{Id: created.Id, Name: "Lucas", Email: "new@gmail.com", Age: 22, Version: 2}, patched)
+
+	found, err := repo.FindById(context.Background(), created.Id.Hex())
+
+	assert.Nil(t, err)
+	assertSamePerson(t, patched, found)
+
+	patch.Version = 1
+	_, err = repo.Patch(context.Background(), patch, []string{"name"})
+
+	assert.Equal(t, repository.ErrVersionMismatch, err)
+
+	_, err = repo.Patch(context.Background(), document.Person{Id: primitive.NewObjectID()}, []string{"name"})
+
+	assert.Equal(t, repository.ErrNotFound, err)
+}
+
+func testContactsAndDocuments(t *testing.T, repo repository.Repository) {
+
+	birthDate := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	created := create(t, repo, document.Person{
+		Name:      "Lucas",
+		Email:     "lucas@gmail.com",
+		Age:       30,
+		BirthDate: &birthDate,
+		Phones:    []document.Phone{{Type: "mobile", Number: "+5511987654321"}},
+		Addresses: []document.Address{{Type: "home", Street: "Avenida Paulista", Number: "1000", City: "São Paulo", State: "SP", PostalCode: "01310-100", Country: "BR"}},
+		Documents: []document.Identifier{{Type: "cpf", Number: "52998224725"}},
+	})[0]
+
+	found, err := repo.FindById(context.Background(), created.Id.Hex())
+
+	assert.Nil(t, err)
+	assertSamePerson(t, created, found)
+
+	changed := found
+	changed.BirthDate = nil
+	changed.Phones = append(changed.Phones, document.Phone{Type: "work", Number: "+551130000000"})
+	changed.Addresses = nil
+	updated, err := repo.Update(context.Background(), changed)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"birthDate", "phones", "addresses"}, repository.Changes(found, updated))
+
+	found, err = repo.FindById(context.Background(), created.Id.Hex())
+
+	assert.Nil(t, err)
+	assertSamePerson(t, updated, found)
+	assert.Nil(t, found.BirthDate)
+	assert.Len(t, found.Phones, 2)
+	assert.Empty(t, found.Addresses)
+	assert.Equal(t, created.Documents, found.Documents)
+}
+
+func testDuplicateEmail(t *testing.T, repo repository.Repository) {
+
+	created := create(t, repo,
+		document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22},
+		document.Person{Name: "Ana", Email: "ana@gmail.com", Age: 30},
+	)
+
+	_, err := repo.Create(context.Background(), document.Person{Name: "Other Lucas", Email: "LUCAS@gmail.com", Age: 40})
+
+	assert.Equal(t, repository.DuplicateEmailError{Id: created[0].Id}, err)
+
+	ana := created[1]
+	ana.Email = "Lucas@Gmail.com"
+	_, err = repo.Update(context.Background(), ana)
+
+	assert.Equal(t, repository.DuplicateEmailError{Id: created[0].Id}, err)
+
+	_, err = repo.Patch(context.Background(), ana, []string{"email"})
+
+	assert.Equal(t, repository.DuplicateEmailError{Id: created[0].Id}, err)
+
+	lucas := created[0]
+	lucas.Email = "Lucas@Gmail.com"
+	updated, err := repo.Update(context.Background(), lucas)
+
+	assert.Nil(t, err, "a person keeps its own email with another case")
+	assert.Equal(t, "Lucas@Gmail.com", updated.Email)
+
+	_, err = repo.Delete(context.Background(), lucas.Id, 0)
+	assert.Nil(t, err)
+
+	other, err := repo.Create(context.Background(), document.Person{Name: "Other Lucas", Email: "LUCAS@gmail.com", Age: 40})
+
+	assert.Nil(t, err, "the email of a deleted person can be used again")
+
+	_, err = repo.Restore(context.Background(), lucas.Id)
+
+	assert.Equal(t, repository.DuplicateEmailError{Id: other.Id}, err, "a person is not restored over the email taken meanwhile")
+
+	_, err = repo.FindById(context.Background(), lucas.Id.Hex())
+
+	assert.Equal(t, repository.ErrNotFound, err)
+}
+
+func testDelete(t *testing.T, repo repository.Repository) {
+
+	created := create(t, repo, document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22})[0]
+
+	count, err := repo.Delete(context.Background(), created.Id, created.Version+1)
+
+	assert.Equal(t, repository.ErrVersionMismatch, err)
+	assert.Equal(t, int64(0), count)
+
+	count, err = repo.Delete(context.Background(), created.Id, created.Version)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	_, err = repo.FindById(context.Background(), created.Id.Hex())
+
+	assert.Equal(t, repository.ErrNotFound, err)
+
+	count, err = repo.Delete(context.Background(), created.Id, 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+
+	_, err = repo.Delete(context.Background(), created.Id, created.Version)
+
+	assert.Equal(t, repository.ErrNotFound, err)
+}
+
+func testSoftDeleteAndRestore(t *testing.T, repo repository.Repository) {
+
+	created := create(t, repo,
+		document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22},
+		document.Person{Name: "Ana", Email: "ana@gmail.com", Age: 30},
+	)
+	lucas := created[0]
+
+	_, err := repo.Restore(context.Background(), lucas.Id)
+
+	assert.Equal(t, repository.ErrNotFound, err, "only deleted people are restored")
+
+	count, err := repo.Delete(context.Background(), lucas.Id, 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	people, total, err := repo.Find(context.Background(), query())
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, []string{"Ana"}, names(people))
+
+	q := query()
+	q.IncludeDeleted = true
+	q.Sort = []repository.Sort{{Field: "name"}}
+	people, total, err = repo.Find(context.Background(), q)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, []string{"Ana", "Lucas"}, names(people))
+	assert.Nil(t, people[0].DeletedAt)
+	assert.NotNil(t, people[1].DeletedAt)
+
+	_, err = repo.Update(context.Background(), lucas)
+
+	assert.Equal(t, repository.ErrNotFound, err, "a deleted person cannot be updated")
+
+	restored, err := repo.Restore(context.Background(), lucas.Id)
+
+	assert.Nil(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, int64(3), restored.Version)
+
+	found, err := repo.FindById(context.Background(), lucas.Id.Hex())
+
+	assert.Nil(t, err)
+	assertSamePerson(t, restored, found)
+}
+
+func testPurge(t *testing.T, repo repository.Repository) {
+
+	created := create(t, repo,
+		document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22},
+		document.Person{Name: "Ana", Email: "ana@gmail.com", Age: 30},
+		document.Person{Name: "Bruno", Email: "bruno@gmail.com", Age: 40},
+	)
+
+	_, err := repo.Delete(context.Background(), created[0].Id, 0)
+	assert.Nil(t, err)
+
+	count, err := repo.Purge(context.Background(), time.Now().Add(-time.Hour))
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count, "people deleted after the limit are kept")
+
+	_, err = repo.Delete(context.Background(), created[1].Id, 0)
+	assert.Nil(t, err)
+
+	count, err = repo.Purge(context.Background(), time.Now().Add(time.Minute))
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
+
+	_, err = repo.Restore(context.Background(), created[0].Id)
+
+	assert.Equal(t, repository.ErrNotFound, err)
+
+	q := query()
+	q.IncludeDeleted = true
+	people, _, err := repo.Find(context.Background(), q)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Bruno"}, names(people))
+
+	for _, purged := range created[:2] {
+		revisions, total, err := repo.History(context.Background(), purged.Id, query())
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), total, "the revisions of a purged person are purged too")
+		assert.Empty(t, revisions)
+
+		_, err = repo.FindAsOf(context.Background(), purged.Id, *purged.CreatedAt)
+
+		assert.Equal(t, repository.ErrNotFound, err)
+	}
+
+	revisions, total, err := repo.History(context.Background(), created[2].Id, query())
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, revisions, 1)
+}
+
+func testAudit(t *testing.T, repo repository.Repository) {
+
+	before := time.Now().Add(-time.Second)
+	createdAt := time.Now().Add(-time.Hour)
+
+	created, err := repo.Create(repository.WithActor(context.Background(), "ana"), document.Person{
+		Name: "Lucas", Email: "lucas@gmail.com", Age: 22, CreatedAt: &createdAt, CreatedBy: "forged",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "ana", created.CreatedBy)
+	assert.Equal(t, "ana", created.UpdatedBy)
+	assert.True(t, created.CreatedAt.After(before), "creation time is set by the repository")
+	assertSameTime(t, created.CreatedAt, created.UpdatedAt)
+
+	created.Name = "Lucas Silva"
+	updated, err := repo.Update(repository.WithActor(context.Background(), "bruno"), created)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "ana", updated.CreatedBy)
+	assert.Equal(t, "bruno", updated.UpdatedBy)
+	assertSameTime(t, created.CreatedAt, updated.CreatedAt)
+	assert.False(t, updated.UpdatedAt.Before(*created.UpdatedAt))
+
+	patched, err := repo.Patch(context.Background(), updated, []string{"age"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, repository.Anonymous, patched.UpdatedBy)
+
+	_, err = repo.Delete(repository.WithActor(context.Background(), "carla"), created.Id, 0)
+	assert.Nil(t, err)
+
+	q := query()
+	q.IncludeDeleted = true
+	people, _, err := repo.Find(context.Background(), q)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "carla", people[0].UpdatedBy)
+
+	restored, err := repo.Restore(repository.WithActor(context.Background(), "diego"), created.Id)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "diego", restored.UpdatedBy)
+	assert.Equal(t, "ana", restored.CreatedBy)
+
+	q = query()
+	q.UpdatedSince = restored.UpdatedAt
+	people, total, err := repo.Find(context.Background(), q)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+
+	after := restored.UpdatedAt.Add(time.Millisecond)
+	q.UpdatedSince = &after
+	people, total, err = repo.Find(context.Background(), q)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+	assert.Empty(t, people)
+}
+
+func testFindEmpty(t *testing.T, repo repository.Repository) {
+
+	people, total, err := repo.Find(context.Background(), query())
+
+	assert.Nil(t, err)
+	assert.Empty(t, people)
+	assert.Equal(t, int64(0), total)
+}
+
+func testFindFiltered(t *testing.T, repo repository.Repository) {
+
+	create(t, repo,
+		document.Person{Name: "Lucas Silva", Email: "lucas@gmail.com", Age: 22},
+		document.Person{Name: "Ana Silva", Email: "ana@gmail.com", Age: 35},
+		document.Person{Name: "Bruno", Email: "bruno@gmail.com", Age: 40},
+	)
+
+	q := query()
+	q.Name = "SILVA"
+	q.Sort = []repository.Sort{{Field: "name"}}
+	people, total, err := repo.Find(context.Background(), q)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, []string{"Ana Silva", "Lucas Silva"}, names(people))
+
+	q = query()
+	q.Email = "Bruno@Gmail.com"
+	people, total, err = repo.Find(context.Background(), q)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, []string{"Bruno"}, names(people))
+
+	minAge, maxAge := 30, 40
+	q = query()
+	q.MinAge, q.MaxAge = &minAge, &maxAge
+	q.Sort = []repository.Sort{{Field: "age", Descending: true}}
+	people, total, err = repo.Find(context.Background(), q)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, []string{"Bruno", "Ana Silva"}, names(people))
+}
+
+func testFindByAgeFromBirthDate(t *testing.T, repo repository.Repository) {
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	turnsFortyToday := today.AddDate(-40, 0, 0)
+	turnsThirtyTomorrow := today.AddDate(-30, 0, 1)
+
+	create(t, repo,
+		document.Person{Name: "Ana", Email: "ana@gmail.com", Age: 99, BirthDate: &turnsFortyToday},
+		document.Person{Name: "Bruno", Email: "bruno@gmail.com", Age: 35},
+		document.Person{Name: "Carla", Email: "carla@gmail.com", Age: 35, BirthDate: &turnsThirtyTomorrow},
+	)
+
+	minAge, maxAge := 30, 40
+	q := query()
+	q.MinAge, q.MaxAge = &minAge, &maxAge
+	q.Sort = []repository.Sort{{Field: "name"}}
+	people, total, err := repo.Find(context.Background(), q)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, []string{"Ana", "Bruno"}, names(people))
+
+	q = query()
+	q.Sort = []repository.Sort{{Field: "age"}}
+	people, _, err = repo.Find(context.Background(), q)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Carla", "Bruno", "Ana"}, names(people), "ages sort by the birth dates")
+
+	q.Sort = []repository.Sort{{Field: "age", Descending: true}}
+	var streamed []document.Person
+	err = repo.Stream(context.Background(), q, func(person document.Person) error {
+		streamed = append(streamed, person)
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Ana", "Bruno", "Carla"}, names(streamed))
+}
+
+func testFindSortedAndPaginated(t *testing.T, repo repository.Repository) {
+
+	create(t, repo,
+		document.Person{Name: "Carla", Email: "carla@gmail.com", Age: 30},
+		document.Person{Name: "Ana", Email: "ana@gmail.com", Age: 30},
+		document.Person{Name: "Bruno", Email: "bruno@gmail.com", Age: 40},
+		document.Person{Name: "Diego", Email: "diego@gmail.com", Age: 20},
+	)
+
+	q := query()
+	q.Size = 3
+	q.Sort = []repository.Sort{{Field: "age", Descending: true}, {Field: "name"}}
+	people, total, err := repo.Find(context.Background(), q)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), total)
+	assert.Equal(t, []string{"Bruno", "Ana", "Carla"}, names(people))
+
+	q.Page = 2
+	people, total, err = repo.Find(context.Background(), q)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), total)
+	assert.Equal(t, []string{"Diego"}, names(people))
+
+	q.Page = 3
+	people, _, err = repo.Find(context.Background(), q)
+
+	assert.Nil(t, err)
+	assert.Empty(t, people)
+}
+
+func testFindWithCursor(t *testing.T, repo repository.Repository) {
+
+	created := create(t, repo,
+		document.Person{Name: "Bruno", Email: "bruno@gmail.com", Age: 40},
+		document.Person{Name: "Ana", Email: "ana@gmail.com", Age: 30},
+		document.Person{Name: "Ana", Email: "ana2@gmail.com", Age: 31},
+		document.Person{Name: "Carla", Email: "carla@gmail.com", Age: 20},
+	)
+
+	for _, sort := range [][]repository.Sort{nil, {{Field: "name"}}, {{Field: "name", Descending: true}}, {{Field: "id", Descending: true}}} {
+		q := query()
+		q.Size = 1
+		q.Sort = sort
+
+		var walked []document.Person
+
+		for {
+			people, total, err := repo.Find(context.Background(), q)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(len(created)), total, "the total does not depend on the cursor")
+
+			if len(people) == 0 {
+				break
+			}
+
+			walked = append(walked, people...)
+			last := people[len(people)-1]
+			q.After = &repository.Cursor{Id: last.Id, Name: last.Name}
+		}
+
+		q = query()
+		q.Sort = sort
+		expected, _, err := repo.Find(context.Background(), q)
+
+		assert.Nil(t, err)
+		assert.Len(t, walked, len(created))
+		assert.Equal(t, ids(expected), ids(walked))
+	}
+}
+
+func testHistory(t *testing.T, repo repository.Repository) {
+
+	ctx := repository.WithActor(context.Background(), "ana")
+	created := create(t, repo, document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22})[0]
+
+	changed := created
+	changed.Age = 23
+	updated, err := repo.Update(ctx, changed)
+	assert.Nil(t, err)
+
+	_, err = repo.Delete(ctx, created.Id, 0)
+	assert.Nil(t, err)
+
+	restored, err := repo.Restore(ctx, created.Id)
+	assert.Nil(t, err)
+
+	revisions, total, err := repo.History(context.Background(), created.Id, query())
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), total)
+	assert.Len(t, revisions, 4)
+
+	var operations []string
+	for _, revision := range revisions {
+		operations = append(operations, revision.Operation)
+		assert.Equal(t, created.Id, revision.PersonId)
+		assert.Equal(t, revision.Version, revision.After.Version)
+		assertSameTime(t, revision.After.UpdatedAt, revision.At)
+	}
+	assert.Equal(t, []string{document.Restored, document.Deleted, document.Updated, document.Created}, operations)
+
+	assertSamePerson(t, restored, *revisions[0].After)
+	assertSamePerson(t, updated, *revisions[2].After)
+	assertSamePerson(t, created, *revisions[2].Before)
+	assert.Equal(t, "ana", revisions[2].Actor)
+	assert.Nil(t, revisions[3].Before)
+	assert.Equal(t, repository.Anonymous, revisions[3].Actor)
+
+	q := query()
+	q.Page = 2
+	q.Size = 3
+	revisions, total, err = repo.History(context.Background(), created.Id, q)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), total)
+	assert.Len(t, revisions, 1)
+	assert.Equal(t, document.Created, revisions[0].Operation)
+
+	revisions, total, err = repo.History(context.Background(), primitive.NewObjectID(), query())
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+	assert.Empty(t, revisions)
+}
+
+func testFindAsOf(t *testing.T, repo repository.Repository) {
+
+	created := create(t, repo, document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22})[0]
+	time.Sleep(5 * time.Millisecond)
+
+	changed := created
+	changed.Age = 23
+	updated, err := repo.Update(context.Background(), changed)
+	assert.Nil(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = repo.Delete(context.Background(), created.Id, 0)
+	assert.Nil(t, err)
+
+	_, err = repo.FindAsOf(context.Background(), created.Id, created.CreatedAt.Add(-time.Millisecond))
+	assert.Equal(t, repository.ErrNotFound, err)
+
+	found, err := repo.FindAsOf(context.Background(), created.Id, *created.CreatedAt)
+	assert.Nil(t, err)
+	assertSamePerson(t, created, found)
+
+	found, err = repo.FindAsOf(context.Background(), created.Id, updated.UpdatedAt.Add(time.Millisecond))
+	assert.Nil(t, err)
+	assertSamePerson(t, updated, found)
+
+	_, err = repo.FindAsOf(context.Background(), created.Id, time.Now().Add(time.Second))
+	assert.Equal(t, repository.ErrNotFound, err)
+}
+
+func testFindRevisionAndRevert(t *testing.T, repo repository.Repository) {
+
+	created := create(t, repo, document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22})[0]
+
+	changed := created
+	changed.Age = 23
+	_, err := repo.Update(context.Background(), changed)
+	assert.Nil(t, err)
+
+	first, err := repo.FindRevision(context.Background(), created.Id, 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, document.Created, first.Operation)
+	assertSamePerson(t, created, *first.After)
+
+	_, err = repo.FindRevision(context.Background(), created.Id, 3)
+	assert.Equal(t, repository.ErrNotFound, err)
+
+	snapshot := *first.After
+	snapshot.Version = 0
+	reverted, err := repo.Update(repository.WithRevert(context.Background(), 1), snapshot)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), reverted.Version)
+	assert.Equal(t, 22, reverted.Age)
+
+	last, err := repo.FindRevision(context.Background(), created.Id, 3)
+
+	assert.Nil(t, err)
+	assert.Equal(t, document.Reverted, last.Operation)
+	assert.Equal(t, int64(1), last.Reverts)
+	assert.Equal(t, 23, last.Before.Age)
+}
+
+func testBulk(t *testing.T, repo repository.Repository) {
+
+	phones := []document.Phone{{Type: "mobile", Number: "+5511987654321"}}
+	people := create(t, repo,
+		document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22, Phones: phones},
+		document.Person{Name: "Ana", Email: "ana@gmail.com", Age: 30},
+	)
+
+	results, err := repo.Bulk(repository.WithActor(context.Background(), "importer"), []repository.BulkOperation{
+		{Operation: document.Created, Person: document.Person{Name: "Bruno", Email: "bruno@gmail.com", Age: 40}},
+		{Operation: document.Created, Person: document.Person{Name: "Other", Email: "LUCAS@gmail.com", Age: 40}},
+		{Operation: document.Updated, Person: document.Person{Id: people[0].Id, Name: "Lucas Silva", Email: "lucas@gmail.com", Age: 23}, Fields: []string{"name", "email", "age"}},
+		{Operation: document.Updated, Person: document.Person{Id: people[1].Id, Name: "Ana", Email: "ana@gmail.com", Age: 31, Version: 7}},
+		{Operation: document.Deleted, Person: document.Person{Id: people[1].Id, Version: 1}},
+		{Operation: document.Deleted, Person: document.Person{Id: primitive.NewObjectID()}},
+	}, false)
+
+	assert.Nil(t, err)
+	assert.Len(t, results, 6)
+
+	assert.Nil(t, results[0].Err)
+	assert.False(t, results[0].Person.Id.IsZero())
+	assert.Equal(t, "importer", results[0].Person.CreatedBy)
+	assert.Equal(t, duplicateEmail(people[0].Id), results[1].Err)
+	assert.Nil(t, results[2].Err)
+	assert.Equal(t, int64(2), results[2].Person.Version)
+	assert.Equal(t, repository.ErrVersionMismatch, results[3].Err)
+	assert.Nil(t, results[4].Err)
+	assert.Equal(t, repository.ErrNotFound, results[5].Err)
+
+	found, err := repo.FindById(context.Background(), results[0].Person.Id.Hex())
+	assert.Nil(t, err)
+	assertSamePerson(t, results[0].Person, found)
+
+	found, err = repo.FindById(context.Background(), people[0].Id.Hex())
+	assert.Nil(t, err)
+	assert.Equal(t, "Lucas Silva", found.Name)
+	assert.Equal(t, "importer", found.UpdatedBy)
+	assert.Equal(t, phones, found.Phones, "an update only writes its fields")
+
+	_, err = repo.FindById(context.Background(), people[1].Id.Hex())
+	assert.Equal(t, repository.ErrNotFound, err)
+
+	revisions, total, err := repo.History(context.Background(), people[0].Id, query())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, document.Updated, revisions[0].Operation)
+	assert.Equal(t, "importer", revisions[0].Actor)
+
+	revisions, total, err = repo.History(context.Background(), people[1].Id, query())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, document.Deleted, revisions[0].Operation)
+}
+
+func testBulkOrdered(t *testing.T, repo repository.Repository) {
+
+	people := create(t, repo, document.Person{Name: "Lucas", Email: "lucas@gmail.com", Age: 22})
+
+	results, err := repo.Bulk(context.Background(), []repository.BulkOperation{
+		{Operation: document.Updated, Person: document.Person{Id: people[0].Id, Name: "Lucas", Email: "lucas@gmail.com", Age: 23}},
+		{Operation: document.Created, Person: document.Person{Name: "Other", Email: "lucas@gmail.com", Age: 40}},
+		{Operation: document.Created, Person: document.Person{Name: "Bruno", Email: "bruno@gmail.com", Age: 40}},
+		{Operation: document.Deleted, Person: document.Person{Id: people[0].Id}},
+	}, true)
+
+	assert.Nil(t, err)
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, duplicateEmail(people[0].Id), results[1].Err)
+	assert.Equal(t, repository.ErrSkipped, results[2].Err)
+	assert.Equal(t, repository.ErrSkipped, results[3].Err)
+
+	found, total, err := repo.Find(context.Background(), query())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, 23, found[0].Age)
+}
+
+func duplicateEmail(id primitive.ObjectID) error {
+	return repository.DuplicateEmailError{Id: id}
+}
+
+func testStream(t *testing.T, repo repository.Repository) {
+
+	create(t, repo,
+		document.Person{Name: "Lucas Silva", Email: "lucas@gmail.com", Age: 22},
+		document.Person{Name: "Ana Silva", Email: "ana@gmail.com", Age: 35},
+		document.Person{Name: "Bruno", Email: "bruno@gmail.com", Age: 40},
+		document.Person{Name: "Carla Silva", Email: "carla@gmail.com", Age: 28},
+	)
+
+	q := query()
+	q.Size = 1
+	q.Name = "silva"
+	q.Sort = []repository.Sort{{Field: "age", Descending: true}}
+
+	var streamed []document.Person
+	err := repo.Stream(context.Background(), q, func(person document.Person) error {
+		streamed = append(streamed, person)
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Ana Silva", "Carla Silva", "Lucas Silva"}, names(streamed))
+
+	stop := errors.New("stop")
+	streamed = nil
+	err = repo.Stream(context.Background(), query(), func(person document.Person) error {
+		streamed = append(streamed, person)
+		return stop
+	})
+
+	assert.Equal(t, stop, err)
+	assert.Len(t, streamed, 1)
+}
+
+func search(text string, fuzzy bool) repository.Query {
+	q := query()
+	q.Text = text
+	q.Fuzzy = fuzzy
+	return q
+}
+
+func testSearch(t *testing.T, repo repository.Repository) {
+
+	created := create(t, repo,
+		document.Person{Name: "João da Silva", Email: "joao@gmail.com", Age: 22},
+		document.Person{Name: "Maria Silva", Email: "maria@gmail.com", Age: 35},
+		document.Person{Name: "Joana Souza", Email: "joana@example.com", Age: 40},
+		document.Person{Name: "João Pereira", Email: "pereira@gmail.com", Age: 28},
+	)
+
+	_, err := repo.Delete(context.Background(), created[3].Id, created[3].Version)
+	assert.Nil(t, err)
+
+	people, total, err := repo.Search(context.Background(), search("JOAO", false))
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, []string{"João da Silva"}, names(people))
+
+	people, total, err = repo.Search(context.Background(), search("silva joão", false))
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, []string{"João da Silva", "Maria Silva"}, names(people))
+
+	q := search("silva joão", false)
+	q.Page, q.Size = 2, 1
+	people, total, err = repo.Search(context.Background(), q)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, []string{"Maria Silva"}, names(people))
+
+	people, total, err = repo.Search(context.Background(), search("example", false))
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, []string{"Joana Souza"}, names(people))
+
+	for _, text := range []string{"Slva", "Jo", `"-"`} {
+		people, total, err = repo.Search(context.Background(), search(text, false))
+
+		assert.Nil(t, err, text)
+		assert.Equal(t, int64(0), total, text)
+		assert.Empty(t, people, text)
+	}
+}
+
+func testSearchFuzzy(t *testing.T, repo repository.Repository) {
+
+	created := create(t, repo,
+		document.Person{Name: "João da Silva", Email: "joao@gmail.com", Age: 22},
+		document.Person{Name: "Maria Silva", Email: "maria@gmail.com", Age: 35},
+		document.Person{Name: "Mariana Souza", Email: "mariana@example.com", Age: 40},
+		document.Person{Name: "Bruno", Email: "bruno@gmail.com", Age: 28},
+		document.Person{Name: "Marta Pereira", Email: "marta@gmail.com", Age: 31},
+	)
+
+	_, err := repo.Delete(context.Background(), created[4].Id, created[4].Version)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"Jaoo Slva", []string{"João da Silva"}},
+		{"maira", []string{"Maria Silva", "Mariana Souza"}},
+		{"Mari", []string{"Maria Silva", "Mariana Souza"}},
+		{"mariana", []string{"Mariana Souza", "Maria Silva"}},
+		{"brunno", []string{"Bruno"}},
+		{"xyz", nil},
+	}
+
+	for _, test := range tests {
+		people, total, err := repo.Search(context.Background(), search(test.text, true))
+
+		assert.Nil(t, err, test.text)
+		assert.Equal(t, int64(len(test.expected)), total, test.text)
+		assert.Equal(t, test.expected, names(people), test.text)
+	}
+}
+
+// testSearchFuzzyAmongMany finds a person saved after more people than a scan of the whole collection would afford.
+func testSearchFuzzyAmongMany(t *testing.T, repo repository.Repository) {
+
+	var operations []repository.BulkOperation
+
+	for i := 0; i <= 10000; i++ {
+		person := document.Person{Name: "Filler", Email: fmt.Sprintf("filler%d@gmail.com", i), Age: 30}
+		operations = append(operations, repository.BulkOperation{Operation: document.Created, Person: person})
+	}
+
+	for start := 0; start < len(operations); start += repository.MaxBulkSize {
+		end := start + repository.MaxBulkSize
+		if end > len(operations) {
+			end = len(operations)
+		}
+		_, err := repo.Bulk(context.Background(), operations[start:end], true)
+		assert.Nil(t, err)
+	}
+
+	create(t, repo, document.Person{Name: "Lucas Silva", Email: "lucas@gmail.com", Age: 22})
+
+	people, total, err := repo.Search(context.Background(), search("Lucs Silv", true))
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, []string{"Lucas Silva"}, names(people))
+}
+
+func testCanceledContext(t *testing.T, repo repository.Repository) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := repo.Find(ctx, query())
+	assert.True(t, errors.Is(err, context.Canceled), err)
+
+	_, _, err = repo.Search(ctx, search("lucas", true))
+	assert.True(t, errors.Is(err, context.Canceled), err)
+
+	_, err = repo.Create(ctx, document.Person{Name: "Lucas"})
+	assert.True(t, errors.Is(err, context.Canceled), err)
+}
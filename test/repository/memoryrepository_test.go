@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"person/internal/repository"
+	"testing"
+)
+
+func TestMemoryRepositoryConformance(t *testing.T) {
+	Conformance(t, func(t *testing.T) repository.Repository {
+		return repository.NewMemoryRepository()
+	})
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"os"
+	"person/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestPersonRepositoryConformance(t *testing.T) {
+
+	uri := os.Getenv("MONGO_URI")
+
+	if uri == "" {
+		t.Skip("MONGO_URI not set, skipping conformance against MongoDB.")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = client.Disconnect(context.Background()) }()
+
+	if err = client.Ping(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	database := client.Database("person_conformance")
+
+	Conformance(t, func(t *testing.T) repository.Repository {
+		collection := database.Collection(primitive.NewObjectID().Hex())
+		history := database.Collection(collection.Name() + "_history")
+		t.Cleanup(func() {
+			_ = collection.Drop(context.Background())
+			_ = history.Drop(context.Background())
+		})
+		if err := repository.EnsureIndexes(context.Background(), collection); err != nil {
+			t.Fatal(err)
+		}
+		if err := repository.EnsureRevisionIndexes(context.Background(), history); err != nil {
+			t.Fatal(err)
+		}
+		return repository.PersonRepository{Collection: collection, Revisions: history}
+	})
+}
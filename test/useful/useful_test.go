@@ -6,7 +6,9 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"net/http"
 	"net/http/httptest"
+	"person/internal/codec"
 	"person/internal/dto"
+	"person/internal/i18n"
 	"person/internal/useful"
 	"testing"
 )
@@ -20,7 +22,7 @@ func TestBuildSuccess200WithBody(t *testing.T) {
 		Id:    objID,
 		Name:  "Lucas",
 		Email: "lucas@gmail.com",
-		Age:   22,
+		Age:   intPointer(22),
 	}
 
 	useful.BuildSuccess(response, http.StatusOK, body)
@@ -61,13 +63,105 @@ func TestBuildSuccess204WithoutBody(t *testing.T) {
 func TestBuildError(t *testing.T) {
 
 	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/v1/person", nil)
 
-	useful.BuildError(response, http.StatusInternalServerError, useful.CreateError)
+	useful.BuildError(response, request, http.StatusInternalServerError, useful.CreateError)
 
-	var body dto.Error
+	var body dto.Problem
 	_ = json.Unmarshal([]byte(response.Body.String()), &body)
 
+	expected := dto.Problem{
+		Type:     useful.ProblemBlank,
+		Title:    "Internal Server Error",
+		Status:   http.StatusInternalServerError,
+		Detail:   useful.CreateError,
+		Instance: "/v1/person",
+	}
+
 	assert.Equal(t, http.StatusInternalServerError, response.Code)
-	assert.Equal(t, dto.Error{Message: useful.CreateError}, body)
-	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+	assert.Equal(t, expected, body)
+	assert.Equal(t, "application/problem+json", response.Header().Get("Content-Type"))
+}
+
+func TestBuildErrorWithId(t *testing.T) {
+
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/v1/person", nil)
+
+	useful.BuildErrorWithId(response, request, http.StatusConflict, useful.DuplicateEmail, "5f165e2e4de9b442e60b3904")
+
+	var body dto.Problem
+	_ = json.Unmarshal([]byte(response.Body.String()), &body)
+
+	assert.Equal(t, http.StatusConflict, response.Code)
+	assert.Equal(t, useful.ProblemDuplicateEmail, body.Type)
+	assert.Equal(t, useful.DuplicateEmail, body.Detail)
+	assert.Equal(t, "5f165e2e4de9b442e60b3904", body.Id)
+	assert.Equal(t, "application/problem+json", response.Header().Get("Content-Type"))
+}
+
+func TestBuildFieldErrors(t *testing.T) {
+
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/v1/person", nil)
+	errors := []dto.FieldError{{Field: "email", Rule: "email", Message: "email must be a valid email address"}}
+
+	useful.BuildFieldErrors(response, request, http.StatusBadRequest, useful.InvalidFields, errors)
+
+	var body dto.Problem
+	_ = json.Unmarshal([]byte(response.Body.String()), &body)
+
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, useful.ProblemInvalidFields, body.Type)
+	assert.Equal(t, "Bad Request", body.Title)
+	assert.Equal(t, errors, body.Errors)
+}
+
+func TestBuildErrorWithNegotiatedXml(t *testing.T) {
+
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/v1/person/1", nil)
+
+	useful.BuildError(codec.With(response, codec.XML), request, http.StatusNotFound, useful.PersonNotFound)
+
+	assert.Equal(t, "application/problem+xml", response.Header().Get("Content-Type"))
+	assert.Contains(t, response.Body.String(), "<problem><type>about:blank</type><title>Not Found</title><status>404</status>")
+}
+
+func TestBuildSuccessWithNegotiatedCodec(t *testing.T) {
+
+	response := httptest.NewRecorder()
+
+	useful.BuildSuccess(codec.With(response, codec.YAML), http.StatusOK, dto.Person{Name: "Lucas", Age: intPointer(22)})
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/yaml", response.Header().Get("Content-Type"))
+	assert.Contains(t, response.Body.String(), "name: Lucas\n")
+}
+
+func TestBuildErrorLocalized(t *testing.T) {
+
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/v1/person/1", nil)
+	request.Header.Set("Accept-Language", "es-ES")
+
+	useful.BuildError(response, request, http.StatusNotFound, useful.PersonNotFound)
+
+	var body dto.Problem
+	_ = json.Unmarshal([]byte(response.Body.String()), &body)
+
+	assert.Equal(t, "No encontrado", body.Title)
+	assert.Equal(t, "Persona no encontrada.", body.Detail)
+	assert.Equal(t, "es", response.Header().Get("Content-Language"))
+}
+
+func TestLocalizeFallsBackToEnglish(t *testing.T) {
+
+	assert.Equal(t, "Pessoa não encontrada.", useful.Localize(i18n.Negotiate("pt-BR"), useful.PersonNotFound))
+	assert.Equal(t, useful.PersonNotFound, useful.Localize(i18n.Negotiate("en"), useful.PersonNotFound))
+	assert.Equal(t, useful.MemoryStorage, useful.Localize(i18n.Negotiate("es"), useful.MemoryStorage))
+}
+
+func intPointer(value int) *int {
+	return &value
 }